@@ -0,0 +1,790 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.33.2
+// source: api/gruleplus/v1/gruleplus.proto
+
+package gruleplusv1
+
+import (
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	anypb "google.golang.org/protobuf/types/known/anypb"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Fact is the data a rule is evaluated against.
+type Fact struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Kind:
+	//
+	//	*Fact_Struct
+	//	*Fact_Any
+	Kind          isFact_Kind `protobuf_oneof:"kind"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Fact) Reset() {
+	*x = Fact{}
+	mi := &file_api_gruleplus_v1_gruleplus_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Fact) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Fact) ProtoMessage() {}
+
+func (x *Fact) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gruleplus_v1_gruleplus_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Fact.ProtoReflect.Descriptor instead.
+func (*Fact) Descriptor() ([]byte, []int) {
+	return file_api_gruleplus_v1_gruleplus_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Fact) GetKind() isFact_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return nil
+}
+
+func (x *Fact) GetStruct() *structpb.Struct {
+	if x != nil {
+		if x, ok := x.Kind.(*Fact_Struct); ok {
+			return x.Struct
+		}
+	}
+	return nil
+}
+
+func (x *Fact) GetAny() *anypb.Any {
+	if x != nil {
+		if x, ok := x.Kind.(*Fact_Any); ok {
+			return x.Any
+		}
+	}
+	return nil
+}
+
+type isFact_Kind interface {
+	isFact_Kind()
+}
+
+type Fact_Struct struct {
+	// struct is a schemaless fact, accessed by its field names.
+	Struct *structpb.Struct `protobuf:"bytes,1,opt,name=struct,proto3,oneof"`
+}
+
+type Fact_Any struct {
+	// any is a registered protobuf message, accessed by its Go field names.
+	Any *anypb.Any `protobuf:"bytes,2,opt,name=any,proto3,oneof"`
+}
+
+func (*Fact_Struct) isFact_Kind() {}
+
+func (*Fact_Any) isFact_Kind() {}
+
+type AddRuleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Statement     string                 `protobuf:"bytes,2,opt,name=statement,proto3" json:"statement,omitempty"`
+	Duration      int64                  `protobuf:"varint,3,opt,name=duration,proto3" json:"duration,omitempty"`                    // cache duration in nanoseconds, 0 means the engine TTL
+	BuildOnly     bool                   `protobuf:"varint,4,opt,name=build_only,json=buildOnly,proto3" json:"build_only,omitempty"` // only add the rule if it does not exist
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddRuleRequest) Reset() {
+	*x = AddRuleRequest{}
+	mi := &file_api_gruleplus_v1_gruleplus_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddRuleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddRuleRequest) ProtoMessage() {}
+
+func (x *AddRuleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gruleplus_v1_gruleplus_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddRuleRequest.ProtoReflect.Descriptor instead.
+func (*AddRuleRequest) Descriptor() ([]byte, []int) {
+	return file_api_gruleplus_v1_gruleplus_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AddRuleRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AddRuleRequest) GetStatement() string {
+	if x != nil {
+		return x.Statement
+	}
+	return ""
+}
+
+func (x *AddRuleRequest) GetDuration() int64 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+func (x *AddRuleRequest) GetBuildOnly() bool {
+	if x != nil {
+		return x.BuildOnly
+	}
+	return false
+}
+
+type AddRuleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddRuleResponse) Reset() {
+	*x = AddRuleResponse{}
+	mi := &file_api_gruleplus_v1_gruleplus_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddRuleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddRuleResponse) ProtoMessage() {}
+
+func (x *AddRuleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gruleplus_v1_gruleplus_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddRuleResponse.ProtoReflect.Descriptor instead.
+func (*AddRuleResponse) Descriptor() ([]byte, []int) {
+	return file_api_gruleplus_v1_gruleplus_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AddRuleResponse) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type RemoveRuleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveRuleRequest) Reset() {
+	*x = RemoveRuleRequest{}
+	mi := &file_api_gruleplus_v1_gruleplus_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveRuleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveRuleRequest) ProtoMessage() {}
+
+func (x *RemoveRuleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gruleplus_v1_gruleplus_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveRuleRequest.ProtoReflect.Descriptor instead.
+func (*RemoveRuleRequest) Descriptor() ([]byte, []int) {
+	return file_api_gruleplus_v1_gruleplus_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *RemoveRuleRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type RemoveRuleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveRuleResponse) Reset() {
+	*x = RemoveRuleResponse{}
+	mi := &file_api_gruleplus_v1_gruleplus_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveRuleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveRuleResponse) ProtoMessage() {}
+
+func (x *RemoveRuleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gruleplus_v1_gruleplus_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveRuleResponse.ProtoReflect.Descriptor instead.
+func (*RemoveRuleResponse) Descriptor() ([]byte, []int) {
+	return file_api_gruleplus_v1_gruleplus_proto_rawDescGZIP(), []int{4}
+}
+
+type ListRulesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRulesRequest) Reset() {
+	*x = ListRulesRequest{}
+	mi := &file_api_gruleplus_v1_gruleplus_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRulesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRulesRequest) ProtoMessage() {}
+
+func (x *ListRulesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gruleplus_v1_gruleplus_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRulesRequest.ProtoReflect.Descriptor instead.
+func (*ListRulesRequest) Descriptor() ([]byte, []int) {
+	return file_api_gruleplus_v1_gruleplus_proto_rawDescGZIP(), []int{5}
+}
+
+type ListRulesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rules         []string               `protobuf:"bytes,1,rep,name=rules,proto3" json:"rules,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRulesResponse) Reset() {
+	*x = ListRulesResponse{}
+	mi := &file_api_gruleplus_v1_gruleplus_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRulesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRulesResponse) ProtoMessage() {}
+
+func (x *ListRulesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gruleplus_v1_gruleplus_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRulesResponse.ProtoReflect.Descriptor instead.
+func (*ListRulesResponse) Descriptor() ([]byte, []int) {
+	return file_api_gruleplus_v1_gruleplus_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListRulesResponse) GetRules() []string {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+type ExecuteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rule          string                 `protobuf:"bytes,1,opt,name=rule,proto3" json:"rule,omitempty"`
+	Fact          *Fact                  `protobuf:"bytes,2,opt,name=fact,proto3" json:"fact,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecuteRequest) Reset() {
+	*x = ExecuteRequest{}
+	mi := &file_api_gruleplus_v1_gruleplus_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteRequest) ProtoMessage() {}
+
+func (x *ExecuteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gruleplus_v1_gruleplus_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteRequest.ProtoReflect.Descriptor instead.
+func (*ExecuteRequest) Descriptor() ([]byte, []int) {
+	return file_api_gruleplus_v1_gruleplus_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ExecuteRequest) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+func (x *ExecuteRequest) GetFact() *Fact {
+	if x != nil {
+		return x.Fact
+	}
+	return nil
+}
+
+type ExecuteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rule          string                 `protobuf:"bytes,1,opt,name=rule,proto3" json:"rule,omitempty"`
+	Fact          *Fact                  `protobuf:"bytes,2,opt,name=fact,proto3" json:"fact,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"` // set by ExecuteBatch when the request failed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecuteResponse) Reset() {
+	*x = ExecuteResponse{}
+	mi := &file_api_gruleplus_v1_gruleplus_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteResponse) ProtoMessage() {}
+
+func (x *ExecuteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gruleplus_v1_gruleplus_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteResponse.ProtoReflect.Descriptor instead.
+func (*ExecuteResponse) Descriptor() ([]byte, []int) {
+	return file_api_gruleplus_v1_gruleplus_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ExecuteResponse) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+func (x *ExecuteResponse) GetFact() *Fact {
+	if x != nil {
+		return x.Fact
+	}
+	return nil
+}
+
+func (x *ExecuteResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type FetchMatchingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rule          string                 `protobuf:"bytes,1,opt,name=rule,proto3" json:"rule,omitempty"`
+	Fact          *Fact                  `protobuf:"bytes,2,opt,name=fact,proto3" json:"fact,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FetchMatchingRequest) Reset() {
+	*x = FetchMatchingRequest{}
+	mi := &file_api_gruleplus_v1_gruleplus_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FetchMatchingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FetchMatchingRequest) ProtoMessage() {}
+
+func (x *FetchMatchingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gruleplus_v1_gruleplus_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FetchMatchingRequest.ProtoReflect.Descriptor instead.
+func (*FetchMatchingRequest) Descriptor() ([]byte, []int) {
+	return file_api_gruleplus_v1_gruleplus_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *FetchMatchingRequest) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+func (x *FetchMatchingRequest) GetFact() *Fact {
+	if x != nil {
+		return x.Fact
+	}
+	return nil
+}
+
+type RuleEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Salience      int32                  `protobuf:"varint,3,opt,name=salience,proto3" json:"salience,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RuleEntry) Reset() {
+	*x = RuleEntry{}
+	mi := &file_api_gruleplus_v1_gruleplus_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RuleEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuleEntry) ProtoMessage() {}
+
+func (x *RuleEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gruleplus_v1_gruleplus_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuleEntry.ProtoReflect.Descriptor instead.
+func (*RuleEntry) Descriptor() ([]byte, []int) {
+	return file_api_gruleplus_v1_gruleplus_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RuleEntry) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RuleEntry) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *RuleEntry) GetSalience() int32 {
+	if x != nil {
+		return x.Salience
+	}
+	return 0
+}
+
+type FetchMatchingResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rule          string                 `protobuf:"bytes,1,opt,name=rule,proto3" json:"rule,omitempty"`
+	Entries       []*RuleEntry           `protobuf:"bytes,2,rep,name=entries,proto3" json:"entries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FetchMatchingResponse) Reset() {
+	*x = FetchMatchingResponse{}
+	mi := &file_api_gruleplus_v1_gruleplus_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FetchMatchingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FetchMatchingResponse) ProtoMessage() {}
+
+func (x *FetchMatchingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gruleplus_v1_gruleplus_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FetchMatchingResponse.ProtoReflect.Descriptor instead.
+func (*FetchMatchingResponse) Descriptor() ([]byte, []int) {
+	return file_api_gruleplus_v1_gruleplus_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *FetchMatchingResponse) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+func (x *FetchMatchingResponse) GetEntries() []*RuleEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+var File_api_gruleplus_v1_gruleplus_proto protoreflect.FileDescriptor
+
+const file_api_gruleplus_v1_gruleplus_proto_rawDesc = "" +
+	"\n" +
+	" api/gruleplus/v1/gruleplus.proto\x12\fgruleplus.v1\x1a\x19google/protobuf/any.proto\x1a\x1cgoogle/protobuf/struct.proto\"k\n" +
+	"\x04Fact\x121\n" +
+	"\x06struct\x18\x01 \x01(\v2\x17.google.protobuf.StructH\x00R\x06struct\x12(\n" +
+	"\x03any\x18\x02 \x01(\v2\x14.google.protobuf.AnyH\x00R\x03anyB\x06\n" +
+	"\x04kind\"}\n" +
+	"\x0eAddRuleRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1c\n" +
+	"\tstatement\x18\x02 \x01(\tR\tstatement\x12\x1a\n" +
+	"\bduration\x18\x03 \x01(\x03R\bduration\x12\x1d\n" +
+	"\n" +
+	"build_only\x18\x04 \x01(\bR\tbuildOnly\"%\n" +
+	"\x0fAddRuleResponse\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"'\n" +
+	"\x11RemoveRuleRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"\x14\n" +
+	"\x12RemoveRuleResponse\"\x12\n" +
+	"\x10ListRulesRequest\")\n" +
+	"\x11ListRulesResponse\x12\x14\n" +
+	"\x05rules\x18\x01 \x03(\tR\x05rules\"L\n" +
+	"\x0eExecuteRequest\x12\x12\n" +
+	"\x04rule\x18\x01 \x01(\tR\x04rule\x12&\n" +
+	"\x04fact\x18\x02 \x01(\v2\x12.gruleplus.v1.FactR\x04fact\"c\n" +
+	"\x0fExecuteResponse\x12\x12\n" +
+	"\x04rule\x18\x01 \x01(\tR\x04rule\x12&\n" +
+	"\x04fact\x18\x02 \x01(\v2\x12.gruleplus.v1.FactR\x04fact\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"R\n" +
+	"\x14FetchMatchingRequest\x12\x12\n" +
+	"\x04rule\x18\x01 \x01(\tR\x04rule\x12&\n" +
+	"\x04fact\x18\x02 \x01(\v2\x12.gruleplus.v1.FactR\x04fact\"]\n" +
+	"\tRuleEntry\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x1a\n" +
+	"\bsalience\x18\x03 \x01(\x05R\bsalience\"^\n" +
+	"\x15FetchMatchingResponse\x12\x12\n" +
+	"\x04rule\x18\x01 \x01(\tR\x04rule\x121\n" +
+	"\aentries\x18\x02 \x03(\v2\x17.gruleplus.v1.RuleEntryR\aentries2\xed\x03\n" +
+	"\x11RuleEngineService\x12F\n" +
+	"\aAddRule\x12\x1c.gruleplus.v1.AddRuleRequest\x1a\x1d.gruleplus.v1.AddRuleResponse\x12O\n" +
+	"\n" +
+	"RemoveRule\x12\x1f.gruleplus.v1.RemoveRuleRequest\x1a .gruleplus.v1.RemoveRuleResponse\x12L\n" +
+	"\tListRules\x12\x1e.gruleplus.v1.ListRulesRequest\x1a\x1f.gruleplus.v1.ListRulesResponse\x12F\n" +
+	"\aExecute\x12\x1c.gruleplus.v1.ExecuteRequest\x1a\x1d.gruleplus.v1.ExecuteResponse\x12X\n" +
+	"\rFetchMatching\x12\".gruleplus.v1.FetchMatchingRequest\x1a#.gruleplus.v1.FetchMatchingResponse\x12O\n" +
+	"\fExecuteBatch\x12\x1c.gruleplus.v1.ExecuteRequest\x1a\x1d.gruleplus.v1.ExecuteResponse(\x010\x01B<Z:github.com/hungpdn/grule-plus/api/gruleplus/v1;gruleplusv1b\x06proto3"
+
+var (
+	file_api_gruleplus_v1_gruleplus_proto_rawDescOnce sync.Once
+	file_api_gruleplus_v1_gruleplus_proto_rawDescData []byte
+)
+
+func file_api_gruleplus_v1_gruleplus_proto_rawDescGZIP() []byte {
+	file_api_gruleplus_v1_gruleplus_proto_rawDescOnce.Do(func() {
+		file_api_gruleplus_v1_gruleplus_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_gruleplus_v1_gruleplus_proto_rawDesc), len(file_api_gruleplus_v1_gruleplus_proto_rawDesc)))
+	})
+	return file_api_gruleplus_v1_gruleplus_proto_rawDescData
+}
+
+var file_api_gruleplus_v1_gruleplus_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_api_gruleplus_v1_gruleplus_proto_goTypes = []any{
+	(*Fact)(nil),                  // 0: gruleplus.v1.Fact
+	(*AddRuleRequest)(nil),        // 1: gruleplus.v1.AddRuleRequest
+	(*AddRuleResponse)(nil),       // 2: gruleplus.v1.AddRuleResponse
+	(*RemoveRuleRequest)(nil),     // 3: gruleplus.v1.RemoveRuleRequest
+	(*RemoveRuleResponse)(nil),    // 4: gruleplus.v1.RemoveRuleResponse
+	(*ListRulesRequest)(nil),      // 5: gruleplus.v1.ListRulesRequest
+	(*ListRulesResponse)(nil),     // 6: gruleplus.v1.ListRulesResponse
+	(*ExecuteRequest)(nil),        // 7: gruleplus.v1.ExecuteRequest
+	(*ExecuteResponse)(nil),       // 8: gruleplus.v1.ExecuteResponse
+	(*FetchMatchingRequest)(nil),  // 9: gruleplus.v1.FetchMatchingRequest
+	(*RuleEntry)(nil),             // 10: gruleplus.v1.RuleEntry
+	(*FetchMatchingResponse)(nil), // 11: gruleplus.v1.FetchMatchingResponse
+	(*structpb.Struct)(nil),       // 12: google.protobuf.Struct
+	(*anypb.Any)(nil),             // 13: google.protobuf.Any
+}
+var file_api_gruleplus_v1_gruleplus_proto_depIdxs = []int32{
+	12, // 0: gruleplus.v1.Fact.struct:type_name -> google.protobuf.Struct
+	13, // 1: gruleplus.v1.Fact.any:type_name -> google.protobuf.Any
+	0,  // 2: gruleplus.v1.ExecuteRequest.fact:type_name -> gruleplus.v1.Fact
+	0,  // 3: gruleplus.v1.ExecuteResponse.fact:type_name -> gruleplus.v1.Fact
+	0,  // 4: gruleplus.v1.FetchMatchingRequest.fact:type_name -> gruleplus.v1.Fact
+	10, // 5: gruleplus.v1.FetchMatchingResponse.entries:type_name -> gruleplus.v1.RuleEntry
+	1,  // 6: gruleplus.v1.RuleEngineService.AddRule:input_type -> gruleplus.v1.AddRuleRequest
+	3,  // 7: gruleplus.v1.RuleEngineService.RemoveRule:input_type -> gruleplus.v1.RemoveRuleRequest
+	5,  // 8: gruleplus.v1.RuleEngineService.ListRules:input_type -> gruleplus.v1.ListRulesRequest
+	7,  // 9: gruleplus.v1.RuleEngineService.Execute:input_type -> gruleplus.v1.ExecuteRequest
+	9,  // 10: gruleplus.v1.RuleEngineService.FetchMatching:input_type -> gruleplus.v1.FetchMatchingRequest
+	7,  // 11: gruleplus.v1.RuleEngineService.ExecuteBatch:input_type -> gruleplus.v1.ExecuteRequest
+	2,  // 12: gruleplus.v1.RuleEngineService.AddRule:output_type -> gruleplus.v1.AddRuleResponse
+	4,  // 13: gruleplus.v1.RuleEngineService.RemoveRule:output_type -> gruleplus.v1.RemoveRuleResponse
+	6,  // 14: gruleplus.v1.RuleEngineService.ListRules:output_type -> gruleplus.v1.ListRulesResponse
+	8,  // 15: gruleplus.v1.RuleEngineService.Execute:output_type -> gruleplus.v1.ExecuteResponse
+	11, // 16: gruleplus.v1.RuleEngineService.FetchMatching:output_type -> gruleplus.v1.FetchMatchingResponse
+	8,  // 17: gruleplus.v1.RuleEngineService.ExecuteBatch:output_type -> gruleplus.v1.ExecuteResponse
+	12, // [12:18] is the sub-list for method output_type
+	6,  // [6:12] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_api_gruleplus_v1_gruleplus_proto_init() }
+func file_api_gruleplus_v1_gruleplus_proto_init() {
+	if File_api_gruleplus_v1_gruleplus_proto != nil {
+		return
+	}
+	file_api_gruleplus_v1_gruleplus_proto_msgTypes[0].OneofWrappers = []any{
+		(*Fact_Struct)(nil),
+		(*Fact_Any)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_gruleplus_v1_gruleplus_proto_rawDesc), len(file_api_gruleplus_v1_gruleplus_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_gruleplus_v1_gruleplus_proto_goTypes,
+		DependencyIndexes: file_api_gruleplus_v1_gruleplus_proto_depIdxs,
+		MessageInfos:      file_api_gruleplus_v1_gruleplus_proto_msgTypes,
+	}.Build()
+	File_api_gruleplus_v1_gruleplus_proto = out.File
+	file_api_gruleplus_v1_gruleplus_proto_goTypes = nil
+	file_api_gruleplus_v1_gruleplus_proto_depIdxs = nil
+}
@@ -0,0 +1,323 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v6.33.2
+// source: api/gruleplus/v1/gruleplus.proto
+
+package gruleplusv1
+
+import (
+	context "context"
+
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	RuleEngineService_AddRule_FullMethodName       = "/gruleplus.v1.RuleEngineService/AddRule"
+	RuleEngineService_RemoveRule_FullMethodName    = "/gruleplus.v1.RuleEngineService/RemoveRule"
+	RuleEngineService_ListRules_FullMethodName     = "/gruleplus.v1.RuleEngineService/ListRules"
+	RuleEngineService_Execute_FullMethodName       = "/gruleplus.v1.RuleEngineService/Execute"
+	RuleEngineService_FetchMatching_FullMethodName = "/gruleplus.v1.RuleEngineService/FetchMatching"
+	RuleEngineService_ExecuteBatch_FullMethodName  = "/gruleplus.v1.RuleEngineService/ExecuteBatch"
+)
+
+// RuleEngineServiceClient is the client API for RuleEngineService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// RuleEngineService manages and evaluates the rules of a grule-plus engine.
+type RuleEngineServiceClient interface {
+	// AddRule adds a new rule, or updates it when build_only is false.
+	AddRule(ctx context.Context, in *AddRuleRequest, opts ...grpc.CallOption) (*AddRuleResponse, error)
+	// RemoveRule removes a rule from the engine.
+	RemoveRule(ctx context.Context, in *RemoveRuleRequest, opts ...grpc.CallOption) (*RemoveRuleResponse, error)
+	// ListRules lists the names of the rules in the engine.
+	ListRules(ctx context.Context, in *ListRulesRequest, opts ...grpc.CallOption) (*ListRulesResponse, error)
+	// Execute runs a rule against a fact and returns the updated fact.
+	Execute(ctx context.Context, in *ExecuteRequest, opts ...grpc.CallOption) (*ExecuteResponse, error)
+	// FetchMatching returns the rule entries matching a fact.
+	FetchMatching(ctx context.Context, in *FetchMatchingRequest, opts ...grpc.CallOption) (*FetchMatchingResponse, error)
+	// ExecuteBatch runs each streamed request and streams back its result.
+	ExecuteBatch(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ExecuteRequest, ExecuteResponse], error)
+}
+
+type ruleEngineServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRuleEngineServiceClient(cc grpc.ClientConnInterface) RuleEngineServiceClient {
+	return &ruleEngineServiceClient{cc}
+}
+
+func (c *ruleEngineServiceClient) AddRule(ctx context.Context, in *AddRuleRequest, opts ...grpc.CallOption) (*AddRuleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddRuleResponse)
+	err := c.cc.Invoke(ctx, RuleEngineService_AddRule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ruleEngineServiceClient) RemoveRule(ctx context.Context, in *RemoveRuleRequest, opts ...grpc.CallOption) (*RemoveRuleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveRuleResponse)
+	err := c.cc.Invoke(ctx, RuleEngineService_RemoveRule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ruleEngineServiceClient) ListRules(ctx context.Context, in *ListRulesRequest, opts ...grpc.CallOption) (*ListRulesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRulesResponse)
+	err := c.cc.Invoke(ctx, RuleEngineService_ListRules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ruleEngineServiceClient) Execute(ctx context.Context, in *ExecuteRequest, opts ...grpc.CallOption) (*ExecuteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExecuteResponse)
+	err := c.cc.Invoke(ctx, RuleEngineService_Execute_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ruleEngineServiceClient) FetchMatching(ctx context.Context, in *FetchMatchingRequest, opts ...grpc.CallOption) (*FetchMatchingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FetchMatchingResponse)
+	err := c.cc.Invoke(ctx, RuleEngineService_FetchMatching_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ruleEngineServiceClient) ExecuteBatch(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ExecuteRequest, ExecuteResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RuleEngineService_ServiceDesc.Streams[0], RuleEngineService_ExecuteBatch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExecuteRequest, ExecuteResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RuleEngineService_ExecuteBatchClient = grpc.BidiStreamingClient[ExecuteRequest, ExecuteResponse]
+
+// RuleEngineServiceServer is the server API for RuleEngineService service.
+// All implementations must embed UnimplementedRuleEngineServiceServer
+// for forward compatibility.
+//
+// RuleEngineService manages and evaluates the rules of a grule-plus engine.
+type RuleEngineServiceServer interface {
+	// AddRule adds a new rule, or updates it when build_only is false.
+	AddRule(context.Context, *AddRuleRequest) (*AddRuleResponse, error)
+	// RemoveRule removes a rule from the engine.
+	RemoveRule(context.Context, *RemoveRuleRequest) (*RemoveRuleResponse, error)
+	// ListRules lists the names of the rules in the engine.
+	ListRules(context.Context, *ListRulesRequest) (*ListRulesResponse, error)
+	// Execute runs a rule against a fact and returns the updated fact.
+	Execute(context.Context, *ExecuteRequest) (*ExecuteResponse, error)
+	// FetchMatching returns the rule entries matching a fact.
+	FetchMatching(context.Context, *FetchMatchingRequest) (*FetchMatchingResponse, error)
+	// ExecuteBatch runs each streamed request and streams back its result.
+	ExecuteBatch(grpc.BidiStreamingServer[ExecuteRequest, ExecuteResponse]) error
+	mustEmbedUnimplementedRuleEngineServiceServer()
+}
+
+// UnimplementedRuleEngineServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedRuleEngineServiceServer struct{}
+
+func (UnimplementedRuleEngineServiceServer) AddRule(context.Context, *AddRuleRequest) (*AddRuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddRule not implemented")
+}
+func (UnimplementedRuleEngineServiceServer) RemoveRule(context.Context, *RemoveRuleRequest) (*RemoveRuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveRule not implemented")
+}
+func (UnimplementedRuleEngineServiceServer) ListRules(context.Context, *ListRulesRequest) (*ListRulesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRules not implemented")
+}
+func (UnimplementedRuleEngineServiceServer) Execute(context.Context, *ExecuteRequest) (*ExecuteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Execute not implemented")
+}
+func (UnimplementedRuleEngineServiceServer) FetchMatching(context.Context, *FetchMatchingRequest) (*FetchMatchingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FetchMatching not implemented")
+}
+func (UnimplementedRuleEngineServiceServer) ExecuteBatch(grpc.BidiStreamingServer[ExecuteRequest, ExecuteResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ExecuteBatch not implemented")
+}
+func (UnimplementedRuleEngineServiceServer) mustEmbedUnimplementedRuleEngineServiceServer() {}
+func (UnimplementedRuleEngineServiceServer) testEmbeddedByValue()                           {}
+
+// UnsafeRuleEngineServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RuleEngineServiceServer will
+// result in compilation errors.
+type UnsafeRuleEngineServiceServer interface {
+	mustEmbedUnimplementedRuleEngineServiceServer()
+}
+
+func RegisterRuleEngineServiceServer(s grpc.ServiceRegistrar, srv RuleEngineServiceServer) {
+	// If the following call pancis, it indicates UnimplementedRuleEngineServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&RuleEngineService_ServiceDesc, srv)
+}
+
+func _RuleEngineService_AddRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RuleEngineServiceServer).AddRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RuleEngineService_AddRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RuleEngineServiceServer).AddRule(ctx, req.(*AddRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RuleEngineService_RemoveRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RuleEngineServiceServer).RemoveRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RuleEngineService_RemoveRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RuleEngineServiceServer).RemoveRule(ctx, req.(*RemoveRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RuleEngineService_ListRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RuleEngineServiceServer).ListRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RuleEngineService_ListRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RuleEngineServiceServer).ListRules(ctx, req.(*ListRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RuleEngineService_Execute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExecuteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RuleEngineServiceServer).Execute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RuleEngineService_Execute_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RuleEngineServiceServer).Execute(ctx, req.(*ExecuteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RuleEngineService_FetchMatching_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchMatchingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RuleEngineServiceServer).FetchMatching(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RuleEngineService_FetchMatching_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RuleEngineServiceServer).FetchMatching(ctx, req.(*FetchMatchingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RuleEngineService_ExecuteBatch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(RuleEngineServiceServer).ExecuteBatch(&grpc.GenericServerStream[ExecuteRequest, ExecuteResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RuleEngineService_ExecuteBatchServer = grpc.BidiStreamingServer[ExecuteRequest, ExecuteResponse]
+
+// RuleEngineService_ServiceDesc is the grpc.ServiceDesc for RuleEngineService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RuleEngineService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gruleplus.v1.RuleEngineService",
+	HandlerType: (*RuleEngineServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AddRule",
+			Handler:    _RuleEngineService_AddRule_Handler,
+		},
+		{
+			MethodName: "RemoveRule",
+			Handler:    _RuleEngineService_RemoveRule_Handler,
+		},
+		{
+			MethodName: "ListRules",
+			Handler:    _RuleEngineService_ListRules_Handler,
+		},
+		{
+			MethodName: "Execute",
+			Handler:    _RuleEngineService_Execute_Handler,
+		},
+		{
+			MethodName: "FetchMatching",
+			Handler:    _RuleEngineService_FetchMatching_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExecuteBatch",
+			Handler:       _RuleEngineService_ExecuteBatch_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "api/gruleplus/v1/gruleplus.proto",
+}
@@ -0,0 +1,56 @@
+package benchmark
+
+import (
+	"testing"
+
+	"github.com/hungpdn/grule-plus/cache"
+)
+
+// BenchmarkGenericCache compares the allocations of typed caches against the untyped ICache,
+// which boxes every key and value into an interface
+func BenchmarkGenericCache(b *testing.B) {
+	cacheTypes := []struct {
+		name string
+		typ  int
+	}{
+		{"LRU", cache.LRU},
+		{"LFU", cache.LFU},
+		{"ARC", cache.ARC},
+		{"TWOQ", cache.TWOQ},
+		{"RANDOM", cache.RANDOM},
+		{"TINYLFU", cache.TINYLFU},
+		{"SIEVE", cache.SIEVE},
+		{"S3FIFO", cache.S3FIFO},
+	}
+	const size = 1000
+
+	for _, cacheType := range cacheTypes {
+		b.Run(cacheType.name+"_ICache", func(b *testing.B) {
+			c := cache.New(cache.Config[any, any]{Type: cacheType.typ, Size: size})
+			defer c.Close()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				key := i % (2 * size)
+				if _, ok := c.Get(key); !ok {
+					c.Set(key, i, 0)
+				}
+			}
+		})
+
+		b.Run(cacheType.name+"_Generic", func(b *testing.B) {
+			c := cache.NewCache(cache.Config[int, int]{Type: cacheType.typ, Size: size})
+			defer c.Close()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				key := i % (2 * size)
+				if _, ok := c.Get(key); !ok {
+					c.Set(key, i, 0)
+				}
+			}
+		})
+	}
+}
@@ -7,8 +7,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/hungpdn/grule-plus/cache"
 	"github.com/hungpdn/grule-plus/engine"
-	"github.com/hungpdn/grule-plus/internal/cache"
 )
 
 type DiscountFact struct {
@@ -27,6 +27,9 @@ func BenchmarkCacheTypes(b *testing.B) {
 		{"ARC", cache.ARC},
 		{"TWOQ", cache.TWOQ},
 		{"RANDOM", cache.RANDOM},
+		{"TINYLFU", cache.TINYLFU},
+		{"SIEVE", cache.SIEVE},
+		{"S3FIFO", cache.S3FIFO},
 	}
 
 	sizes := []int{100, 1000, 10000}
@@ -36,7 +39,7 @@ func BenchmarkCacheTypes(b *testing.B) {
 		for _, size := range sizes {
 			for _, workload := range workloads {
 				b.Run(fmt.Sprintf("%s_Size%d_%s", cacheType.name, size, workload), func(b *testing.B) {
-					c := cache.New(cache.Config{
+					c := cache.New(cache.Config[any, any]{
 						Type:            cacheType.typ,
 						Size:            size,
 						CleanupInterval: time.Minute,
@@ -100,6 +103,9 @@ func BenchmarkEngineCacheTypes(b *testing.B) {
 		engine.ARC,
 		engine.TWOQ,
 		engine.RANDOM,
+		engine.TINYLFU,
+		engine.SIEVE,
+		engine.S3FIFO,
 	}
 
 	for _, cacheType := range cacheTypes {
@@ -0,0 +1,95 @@
+package benchmark
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/hungpdn/grule-plus/cache"
+	"github.com/hungpdn/grule-plus/engine"
+)
+
+// BenchmarkShardedCache measures the throughput of concurrent reads, with 10% of misses
+// followed by a write, as the number of lock-striped segments grows.
+// Run with -cpu to compare the scaling, e.g. -cpu 1,4,8.
+func BenchmarkShardedCache(b *testing.B) {
+	configs := []struct {
+		name       string
+		shards     int
+		readBuffer int
+	}{
+		{"Shards1", 1, 0},
+		{"Shards4", 4, 0},
+		{"Shards16", 16, 0},
+		{"Shards16_ReadBuffer", 16, 64},
+	}
+	const size = 1000
+
+	for _, typ := range []struct {
+		name string
+		typ  int
+	}{{"LRU", cache.LRU}, {"SIEVE", cache.SIEVE}} {
+		for _, cfg := range configs {
+			b.Run(fmt.Sprintf("%s_%s", typ.name, cfg.name), func(b *testing.B) {
+				c := cache.NewCache(cache.Config[int, int]{
+					Type:       typ.typ,
+					Size:       size,
+					Shards:     cfg.shards,
+					ReadBuffer: cfg.readBuffer,
+				})
+				defer c.Close()
+				for i := 0; i < size; i++ {
+					c.Set(i, i, 0)
+				}
+
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					localRand := rand.New(rand.NewSource(time.Now().UnixNano()))
+					for pb.Next() {
+						key := localRand.Intn(size + size/10)
+						if _, ok := c.Get(key); !ok {
+							c.Set(key, key, 0)
+						}
+					}
+				})
+			})
+		}
+	}
+}
+
+// BenchmarkEngineShards measures concurrent ContainsRule calls, all routed to the first partition,
+// as the number of lock-striped segments of its cache grows.
+func BenchmarkEngineShards(b *testing.B) {
+	for _, shards := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("Shards%d", shards), func(b *testing.B) {
+			grule := engine.NewPartitionEngine(engine.Config{
+				Type:      engine.LRU,
+				Size:      1000,
+				Partition: 1,
+				Shards:    shards,
+			}, func(string) int { return 1 })
+			defer grule.Close()
+			for i := 0; i < 100; i++ {
+				rule := fmt.Sprintf("Rule%d", i)
+				statement := fmt.Sprintf(`rule %s "Test rule %d" salience 10 {
+					when
+						DiscountFact.Amount > %d
+					then
+						DiscountFact.Discount = %d;
+						Retract("%s");
+				}`, rule, i, i*10, i, rule)
+				_ = grule.AddRule(rule, statement, 0)
+			}
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					grule.ContainsRule(fmt.Sprintf("Rule%d", i%100))
+					i++
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,535 @@
+// arc implements an ARC cache.
+package arc
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hungpdn/grule-plus/cache/common"
+)
+
+// Cache is an ARC cache structure
+type Cache[K comparable, V any] struct {
+	maxEntries int                    // The maximum number of cache entries before an entry is evicted, zero means no limit
+	maxCost    int64                  // The maximum total cost of the cache entries before an entry is evicted, zero means no limit
+	cost       int64                  // Total cost of the cache entries
+	entries    map[K]*list.Element    // Map for quick access to cache entries
+	expiry     *common.Expiry[K]      // Expiration times of the entries with a TTL
+	t1         *list.List             // T1: recently accessed items
+	t2         *list.List             // T2: frequently accessed items
+	b1         *list.List             // B1: ghost entries evicted from T1
+	b2         *list.List             // B2: ghost entries evicted from T2
+	p          int                    // Target size for T1, adapts based on access patterns
+	mu         sync.RWMutex           // Mutex to ensure concurrent access safety
+	listeners  common.Listeners[K, V] // Listeners notified when an entry is purged from the cache
+	// cleanup
+	defaultTTL      time.Duration // default TTL for item expire
+	sliding         bool          // whether an access restarts the TTL of the entry
+	cleanupInterval time.Duration // how often to run the expired entry cleaner
+	stopChan        chan struct{} // Channel to stop cleanup goroutine
+	closed          bool          // Flag to indicate if cache is closed
+}
+
+// entry represents an entry in the ARC cache
+type entry[K comparable, V any] struct {
+	key        K
+	value      V
+	inT2       bool          // whether the entry is in T2
+	cost       int64         // weight of the entry counted against maxCost
+	expiration int64         // Unix timestamp (nanoseconds) when the item expires, 0 means never expires
+	ttl        time.Duration // time-to-live of the entry, restarted by each access when the TTL is sliding
+}
+
+// New creates a new ARC cache
+// maxEntries: the maximum number of cache entries before an entry is evicted, zero means no limit
+// cleanupInterval: how often to run the expired entry cleaner
+func New[K comparable, V any](maxEntries int, cleanupInterval time.Duration) *Cache[K, V] {
+	cache := &Cache[K, V]{
+		maxEntries:      maxEntries,
+		entries:         make(map[K]*list.Element),
+		expiry:          common.NewExpiry[K](),
+		t1:              list.New(),
+		t2:              list.New(),
+		b1:              list.New(),
+		b2:              list.New(),
+		p:               0, // Start with p = 0
+		cleanupInterval: cleanupInterval,
+		stopChan:        make(chan struct{}),
+	}
+	if cache.cleanupInterval > 0 {
+		go cache.startCleanup()
+	}
+	return cache
+}
+
+// Set inserts or updates the specified key-value pair with an expiration time, it costs 1
+func (c *Cache[K, V]) Set(key K, value V, duration time.Duration) {
+	c.SetWithCost(key, value, 1, duration)
+}
+
+// SetWithCost inserts or updates the specified key-value pair weighted by cost with an expiration time
+func (c *Cache[K, V]) SetWithCost(key K, value V, cost int64, duration time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ttl := duration
+	if ttl <= 0 {
+		ttl = c.defaultTTL
+	}
+	var expiration int64
+	if ttl > 0 {
+		expiration = time.Now().Add(ttl).UnixNano()
+	}
+
+	if ele, ok := c.entries[key]; ok {
+		// Update existing entry
+		ent := ele.Value.(*entry[K, V])
+		ent.value = value
+		ent.expiration = expiration
+		ent.ttl = ttl
+		c.expiry.Set(key, expiration)
+		// Move to T2 if in T1, or move to front of T2 if already in T2
+		if !ent.inT2 {
+			c.t1.Remove(ele)
+			ent.inT2 = true
+			c.entries[key] = c.t2.PushFront(ent)
+		} else {
+			c.t2.MoveToFront(ele)
+		}
+		c.cost += cost - ent.cost
+		ent.cost = cost
+		for c.overCost(0) && len(c.entries) > 1 {
+			c.evict()
+		}
+		return
+	}
+
+	// New entry - check ghost lists first
+	ent := &entry[K, V]{key: key, value: value, cost: cost, expiration: expiration, ttl: ttl}
+
+	// Check if in B1 or B2 (ghost entries)
+	inB1 := c.checkGhost(c.b1, key)
+	inB2 := c.checkGhost(c.b2, key)
+
+	if inB1 {
+		// Hit in B1, increase p
+		if c.b1.Len() > 0 {
+			c.p = min(c.p+max(1, c.b2.Len()/c.b1.Len()), c.maxEntries)
+		} else {
+			c.p = min(c.p+1, c.maxEntries)
+		}
+		c.replace(key) // This might be redundant, but follows ARC
+	} else if inB2 {
+		// Hit in B2, decrease p
+		if c.b2.Len() > 0 {
+			c.p = max(c.p-max(1, c.b1.Len()/c.b2.Len()), 0)
+		} else {
+			c.p = max(c.p-1, 0)
+		}
+		c.replace(key)
+	}
+
+	// Make room for the cost of the new entry
+	for c.overCost(cost) && len(c.entries) > 0 {
+		c.evict()
+	}
+
+	// Add to T1
+	c.entries[key] = c.t1.PushFront(ent)
+	c.expiry.Set(key, expiration)
+	c.cost += cost
+
+	// Check if we need to evict
+	if c.maxEntries > 0 && c.t1.Len()+c.t2.Len() > c.maxEntries {
+		c.evict()
+	}
+}
+
+// Get looks up a key's value from the cache
+func (c *Cache[K, V]) Get(key K) (value V, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ele, exists := c.entries[key]; exists {
+		ent := ele.Value.(*entry[K, V])
+		if ent.expiration > 0 && time.Now().UnixNano() > ent.expiration {
+			// Expired, remove it
+			c.removeElement(ele, common.ExpirationEvent)
+			return value, false
+		}
+		// Move to T2
+		if !ent.inT2 {
+			c.t1.Remove(ele)
+			ent.inT2 = true
+			c.entries[key] = c.t2.PushFront(ent)
+		} else {
+			c.t2.MoveToFront(ele)
+		}
+		c.slide(ent)
+		return ent.value, true
+	}
+
+	// Miss - check ghost lists
+	inB1 := c.checkGhost(c.b1, key)
+	inB2 := c.checkGhost(c.b2, key)
+
+	if inB1 {
+		if c.b1.Len() > 0 {
+			c.p = min(c.p+max(1, c.b2.Len()/c.b1.Len()), c.maxEntries)
+		} else {
+			c.p = min(c.p+1, c.maxEntries)
+		}
+	} else if inB2 {
+		if c.b2.Len() > 0 {
+			c.p = max(c.p-max(1, c.b1.Len()/c.b2.Len()), 0)
+		} else {
+			c.p = max(c.p-1, 0)
+		}
+	}
+
+	return value, false
+}
+
+// Has returns true if the key exists in the cache
+func (c *Cache[K, V]) Has(key K) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ele, ok := c.entries[key]
+	if !ok {
+		return false
+	}
+	ent := ele.Value.(*entry[K, V])
+	if ent.expiration > 0 && time.Now().UnixNano() > ent.expiration {
+		return false
+	}
+	return true
+}
+
+// Peek looks up a key's value without updating its recency or frequency
+func (c *Cache[K, V]) Peek(key K) (value V, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ele, exists := c.entries[key]
+	if !exists {
+		return value, false
+	}
+	ent := ele.Value.(*entry[K, V])
+	if ent.expiration > 0 && time.Now().UnixNano() > ent.expiration {
+		return value, false
+	}
+	return ent.value, true
+}
+
+// Delete removes the key from the cache, the eviction func receives a DeleteEvent.
+// It reports whether the key was in the cache
+func (c *Cache[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ele, ok := c.entries[key]
+	if !ok {
+		return false
+	}
+	c.removeElement(ele, common.DeleteEvent)
+	return true
+}
+
+// Keys returns a slice of the keys in the cache
+func (c *Cache[K, V]) Keys() []K {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	keys := make([]K, 0, len(c.entries))
+	now := time.Now().UnixNano()
+
+	for key, ele := range c.entries {
+		ent := ele.Value.(*entry[K, V])
+		if ent.expiration == 0 || now <= ent.expiration {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Len returns the number of items in the cache
+func (c *Cache[K, V]) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	count := 0
+	now := time.Now().UnixNano()
+
+	for _, ele := range c.entries {
+		ent := ele.Value.(*entry[K, V])
+		if ent.expiration == 0 || now <= ent.expiration {
+			count++
+		}
+	}
+	return count
+}
+
+// Clear purges all key-value pairs from the cache
+func (c *Cache[K, V]) Clear() {
+	// Note: This function assumes the caller has already acquired the mutex
+	for key, ele := range c.entries {
+		if c.listeners.Len() > 0 {
+			ent := ele.Value.(*entry[K, V])
+			c.listeners.Notify(key, ent.value, common.ClearEvent)
+		}
+	}
+
+	c.entries = make(map[K]*list.Element)
+	c.expiry.Clear()
+	c.t1.Init()
+	c.t2.Init()
+	c.b1.Init()
+	c.b2.Init()
+	c.p = 0
+	c.cost = 0
+}
+
+// Close purges all key-value pairs from the cache and stop cleanup
+func (c *Cache[K, V]) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Stop cleanup goroutine first
+	if !c.closed {
+		close(c.stopChan)
+		c.closed = true
+	}
+	c.Clear()
+}
+
+// AddListener registers a listener of the entries purged from the cache and returns its ID
+func (c *Cache[K, V]) AddListener(listener common.Listener[K, V]) int {
+	return c.listeners.Add(listener)
+}
+
+// RemoveListener unregisters the listener with the given ID, and reports whether it was registered
+func (c *Cache[K, V]) RemoveListener(id int) bool {
+	return c.listeners.Remove(id)
+}
+
+// SetMaxCost sets the maximum total cost of the cache entries, zero means no limit
+func (c *Cache[K, V]) SetMaxCost(maxCost int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxCost = maxCost
+	for c.overCost(0) && len(c.entries) > 1 {
+		c.evict()
+	}
+}
+
+// Resize changes the maximum number of entries, zero means no limit, and evicts the entries
+// over the new size. It returns the number of evicted entries
+func (c *Cache[K, V]) Resize(size int) (evicted int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := len(c.entries)
+	c.maxEntries = size
+	if size > 0 {
+		c.p = min(c.p, size)
+		for len(c.entries) > size {
+			c.evict()
+		}
+		for c.b1.Len() > size {
+			c.b1.Remove(c.b1.Back())
+		}
+		for c.b2.Len() > size {
+			c.b2.Remove(c.b2.Back())
+		}
+	}
+	return n - len(c.entries)
+}
+
+// Cost returns the total cost of the items in the cache
+func (c *Cache[K, V]) Cost() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cost
+}
+
+// SetDefaultTTL sets the default TTL for cache entries
+func (c *Cache[K, V]) SetDefaultTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.defaultTTL = ttl
+}
+
+// SetSlidingTTL sets whether an access restarts the TTL of the entry, so entries in use do not expire
+func (c *Cache[K, V]) SetSlidingTTL(sliding bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sliding = sliding
+}
+
+// overCost reports whether adding cost to the cache exceeds maxCost
+func (c *Cache[K, V]) overCost(cost int64) bool {
+	return c.maxCost > 0 && c.cost+cost > c.maxCost
+}
+
+// evict implements the ARC eviction policy
+func (c *Cache[K, V]) evict() {
+	if c.t1.Len() > 0 && (c.t1.Len() >= max(1, c.p) || c.t2.Len() == 0) {
+		// Evict from T1, add to B1
+		ele := c.t1.Back()
+		c.t1.Remove(ele)
+		ent := ele.Value.(*entry[K, V])
+		delete(c.entries, ent.key)
+		c.cost -= ent.cost
+		c.expiry.Remove(ent.key)
+		c.b1.PushFront(ent)
+		if c.b1.Len() > c.maxEntries {
+			c.b1.Remove(c.b1.Back())
+		}
+		c.listeners.Notify(ent.key, ent.value, common.EvictionEvent)
+	} else {
+		// Evict from T2, add to B2
+		ele := c.t2.Back()
+		c.t2.Remove(ele)
+		ent := ele.Value.(*entry[K, V])
+		delete(c.entries, ent.key)
+		c.cost -= ent.cost
+		c.expiry.Remove(ent.key)
+		c.b2.PushFront(ent)
+		if c.b2.Len() > c.maxEntries {
+			c.b2.Remove(c.b2.Back())
+		}
+		c.listeners.Notify(ent.key, ent.value, common.EvictionEvent)
+	}
+}
+
+// replace implements the ARC replace policy (simplified)
+func (c *Cache[K, V]) replace(key K) {
+	// ARC replace: if T1 is too big, evict from T1, else evict from T2
+	if c.t1.Len() >= max(1, c.p) {
+		// Evict from T1, add to B1
+		ele := c.t1.Back()
+		c.t1.Remove(ele)
+		ent := ele.Value.(*entry[K, V])
+		delete(c.entries, ent.key)
+		c.cost -= ent.cost
+		c.expiry.Remove(ent.key)
+		c.b1.PushFront(ent)
+		if c.b1.Len() > c.maxEntries {
+			c.b1.Remove(c.b1.Back())
+		}
+		c.listeners.Notify(ent.key, ent.value, common.EvictionEvent)
+	} else {
+		// Evict from T2, add to B2
+		ele := c.t2.Back()
+		c.t2.Remove(ele)
+		ent := ele.Value.(*entry[K, V])
+		delete(c.entries, ent.key)
+		c.cost -= ent.cost
+		c.expiry.Remove(ent.key)
+		c.b2.PushFront(ent)
+		if c.b2.Len() > c.maxEntries {
+			c.b2.Remove(c.b2.Back())
+		}
+		c.listeners.Notify(ent.key, ent.value, common.EvictionEvent)
+	}
+}
+
+// checkGhost checks if key exists in ghost list and removes it if found
+func (c *Cache[K, V]) checkGhost(list *list.List, key K) bool {
+	for ele := list.Front(); ele != nil; ele = ele.Next() {
+		if ele.Value.(*entry[K, V]).key == key {
+			list.Remove(ele)
+			return true
+		}
+	}
+	return false
+}
+
+// startCleanup starts the cleanup goroutine
+func (c *Cache[K, V]) startCleanup() {
+	ticker := time.NewTicker(c.cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.cleanup()
+		case <-c.stopChan:
+			return
+		}
+	}
+}
+
+// cleanup removes expired entries, in batches so that readers are not blocked for long
+func (c *Cache[K, V]) cleanup() {
+	removed := 0
+	for {
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+			break
+		}
+		keys := c.expiry.Expired(time.Now().UnixNano(), common.ExpiryBatch)
+		for _, key := range keys {
+			if ele, ok := c.entries[key]; ok {
+				c.removeElement(ele, common.ExpirationEvent)
+			}
+		}
+		c.mu.Unlock()
+
+		removed += len(keys)
+		if len(keys) < common.ExpiryBatch {
+			break
+		}
+	}
+
+	if removed > 0 {
+		fmt.Printf("Cache: Running cleanup routine, removed %d expired entries\n", removed)
+	}
+}
+
+// removeElement removes an element from the cache
+func (c *Cache[K, V]) removeElement(ele *list.Element, event int) {
+	ent := ele.Value.(*entry[K, V])
+	delete(c.entries, ent.key)
+	c.cost -= ent.cost
+	c.expiry.Remove(ent.key)
+
+	// Remove from whichever list it's in, list.Remove returns the value even for another list
+	if ent.inT2 {
+		c.t2.Remove(ele)
+	} else {
+		c.t1.Remove(ele)
+	}
+
+	c.listeners.Notify(ent.key, ent.value, event)
+}
+
+// max returns the maximum of two integers
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// min returns the minimum of two integers
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// slide restarts the TTL of an accessed entry when the TTL is sliding
+func (c *Cache[K, V]) slide(ent *entry[K, V]) {
+	if c.sliding && ent.ttl > 0 {
+		ent.expiration = time.Now().Add(ent.ttl).UnixNano()
+		c.expiry.Set(ent.key, ent.expiration)
+	}
+}
@@ -0,0 +1,128 @@
+// cache provides generic in-memory caches with pluggable eviction policies.
+package cache
+
+import (
+	"time"
+
+	"github.com/hungpdn/grule-plus/cache/arc"
+	"github.com/hungpdn/grule-plus/cache/common"
+	"github.com/hungpdn/grule-plus/cache/lfu"
+	"github.com/hungpdn/grule-plus/cache/lru"
+	"github.com/hungpdn/grule-plus/cache/random"
+	"github.com/hungpdn/grule-plus/cache/s3fifo"
+	"github.com/hungpdn/grule-plus/cache/sieve"
+	"github.com/hungpdn/grule-plus/cache/tinylfu"
+	"github.com/hungpdn/grule-plus/cache/twoq"
+)
+
+// CacheType defines the type of cache to be used.
+const (
+	LRU = iota
+	LFU
+	ARC
+	TWOQ
+	RANDOM
+	TINYLFU
+	SIEVE
+	S3FIFO
+)
+
+// Cache defines the interface for a cache system keyed by K and holding values of type V.
+type Cache[K comparable, V any] interface {
+	// Set inserts or updates the specified key-value pair with an expiration time
+	Set(key K, value V, duration time.Duration)
+	// SetWithCost inserts or updates the specified key-value pair weighted by cost with an expiration time
+	SetWithCost(key K, value V, cost int64, duration time.Duration)
+	// Get looks up a key's value from the cache
+	Get(key K) (value V, ok bool)
+	// Peek looks up a key's value without updating its recency or frequency, nor restarting a sliding TTL
+	Peek(key K) (value V, ok bool)
+	// Delete removes the key from the cache and reports whether it was present, with a DeleteEvent
+	Delete(key K) bool
+	// Has returns true if the key exists in the cache
+	Has(key K) bool
+	// Keys returns a slice of the keys in the cache
+	Keys() []K
+	// Len returns the number of items in the cache
+	Len() int
+	// Clear purges all key-value pairs from the cache
+	Clear()
+	// Close purges all key-value pairs from the cache and stop cleanup
+	Close()
+	// AddListener registers a listener of the entries purged from the cache and returns its ID
+	AddListener(listener common.Listener[K, V]) int
+	// RemoveListener unregisters the listener with the given ID, and reports whether it was registered
+	RemoveListener(id int) bool
+	// SetDefaultTTL sets the default TTL for cache entries
+	SetDefaultTTL(ttl time.Duration)
+	// SetSlidingTTL sets whether an access restarts the TTL of the entry, so entries in use do not expire
+	SetSlidingTTL(sliding bool)
+	// SetMaxCost sets the maximum total cost of the cache entries, zero means no limit
+	SetMaxCost(maxCost int64)
+	// Cost returns the total cost of the items in the cache
+	Cost() int64
+	// Resize changes the maximum number of entries, zero means no limit, and returns the number
+	// of entries evicted to fit, with an EvictionEvent
+	Resize(size int) (evicted int)
+}
+
+// ICache is the untyped cache used by the engine, where any key and value can be stored.
+type ICache = Cache[any, any]
+
+// Config holds the configuration for the cache.
+type Config[K comparable, V any] struct {
+	Type            int
+	Size            int
+	CleanupInterval time.Duration
+	DefaultTTL      time.Duration
+	SlidingTTL      bool // an access restarts the TTL of the entry instead of the TTL counting from Set
+	MaxCost         int64
+	Shards          int                      // number of independently locked segments, 0 or 1 means a single lock
+	ReadBuffer      int                      // with Shards, number of hits buffered per segment before the policy records them
+	ReadSample      int                      // with Shards, record one of every ReadSample hits in the policy
+	EvictedFunc     common.EvictedFunc[K, V] // registered as a synchronous listener of every event
+}
+
+// New creates a new untyped cache instance based on the provided configuration.
+func New(config Config[any, any]) ICache {
+	return NewCache(config)
+}
+
+// NewCache creates a new cache instance keyed by K and holding values of type V
+// based on the provided configuration.
+func NewCache[K comparable, V any](config Config[K, V]) Cache[K, V] {
+	if config.Shards > 1 {
+		return newSharded(config)
+	}
+
+	factories := map[int]func() Cache[K, V]{
+		LRU:     func() Cache[K, V] { return lru.New[K, V](config.Size, config.CleanupInterval) },
+		LFU:     func() Cache[K, V] { return lfu.New[K, V](config.Size, config.CleanupInterval) },
+		ARC:     func() Cache[K, V] { return arc.New[K, V](config.Size, config.CleanupInterval) },
+		TWOQ:    func() Cache[K, V] { return twoq.New[K, V](config.Size, config.CleanupInterval) },
+		RANDOM:  func() Cache[K, V] { return random.New[K, V](config.Size, config.CleanupInterval) },
+		TINYLFU: func() Cache[K, V] { return tinylfu.New[K, V](config.Size, config.CleanupInterval) },
+		SIEVE:   func() Cache[K, V] { return sieve.New[K, V](config.Size, config.CleanupInterval) },
+		S3FIFO:  func() Cache[K, V] { return s3fifo.New[K, V](config.Size, config.CleanupInterval) },
+	}
+
+	factory, ok := factories[config.Type]
+	if !ok {
+		panic("unknown type")
+	}
+
+	cache := factory()
+	if config.EvictedFunc != nil {
+		cache.AddListener(common.Listener[K, V]{Func: config.EvictedFunc})
+	}
+	if config.DefaultTTL > 0 {
+		cache.SetDefaultTTL(config.DefaultTTL)
+	}
+	if config.MaxCost > 0 {
+		cache.SetMaxCost(config.MaxCost)
+	}
+	if config.SlidingTTL {
+		cache.SetSlidingTTL(true)
+	}
+	return cache
+}
@@ -0,0 +1,274 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hungpdn/grule-plus/cache/common"
+)
+
+func TestNewCache(t *testing.T) {
+	for _, typ := range []int{LRU, LFU, ARC, TWOQ, RANDOM, TINYLFU, SIEVE, S3FIFO} {
+		var evicted []string
+		c := NewCache(Config[string, int]{
+			Type: typ,
+			Size: 1,
+			EvictedFunc: func(key string, value int, event int) {
+				if event == common.EvictionEvent {
+					evicted = append(evicted, key)
+				}
+			},
+		})
+
+		c.Set("a", 1, 0)
+		if v, ok := c.Get("a"); !ok || v != 1 {
+			t.Fatalf("type %d: Get a want 1 got %v %v", typ, v, ok)
+		}
+		c.Set("b", 2, 0)
+		if c.Len() != 1 || len(evicted) != 1 {
+			t.Fatalf("type %d: expected one eviction, len %d evicted %v", typ, c.Len(), evicted)
+		}
+		c.Close()
+	}
+}
+
+func TestUnlimitedSize(t *testing.T) {
+	for _, typ := range []int{LRU, LFU, ARC, TWOQ, RANDOM, TINYLFU, SIEVE, S3FIFO} {
+		c := NewCache(Config[int, int]{Type: typ})
+		for i := 0; i < 100; i++ {
+			c.Set(i, i, 0)
+		}
+		if c.Len() != 100 {
+			t.Fatalf("type %d: size 0 should not evict, len %d", typ, c.Len())
+		}
+		c.Clear()
+		c.Set(1, 1, 0)
+		if !c.Has(1) {
+			t.Fatalf("type %d: Set after Clear failed", typ)
+		}
+		c.Close()
+	}
+}
+
+func TestCleanupExpired(t *testing.T) {
+	for _, typ := range []int{LRU, LFU, ARC, TWOQ, RANDOM, TINYLFU, SIEVE, S3FIFO} {
+		var mu sync.Mutex
+		expired := 0
+		c := NewCache(Config[int, int]{
+			Type:            typ,
+			CleanupInterval: 20 * time.Millisecond,
+			EvictedFunc: func(key int, value int, event int) {
+				if event == common.ExpirationEvent {
+					mu.Lock()
+					expired++
+					mu.Unlock()
+				}
+			},
+		})
+
+		// More expired entries than a cleanup batch
+		n := 2*common.ExpiryBatch + 100
+		for i := 0; i < n; i++ {
+			c.Set(i, i, time.Millisecond)
+		}
+		c.Set(-1, -1, 0)
+		c.Set(-2, -2, time.Hour)
+
+		deadline := time.Now().Add(time.Second)
+		for time.Now().Before(deadline) {
+			mu.Lock()
+			done := expired == n
+			mu.Unlock()
+			if done {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		mu.Lock()
+		if expired != n {
+			t.Fatalf("type %d: want %d expired entries got %d", typ, n, expired)
+		}
+		mu.Unlock()
+		if !c.Has(-1) || !c.Has(-2) {
+			t.Fatalf("type %d: entries without expiration should be kept", typ)
+		}
+		c.Close()
+	}
+}
+
+func TestSlidingTTL(t *testing.T) {
+	for _, typ := range []int{LRU, LFU, ARC, TWOQ, RANDOM, TINYLFU, SIEVE, S3FIFO} {
+		c := NewCache(Config[string, int]{Type: typ, SlidingTTL: true})
+
+		c.Set("hot", 1, 60*time.Millisecond)
+		c.Set("cold", 2, 60*time.Millisecond)
+		c.Set("forever", 3, 0)
+
+		// Each access restarts the TTL of hot, cold expires from its Set
+		for i := 0; i < 6; i++ {
+			time.Sleep(20 * time.Millisecond)
+			if _, ok := c.Get("hot"); !ok {
+				t.Fatalf("type %d: hot should not expire while it is accessed", typ)
+			}
+		}
+		if c.Has("cold") {
+			t.Fatalf("type %d: cold should expire 60ms after Set", typ)
+		}
+		if _, ok := c.Get("forever"); !ok {
+			t.Fatalf("type %d: an entry without TTL should not expire", typ)
+		}
+
+		// Has does not count as an access
+		time.Sleep(80 * time.Millisecond)
+		if c.Has("hot") {
+			t.Fatalf("type %d: hot should expire 60ms after its last access", typ)
+		}
+		c.Close()
+	}
+}
+
+func TestDeletePeekResize(t *testing.T) {
+	for _, typ := range []int{LRU, LFU, ARC, TWOQ, RANDOM, TINYLFU, SIEVE, S3FIFO} {
+		events := make(map[int]int)
+		c := NewCache(Config[int, int]{
+			Type: typ,
+			Size: 10,
+			EvictedFunc: func(key int, value int, event int) {
+				events[event]++
+			},
+		})
+
+		for i := 0; i < 10; i++ {
+			c.Set(i, i, 0)
+		}
+		if v, ok := c.Peek(3); !ok || v != 3 {
+			t.Fatalf("type %d: Peek 3 want 3 got %v %v", typ, v, ok)
+		}
+		if _, ok := c.Peek(-1); ok {
+			t.Fatalf("type %d: Peek of a missing key should miss", typ)
+		}
+
+		// Delete reports a DeleteEvent only for present keys
+		if !c.Delete(3) || c.Delete(3) || c.Has(3) || c.Len() != 9 || c.Cost() != 9 {
+			t.Fatalf("type %d: Delete 3 should remove it once, len %d", typ, c.Len())
+		}
+		if events[common.DeleteEvent] != 1 || len(events) != 1 {
+			t.Fatalf("type %d: want one DeleteEvent, events %v", typ, events)
+		}
+
+		// Shrinking evicts down to the new size with EvictionEvents
+		if evicted := c.Resize(4); evicted != 5 || c.Len() != 4 || events[common.EvictionEvent] != 5 {
+			t.Fatalf("type %d: Resize 4 want 5 evicted, got %d len %d events %v", typ, evicted, c.Len(), events)
+		}
+		for i := 10; i < 20; i++ {
+			c.Set(i, i, 0)
+		}
+		if c.Len() != 4 {
+			t.Fatalf("type %d: want 4 entries after Resize, len %d", typ, c.Len())
+		}
+
+		// Zero means no limit
+		if evicted := c.Resize(0); evicted != 0 {
+			t.Fatalf("type %d: Resize 0 should not evict, evicted %d", typ, evicted)
+		}
+		for i := 20; i < 40; i++ {
+			c.Set(i, i, 0)
+		}
+		if c.Len() != 24 {
+			t.Fatalf("type %d: want 24 entries without limit, len %d", typ, c.Len())
+		}
+
+		// Peek does not remove an expired entry
+		expired := events[common.ExpirationEvent]
+		c.Set(-2, -2, time.Millisecond)
+		time.Sleep(2 * time.Millisecond)
+		if _, ok := c.Peek(-2); ok || events[common.ExpirationEvent] != expired {
+			t.Fatalf("type %d: Peek should miss an expired entry without an event", typ)
+		}
+		c.Close()
+	}
+}
+
+func TestMaxCost(t *testing.T) {
+	for _, typ := range []int{LRU, LFU, ARC, TWOQ, RANDOM, TINYLFU, SIEVE, S3FIFO} {
+		evicted := 0
+		c := NewCache(Config[string, int]{
+			Type:    typ,
+			MaxCost: 100,
+			EvictedFunc: func(key string, value int, event int) {
+				if event == common.EvictionEvent {
+					evicted++
+				}
+			},
+		})
+
+		for i := 0; i < 10; i++ {
+			c.SetWithCost(string(rune('a'+i)), i, 10, 0)
+		}
+		if c.Len() != 10 || c.Cost() != 100 || evicted != 0 {
+			t.Fatalf("type %d: want 10 entries of cost 100, len %d cost %d evicted %d", typ, c.Len(), c.Cost(), evicted)
+		}
+
+		// A heavy entry evicts as many entries as its cost needs
+		c.SetWithCost("heavy", 0, 35, 0)
+		if !c.Has("heavy") || c.Len() != 7 || c.Cost() != 95 || evicted != 4 {
+			t.Fatalf("type %d: want heavy and 6 entries of cost 95, len %d cost %d evicted %d", typ, c.Len(), c.Cost(), evicted)
+		}
+
+		// Growing an entry evicts down to the budget, an entry over the budget is kept alone
+		c.SetWithCost("heavy", 0, 150, 0)
+		if c.Cost() > 100 && c.Len() != 1 {
+			t.Fatalf("type %d: want the budget or a single entry, len %d cost %d", typ, c.Len(), c.Cost())
+		}
+
+		c.Clear()
+		if c.Cost() != 0 {
+			t.Fatalf("type %d: cost after clear want 0 got %d", typ, c.Cost())
+		}
+
+		// Lowering the budget evicts down to it
+		for i := 0; i < 10; i++ {
+			c.Set(string(rune('a'+i)), i, 0)
+		}
+		c.SetMaxCost(4)
+		if c.Len() != 4 || c.Cost() != 4 {
+			t.Fatalf("type %d: want 4 entries after SetMaxCost, len %d cost %d", typ, c.Len(), c.Cost())
+		}
+		c.Close()
+	}
+}
+
+func TestMaxCostUpdateKeepsKey(t *testing.T) {
+	for _, typ := range []int{LRU, LFU, ARC, TWOQ, RANDOM, TINYLFU, SIEVE, S3FIFO} {
+		c := NewCache(Config[string, int]{Type: typ, MaxCost: 100})
+
+		// The updated key is the oldest and least used entry, the first victim of every policy
+		c.SetWithCost("a", 0, 10, 0)
+		for i := 1; i < 10; i++ {
+			key := string(rune('a' + i))
+			c.SetWithCost(key, i, 10, 0)
+			c.Get(key)
+			c.Get(key)
+		}
+
+		// Growing it to a cost that fits alone evicts the other entries, never the key itself
+		c.SetWithCost("a", 1, 100, 0)
+		if v, ok := c.Peek("a"); !ok || v != 1 {
+			t.Fatalf("type %d: updated key evicted, Peek a got %v %v", typ, v, ok)
+		}
+		if c.Len() != 1 || c.Cost() != 100 {
+			t.Fatalf("type %d: want the updated key alone, len %d cost %d", typ, c.Len(), c.Cost())
+		}
+		c.Close()
+	}
+}
+
+func TestNewUnknownType(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic for unknown type")
+		}
+	}()
+	New(Config[any, any]{Type: -1})
+}
@@ -0,0 +1,12 @@
+package common
+
+// enum event for EvictedFunc
+const (
+	ExpirationEvent = iota // the entry expired, removed by a cleanup or the access finding it expired
+	EvictionEvent          // the policy evicted the entry to make room, on Set, SetMaxCost or Resize
+	DeleteEvent            // the entry was removed by Delete
+	ClearEvent             // the entry was purged by Clear or Close
+)
+
+// EvictedFunc is called with the key, value and event of an entry leaving the cache.
+type EvictedFunc[K comparable, V any] = func(key K, value V, event int)
@@ -0,0 +1,123 @@
+package common
+
+// ExpiryBatch is the maximum number of expired keys removed by a cleanup while holding the cache lock
+const ExpiryBatch = 1024
+
+// Expiry is a min-heap of the expiration times of cache keys, so that a cleanup only visits the
+// expired keys instead of scanning every entry. It is not safe for concurrent use, the cache
+// guards it with its own mutex.
+type Expiry[K comparable] struct {
+	items []expiryItem[K] // heap ordered by expiration
+	index map[K]int       // position of each key in items
+}
+
+// expiryItem is a key and its expiration time, Unix timestamp in nanoseconds
+type expiryItem[K comparable] struct {
+	key        K
+	expiration int64
+}
+
+// NewExpiry creates an empty expiry heap
+func NewExpiry[K comparable]() *Expiry[K] {
+	return &Expiry[K]{index: make(map[K]int)}
+}
+
+// Set schedules the expiration of the key, an expiration of 0 means the key never expires
+func (e *Expiry[K]) Set(key K, expiration int64) {
+	i, ok := e.index[key]
+	switch {
+	case expiration == 0:
+		if ok {
+			e.remove(i)
+		}
+	case ok:
+		e.items[i].expiration = expiration
+		if !e.up(i) {
+			e.down(i)
+		}
+	default:
+		e.items = append(e.items, expiryItem[K]{key: key, expiration: expiration})
+		e.index[key] = len(e.items) - 1
+		e.up(len(e.items) - 1)
+	}
+}
+
+// Remove forgets the expiration of the key
+func (e *Expiry[K]) Remove(key K) {
+	if i, ok := e.index[key]; ok {
+		e.remove(i)
+	}
+}
+
+// Expired removes and returns up to limit keys expired at now, the earliest first
+func (e *Expiry[K]) Expired(now int64, limit int) []K {
+	keys := make([]K, 0)
+	for len(keys) < limit && len(e.items) > 0 && now > e.items[0].expiration {
+		keys = append(keys, e.items[0].key)
+		e.remove(0)
+	}
+	return keys
+}
+
+// Len returns the number of keys with an expiration
+func (e *Expiry[K]) Len() int {
+	return len(e.items)
+}
+
+// Clear forgets all expirations
+func (e *Expiry[K]) Clear() {
+	e.items = nil
+	e.index = make(map[K]int)
+}
+
+// remove removes the item at position i
+func (e *Expiry[K]) remove(i int) {
+	last := len(e.items) - 1
+	delete(e.index, e.items[i].key)
+	if i != last {
+		e.items[i] = e.items[last]
+		e.index[e.items[i].key] = i
+	}
+	e.items = e.items[:last]
+	if i != last && !e.up(i) {
+		e.down(i)
+	}
+}
+
+// up moves the item at position i towards the root, and reports whether it moved
+func (e *Expiry[K]) up(i int) bool {
+	start := i
+	for i > 0 {
+		parent := (i - 1) / 2
+		if e.items[parent].expiration <= e.items[i].expiration {
+			break
+		}
+		e.swap(i, parent)
+		i = parent
+	}
+	return i != start
+}
+
+// down moves the item at position i towards the leaves
+func (e *Expiry[K]) down(i int) {
+	for {
+		smallest := i
+		for _, child := range []int{2*i + 1, 2*i + 2} {
+			if child < len(e.items) && e.items[child].expiration < e.items[smallest].expiration {
+				smallest = child
+			}
+		}
+		if smallest == i {
+			return
+		}
+		e.swap(i, smallest)
+		i = smallest
+	}
+}
+
+// swap swaps the items at positions i and j
+func (e *Expiry[K]) swap(i, j int) {
+	e.items[i], e.items[j] = e.items[j], e.items[i]
+	e.index[e.items[i].key] = i
+	e.index[e.items[j].key] = j
+}
@@ -0,0 +1,81 @@
+package common
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestExpiry(t *testing.T) {
+	e := NewExpiry[string]()
+
+	e.Set("a", 30)
+	e.Set("b", 10)
+	e.Set("c", 20)
+	e.Set("d", 0)
+	if e.Len() != 3 {
+		t.Fatalf("Len want 3 got %d", e.Len())
+	}
+
+	// Rescheduling and removing keys
+	e.Set("a", 5)
+	e.Set("c", 0)
+	e.Remove("missing")
+
+	if keys := e.Expired(15, 10); len(keys) != 2 || keys[0] != "a" || keys[1] != "b" {
+		t.Fatalf("Expired want [a b] got %v", keys)
+	}
+	if keys := e.Expired(15, 10); len(keys) != 0 {
+		t.Fatalf("Expired should not return keys twice, got %v", keys)
+	}
+	if e.Len() != 0 {
+		t.Fatalf("Len want 0 got %d", e.Len())
+	}
+}
+
+func TestExpiryLimit(t *testing.T) {
+	e := NewExpiry[int]()
+	for i := 1; i <= 10; i++ {
+		e.Set(i, int64(i))
+	}
+
+	if keys := e.Expired(100, 4); len(keys) != 4 || keys[0] != 1 || keys[3] != 4 {
+		t.Fatalf("Expired want the 4 earliest keys got %v", keys)
+	}
+	// Expiration is strict, a key expires after its timestamp
+	if keys := e.Expired(6, 10); len(keys) != 1 || keys[0] != 5 {
+		t.Fatalf("Expired want [5] got %v", keys)
+	}
+
+	e.Clear()
+	if e.Len() != 0 || len(e.Expired(100, 10)) != 0 {
+		t.Fatalf("Clear should forget all expirations")
+	}
+}
+
+func TestExpiryOrder(t *testing.T) {
+	e := NewExpiry[int]()
+	want := make(map[int]int64)
+	for i := 0; i < 1000; i++ {
+		key := rand.Intn(300)
+		switch expiration := int64(rand.Intn(10000)); {
+		case i%7 == 0:
+			e.Remove(key)
+			delete(want, key)
+		case i%5 == 0:
+			e.Set(key, 0)
+			delete(want, key)
+		default:
+			e.Set(key, expiration+1)
+			want[key] = expiration + 1
+		}
+	}
+
+	keys := e.Expired(1<<62, len(want)+1)
+	if len(keys) != len(want) {
+		t.Fatalf("Expired want %d keys got %d", len(want), len(keys))
+	}
+	if !sort.SliceIsSorted(keys, func(i, j int) bool { return want[keys[i]] < want[keys[j]] }) {
+		t.Fatalf("Expired keys should be ordered by expiration")
+	}
+}
@@ -0,0 +1,94 @@
+package common
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hungpdn/grule-plus/internal/utils"
+)
+
+// Listener subscribes a function to the entries leaving a cache.
+type Listener[K comparable, V any] struct {
+	Func   EvictedFunc[K, V] // called with the key, value and event of the entry
+	Events []int             // events delivered to Func, all events when empty
+	Async  bool              // call Func from a new goroutine instead of under the cache lock
+}
+
+// accepts reports whether the listener subscribed to the event
+func (l Listener[K, V]) accepts(event int) bool {
+	return len(l.Events) == 0 || slices.Contains(l.Events, event)
+}
+
+// subscription is a registered listener and its ID
+type subscription[K comparable, V any] struct {
+	id       int
+	listener Listener[K, V]
+}
+
+// Listeners is a registry of the listeners of a cache. Notify reads the listeners without locking,
+// so notifying a cache without listeners costs a single atomic load. The zero value is ready to use.
+type Listeners[K comparable, V any] struct {
+	mu     sync.Mutex                           // serializes Add and Remove
+	nextID int                                  // ID of the next registered listener
+	subs   atomic.Pointer[[]subscription[K, V]] // copied on write, never modified in place
+}
+
+// Add registers a listener and returns its ID for Remove
+func (l *Listeners[K, V]) Add(listener Listener[K, V]) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.nextID++
+	subs := append(l.load(), subscription[K, V]{id: l.nextID, listener: listener})
+	l.subs.Store(&subs)
+	return l.nextID
+}
+
+// Remove unregisters the listener with the given ID, and reports whether it was registered
+func (l *Listeners[K, V]) Remove(id int) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	subs := l.load()
+	i := slices.IndexFunc(subs, func(s subscription[K, V]) bool { return s.id == id })
+	if i < 0 {
+		return false
+	}
+	subs = slices.Delete(slices.Clone(subs), i, i+1)
+	l.subs.Store(&subs)
+	return true
+}
+
+// Len returns the number of registered listeners
+func (l *Listeners[K, V]) Len() int {
+	return len(l.load())
+}
+
+// Notify delivers an entry leaving the cache to the listeners subscribed to the event.
+// Synchronous listeners are called in registration order before Notify returns. A panic of an
+// asynchronous listener is logged instead of crashing the program.
+func (l *Listeners[K, V]) Notify(key K, value V, event int) {
+	for _, s := range l.load() {
+		if !s.listener.accepts(event) {
+			continue
+		}
+		if s.listener.Async {
+			go func() {
+				defer utils.RecoverWithContext(context.Background(), "[Listeners][Notify]")
+				s.listener.Func(key, value, event)
+			}()
+		} else {
+			s.listener.Func(key, value, event)
+		}
+	}
+}
+
+// load returns the registered listeners, the slice must not be modified
+func (l *Listeners[K, V]) load() []subscription[K, V] {
+	if subs := l.subs.Load(); subs != nil {
+		return *subs
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+func TestListeners(t *testing.T) {
+	var l Listeners[string, int]
+	l.Notify("a", 1, EvictionEvent) // no listeners
+
+	calls := make([]string, 0)
+	first := l.Add(Listener[string, int]{Func: func(key string, value int, event int) {
+		calls = append(calls, "first")
+	}})
+	l.Add(Listener[string, int]{
+		Func:   func(key string, value int, event int) { calls = append(calls, "expired") },
+		Events: []int{ExpirationEvent},
+	})
+	if l.Len() != 2 {
+		t.Fatalf("Len want 2 got %d", l.Len())
+	}
+
+	// Synchronous listeners are called in registration order, filtered by event
+	l.Notify("a", 1, ExpirationEvent)
+	l.Notify("b", 2, DeleteEvent)
+	if len(calls) != 3 || calls[0] != "first" || calls[1] != "expired" || calls[2] != "first" {
+		t.Fatalf("want [first expired first] got %v", calls)
+	}
+
+	if !l.Remove(first) || l.Remove(first) || l.Len() != 1 {
+		t.Fatalf("Remove should unregister the listener once, len %d", l.Len())
+	}
+	l.Notify("c", 3, DeleteEvent)
+	if len(calls) != 3 {
+		t.Fatalf("removed listener should not be called, calls %v", calls)
+	}
+}
+
+func TestListenersAsync(t *testing.T) {
+	var l Listeners[string, int]
+	done := make(chan string, 1)
+	l.Add(Listener[string, int]{
+		Func:  func(key string, value int, event int) { done <- key },
+		Async: true,
+	})
+
+	l.Notify("a", 1, ClearEvent)
+	select {
+	case key := <-done:
+		if key != "a" {
+			t.Fatalf("want key a got %s", key)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("asynchronous listener not called")
+	}
+}
+
+func TestListenersAsyncPanic(t *testing.T) {
+	var l Listeners[string, int]
+	done := make(chan string, 1)
+	l.Add(Listener[string, int]{
+		Func: func(key string, value int, event int) {
+			defer func() { done <- key }()
+			panic("listener failed")
+		},
+		Async: true,
+	})
+
+	// The panic of an asynchronous listener must not crash the test binary
+	l.Notify("a", 1, ClearEvent)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("asynchronous listener not called")
+	}
+	time.Sleep(10 * time.Millisecond)
+}
@@ -0,0 +1,461 @@
+// lfu implements an LFU cache.
+package lfu
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hungpdn/grule-plus/cache/common"
+)
+
+// entry holds a key-value item, its frequency count, and expiration.
+type entry[K comparable, V any] struct {
+	key        K
+	value      V
+	freq       int
+	cost       int64
+	expiration int64
+	ttl        time.Duration
+	node       *list.Element
+}
+
+// Cache is a fixed-maxEntries in-memory cache with LFU eviction and per-item TTL.
+type Cache[K comparable, V any] struct {
+	maxEntries      int
+	maxCost         int64
+	cost            int64
+	entries         map[K]*entry[K, V]
+	freqList        map[int]*list.List // maps frequency -> list of entries
+	expiry          *common.Expiry[K]  // expiration times of the entries with a TTL
+	minFreq         int
+	mu              sync.RWMutex
+	listeners       common.Listeners[K, V]
+	defaultTTL      time.Duration
+	sliding         bool
+	cleanupInterval time.Duration
+	stopCleanup     chan struct{}
+}
+
+// NewLFUCache creates an Cache with given maxEntries and starts a background
+// cleanup goroutine that runs every cleanupInterval.
+func New[K comparable, V any](maxEntries int, cleanupInterval time.Duration) *Cache[K, V] {
+	cache := &Cache[K, V]{
+		maxEntries:      maxEntries,
+		entries:         make(map[K]*entry[K, V]),
+		freqList:        make(map[int]*list.List),
+		expiry:          common.NewExpiry[K](),
+		minFreq:         0,
+		cleanupInterval: cleanupInterval,
+		stopCleanup:     make(chan struct{}),
+	}
+	// Start background cleanup of expired entries
+	if cache.cleanupInterval > 0 {
+		go cache.startCleanup()
+	}
+	return cache
+}
+
+// NewWithEvictionFunc creates an Cache with given maxEntries and eviction callback function,
+// and starts a background cleanup goroutine that runs every cleanupInterval.
+func NewWithEvictionFunc[K comparable, V any](maxEntries int, cleanupInterval time.Duration, f common.EvictedFunc[K, V]) *Cache[K, V] {
+	c := New[K, V](maxEntries, cleanupInterval)
+	c.AddListener(common.Listener[K, V]{Func: f})
+	return c
+}
+
+// AddListener registers a listener of the entries purged from the cache and returns its ID
+func (c *Cache[K, V]) AddListener(listener common.Listener[K, V]) int {
+	return c.listeners.Add(listener)
+}
+
+// RemoveListener unregisters the listener with the given ID, and reports whether it was registered
+func (c *Cache[K, V]) RemoveListener(id int) bool {
+	return c.listeners.Remove(id)
+}
+
+// SetDefaultTTL sets the default TTL for items. A zero duration means no default TTL.
+func (c *Cache[K, V]) SetDefaultTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.defaultTTL = ttl
+}
+
+// SetSlidingTTL sets whether an access restarts the TTL of the entry, so entries in use do not expire.
+func (c *Cache[K, V]) SetSlidingTTL(sliding bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sliding = sliding
+}
+
+// SetMaxCost updates the maximum total cost of the entries, zero means no limit.
+func (c *Cache[K, V]) SetMaxCost(maxCost int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxCost = maxCost
+	for c.overCost(0) && len(c.entries) > 1 {
+		c.evict()
+	}
+}
+
+// Resize changes the maximum number of entries, zero means no limit, and evicts the entries
+// over the new size. It returns the number of evicted entries.
+func (c *Cache[K, V]) Resize(size int) (evicted int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := len(c.entries)
+	c.maxEntries = size
+	for size > 0 && len(c.entries) > size {
+		c.evict()
+	}
+	return n - len(c.entries)
+}
+
+// Cost returns the total cost of the items in the cache.
+func (c *Cache[K, V]) Cost() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.cost
+}
+
+// Set inserts or updates a key with the given value and TTL (in seconds), it costs 1.
+func (c *Cache[K, V]) Set(key K, value V, duration time.Duration) {
+	c.SetWithCost(key, value, 1, duration)
+}
+
+// SetWithCost inserts or updates a key with the given value weighted by cost and TTL.
+// If the cache is at maxEntries or maxCost, it evicts the least-frequently used items.
+func (c *Cache[K, V]) SetWithCost(key K, value V, cost int64, duration time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ttl := duration
+	if ttl <= 0 || (c.defaultTTL > 0 && ttl > c.defaultTTL) {
+		ttl = c.defaultTTL
+	}
+	expiration := int64(0)
+	if ttl > 0 {
+		expiration = time.Now().Add(ttl).UnixNano()
+	}
+
+	// Update existing entry
+	if entry, ok := c.entries[key]; ok {
+		entry.value = value
+		entry.expiration = expiration
+		entry.ttl = ttl
+		c.expiry.Set(key, expiration)
+		c.cost += cost - entry.cost
+		entry.cost = cost
+
+		// Increase frequency
+		c.incrementFrequency(entry)
+		for c.overCost(0) && len(c.entries) > 1 {
+			victim := c.victim(entry)
+			c.removeEntry(victim, common.EvictionEvent)
+			delete(c.entries, victim.key)
+		}
+		return
+	}
+
+	// Evict if necessary
+	if c.maxEntries > 0 && len(c.entries) >= c.maxEntries {
+		c.evict()
+	}
+	for c.overCost(cost) && len(c.entries) > 0 {
+		c.evict()
+	}
+
+	// Insert new entry at frequency 1
+	entry := &entry[K, V]{
+		key:        key,
+		value:      value,
+		freq:       1,
+		cost:       cost,
+		expiration: expiration,
+		ttl:        ttl,
+	}
+
+	c.entries[key] = entry
+	c.expiry.Set(key, expiration)
+	c.cost += cost
+	if c.freqList[1] == nil {
+		c.freqList[1] = list.New()
+	}
+	entry.node = c.freqList[1].PushBack(entry)
+	c.minFreq = 1
+}
+
+// overCost reports whether adding cost to the cache exceeds maxCost.
+func (c *Cache[K, V]) overCost(cost int64) bool {
+	return c.maxCost > 0 && c.cost+cost > c.maxCost
+}
+
+// Get retrieves the value for a key, returning (nil,false) if not found or expired.
+// On a hit, it increments the access frequency (LFU policy).
+func (c *Cache[K, V]) Get(key K) (value V, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	// Check expiration
+	if entry.expiration > 0 && time.Now().UnixNano() > entry.expiration {
+		// Remove expired entry
+		c.removeEntry(entry, common.ExpirationEvent)
+		delete(c.entries, key)
+		return
+	}
+	// Increment frequency and return value
+	c.incrementFrequency(entry)
+	c.slide(entry)
+	return entry.value, true
+}
+
+// incrementFrequency moves an entry from freq -> freq+1 list.
+func (c *Cache[K, V]) incrementFrequency(entry *entry[K, V]) {
+	freq := entry.freq
+	// Remove from current frequency list
+	c.freqList[freq].Remove(entry.node)
+	if c.freqList[freq].Len() == 0 {
+		delete(c.freqList, freq)
+		if c.minFreq == freq {
+			c.minFreq++
+		}
+	}
+	// Add to next frequency list
+	entry.freq++
+	if c.freqList[entry.freq] == nil {
+		c.freqList[entry.freq] = list.New()
+	}
+	entry.node = c.freqList[entry.freq].PushBack(entry)
+}
+
+// evict removes the least frequently used entry (and oldest among ties).
+func (c *Cache[K, V]) evict() {
+	// Find list of entries with minFreq
+	list := c.freqList[c.minFreq]
+	if list == nil {
+		// minFreq is stale after a removal, find the lowest frequency
+		c.minFreq = 0
+		for freq := range c.freqList {
+			if c.minFreq == 0 || freq < c.minFreq {
+				c.minFreq = freq
+			}
+		}
+		if list = c.freqList[c.minFreq]; list == nil {
+			return
+		}
+	}
+	// Remove oldest entry from this list
+	oldest := list.Front().Value.(*entry[K, V])
+	list.Remove(list.Front())
+	delete(c.entries, oldest.key)
+	c.expiry.Remove(oldest.key)
+	c.cost -= oldest.cost
+	if list.Len() == 0 {
+		delete(c.freqList, c.minFreq)
+		// next minFreq will reset on new insert
+	}
+	c.listeners.Notify(oldest.key, oldest.value, common.EvictionEvent)
+}
+
+// victim returns the least frequently used entry other than keep, the oldest one among equals.
+func (c *Cache[K, V]) victim(keep *entry[K, V]) *entry[K, V] {
+	var victim *entry[K, V]
+	for freq, list := range c.freqList {
+		if victim != nil && freq >= victim.freq {
+			continue
+		}
+		for ele := list.Front(); ele != nil; ele = ele.Next() {
+			if ent := ele.Value.(*entry[K, V]); ent != keep {
+				victim = ent
+				break
+			}
+		}
+	}
+	return victim
+}
+
+// removeEntry removes an entry from its frequency list (used on expiration).
+func (c *Cache[K, V]) removeEntry(entry *entry[K, V], event int) {
+	list := c.freqList[entry.freq]
+	if list != nil {
+		list.Remove(entry.node)
+		c.expiry.Remove(entry.key)
+		c.cost -= entry.cost
+		if list.Len() == 0 {
+			delete(c.freqList, entry.freq)
+			if entry.freq == c.minFreq {
+				c.minFreq = 1 // reset; will be recomputed on next insert
+			}
+		}
+		c.listeners.Notify(entry.key, entry.value, event)
+	}
+}
+
+// Has checks if a key exists and is not expired, without updating its frequency.
+func (c *Cache[K, V]) Has(key K) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.entries == nil {
+		return false
+	}
+
+	if entry, hit := c.entries[key]; hit {
+		if entry.expiration > 0 && time.Now().UnixNano() > entry.expiration {
+			return false
+		}
+		return true
+	}
+
+	return false
+}
+
+// Peek retrieves the value for a key without updating its frequency.
+func (c *Cache[K, V]) Peek(key K) (value V, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if entry, hit := c.entries[key]; hit {
+		if entry.expiration > 0 && time.Now().UnixNano() > entry.expiration {
+			return
+		}
+		return entry.value, true
+	}
+	return
+}
+
+// Delete removes a key from the cache, the eviction func receives a DeleteEvent.
+// Returns true if the key was present.
+func (c *Cache[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ele, hit := c.entries[key]; hit {
+		c.removeEntry(ele, common.DeleteEvent)
+		delete(c.entries, key)
+		return true
+	}
+
+	return false
+}
+
+// Len returns the number of items in the cache.
+func (c *Cache[K, V]) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.entries == nil {
+		return 0
+	}
+	return len(c.entries)
+}
+
+// Clear removes all items from the cache.
+func (c *Cache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.listeners.Len() > 0 {
+		for _, entry := range c.entries {
+			c.listeners.Notify(entry.key, entry.value, common.ClearEvent)
+		}
+	}
+
+	c.entries = make(map[K]*entry[K, V])
+	c.freqList = make(map[int]*list.List)
+	c.minFreq = 0
+	c.expiry.Clear()
+	c.cost = 0
+}
+
+// startCleanup runs in background to delete all expired entries periodically.
+// This uses a ticker to scan the map and remove outdated entries:contentReference[oaicite:2]{index=2}.
+func (c *Cache[K, V]) startCleanup() {
+	ticker := time.NewTicker(c.cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			fmt.Println("Cache: Running cleanup routine...")
+			c.cleanupExpiredEntries()
+		case <-c.stopCleanup:
+			fmt.Println("Cache: Stopping cleanup routine...")
+			return
+		}
+	}
+}
+
+// cleanupExpiredEntries removes the expired entries, in batches so that readers are not blocked for long.
+func (c *Cache[K, V]) cleanupExpiredEntries() {
+	for {
+		c.mu.Lock()
+		keys := c.expiry.Expired(time.Now().UnixNano(), common.ExpiryBatch)
+		for _, key := range keys {
+			if entry, ok := c.entries[key]; ok {
+				c.removeEntry(entry, common.ExpirationEvent)
+				delete(c.entries, key)
+			}
+		}
+		c.mu.Unlock()
+
+		if len(keys) < common.ExpiryBatch {
+			return
+		}
+	}
+}
+
+func (c *Cache[K, V]) StopCleanup() {
+	if c.stopCleanup != nil {
+		close(c.stopCleanup)
+	}
+}
+
+// Keys returns a slice of all keys in the cache.
+func (c *Cache[K, V]) Keys() []K {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	keys := make([]K, 0, len(c.entries))
+	for k := range c.entries {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Close stops the background cleanup goroutine.
+func (c *Cache[K, V]) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.StopCleanup()
+
+	if c.listeners.Len() > 0 {
+		for _, entry := range c.entries {
+			c.listeners.Notify(entry.key, entry.value, common.ClearEvent)
+		}
+	}
+
+	c.entries = make(map[K]*entry[K, V])
+	c.freqList = make(map[int]*list.List)
+	c.minFreq = 0
+	c.expiry.Clear()
+	c.cost = 0
+}
+
+// slide restarts the TTL of an accessed entry when the TTL is sliding.
+func (c *Cache[K, V]) slide(ent *entry[K, V]) {
+	if c.sliding && ent.ttl > 0 {
+		ent.expiration = time.Now().Add(ent.ttl).UnixNano()
+		c.expiry.Set(ent.key, ent.expiration)
+	}
+}
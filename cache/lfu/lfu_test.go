@@ -4,11 +4,11 @@ import (
 	"testing"
 	"time"
 
-	"github.com/hungpdn/grule-plus/internal/cache/common"
+	"github.com/hungpdn/grule-plus/cache/common"
 )
 
 func TestNewAndLen(t *testing.T) {
-	c := New(0, 0)
+	c := New[string, any](0, 0)
 	defer c.StopCleanup()
 	if c == nil {
 		t.Fatalf("New returned nil cache")
@@ -19,7 +19,7 @@ func TestNewAndLen(t *testing.T) {
 }
 
 func TestSetAndGet(t *testing.T) {
-	c := New(0, 0)
+	c := New[string, any](0, 0)
 	defer c.StopCleanup()
 	c.Set("a", "va", 0)
 	v, ok := c.Get("a")
@@ -29,7 +29,7 @@ func TestSetAndGet(t *testing.T) {
 }
 
 func TestHasAndDelete(t *testing.T) {
-	c := New(2, 0)
+	c := New[string, any](2, 0)
 	defer c.StopCleanup()
 	c.Set("b", "vb", 0)
 	if !c.Has("b") {
@@ -44,7 +44,7 @@ func TestHasAndDelete(t *testing.T) {
 }
 
 func TestEvictionPolicy(t *testing.T) {
-	c := New(2, 0)
+	c := New[string, any](2, 0)
 	defer c.StopCleanup()
 	c.Set("x", 1, 0)
 	c.Set("y", 2, 0)
@@ -60,7 +60,7 @@ func TestEvictionPolicy(t *testing.T) {
 }
 
 func TestDefaultTTLAndExpiration(t *testing.T) {
-	c := New(0, 0)
+	c := New[string, any](0, 0)
 	defer c.StopCleanup()
 	c.SetDefaultTTL(20 * time.Millisecond)
 	c.Set("ttl", "vttl", 50*time.Millisecond) // should use default 20ms
@@ -76,7 +76,7 @@ func TestDefaultTTLAndExpiration(t *testing.T) {
 }
 
 func TestCleanupGoroutine(t *testing.T) {
-	c := New(0, 15*time.Millisecond)
+	c := New[string, any](0, 15*time.Millisecond)
 	defer c.StopCleanup()
 	c.Set("z", "vz", 10*time.Millisecond)
 	time.Sleep(60 * time.Millisecond)
@@ -85,12 +85,12 @@ func TestCleanupGoroutine(t *testing.T) {
 	}
 }
 
-func TestEvictedFuncAndSetEvictedFunc(t *testing.T) {
+func TestEvictedFuncAndAddListener(t *testing.T) {
 	events := make(chan int, 4)
-	f := func(key, value any, event int) {
+	f := func(key string, value any, event int) {
 		events <- event
 	}
-	c := NewWithEvictionFunc(2, 0, f)
+	c := NewWithEvictionFunc[string, any](2, 0, f)
 	c.Set("a", 1, 0)
 	c.Set("b", 2, 0)
 	c.Set("c", 3, 0) // should evict a
@@ -102,13 +102,27 @@ func TestEvictedFuncAndSetEvictedFunc(t *testing.T) {
 	case <-time.After(50 * time.Millisecond):
 		t.Fatalf("eviction event not received")
 	}
-	c2 := New(0, 0)
+	// Every listener receives the events it subscribed to, until it is removed
+	c2 := New[string, any](0, 0)
 	defer c2.StopCleanup()
-	if err := c2.SetEvictedFunc(f); err != nil {
-		t.Fatalf("unexpected error setting eviction func: %v", err)
-	}
-	if err := c2.SetEvictedFunc(f); err == nil {
-		t.Fatalf("expected error when setting eviction func twice")
+	deletes := 0
+	id := c2.AddListener(common.Listener[string, any]{Func: f})
+	c2.AddListener(common.Listener[string, any]{
+		Func:   func(key string, value any, event int) { deletes++ },
+		Events: []int{common.DeleteEvent},
+	})
+	c2.Set("a", 1, 0)
+	c2.Delete("a")
+	if ev := <-events; ev != common.DeleteEvent || deletes != 1 {
+		t.Fatalf("expected DeleteEvent for both listeners got %d, deletes %d", ev, deletes)
+	}
+	if !c2.RemoveListener(id) || c2.RemoveListener(id) {
+		t.Fatalf("RemoveListener should remove the listener once")
+	}
+	c2.Set("b", 2, 0)
+	c2.Delete("b")
+	if len(events) != 0 || deletes != 2 {
+		t.Fatalf("removed listener should not receive events, deletes %d", deletes)
 	}
 	c.Close()
 	select {
@@ -122,7 +136,7 @@ func TestEvictedFuncAndSetEvictedFunc(t *testing.T) {
 }
 
 func TestKeysAndClear(t *testing.T) {
-	c := New(2, 0)
+	c := New[string, any](2, 0)
 	defer c.StopCleanup()
 	c.Set("a", 1, 0)
 	c.Set("b", 2, 0)
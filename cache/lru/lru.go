@@ -0,0 +1,382 @@
+// lru implements an LRU cache
+package lru
+
+import (
+	"container/list"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/hungpdn/grule-plus/cache/common"
+)
+
+// Cache is an LRU cache structure
+type Cache[K comparable, V any] struct {
+	maxEntries int                    // The maximum number of cache entries before an entry is evicte, zero means no limit
+	maxCost    int64                  // The maximum total cost of the cache entries before an entry is evicted, zero means no limit
+	cost       int64                  // Total cost of the cache entries
+	entries    map[K]*list.Element    // Map for quick access to cache entries
+	ll         *list.List             // Doubly linked list to track LRU order
+	expiry     *common.Expiry[K]      // Expiration times of the entries with a TTL
+	mu         sync.RWMutex           // Mutex to ensure concurrent access safety
+	listeners  common.Listeners[K, V] // Listeners notified when an entry is purged from the cache
+	// cleanup
+	defaultTTL      time.Duration // default TTL for item expire
+	sliding         bool          // whether an access restarts the TTL of the entry
+	cleanupInterval time.Duration // how often to run the expired entry cleaner
+	stopChan        chan struct{} // Channel to stop cleanup goroutine
+}
+
+// entry represents an entry in the LRU cache
+type entry[K comparable, V any] struct {
+	key        K
+	value      V
+	cost       int64         // weight of the entry counted against maxCost
+	expiration int64         // Unix timestamp (nanoseconds) when the item expires, 0 means never expires
+	ttl        time.Duration // time-to-live of the entry, restarted by each access when the TTL is sliding
+}
+
+// New creates a new LRU cache
+// maxEntries: the maximum number of cache entries before an entry is evicted, zero means no limit
+// cleanupInterval: how often to run the expired entry cleaner
+func New[K comparable, V any](maxEntries int, cleanupInterval time.Duration) *Cache[K, V] {
+	cache := &Cache[K, V]{
+		maxEntries:      maxEntries,
+		entries:         make(map[K]*list.Element),
+		ll:              list.New(),
+		expiry:          common.NewExpiry[K](),
+		cleanupInterval: cleanupInterval,
+		stopChan:        make(chan struct{}),
+	}
+	if cache.cleanupInterval > 0 {
+		go cache.startCleanup()
+	}
+	return cache
+}
+
+// NewWithEvictionFunc creates an LRU of the given size with the given eviction func
+func NewWithEvictionFunc[K comparable, V any](maxEntries int, cleanupInterval time.Duration, f common.EvictedFunc[K, V]) *Cache[K, V] {
+	c := New[K, V](maxEntries, cleanupInterval)
+	c.AddListener(common.Listener[K, V]{Func: f})
+	return c
+}
+
+// AddListener registers a listener of the entries purged from the cache and returns its ID
+func (c *Cache[K, V]) AddListener(listener common.Listener[K, V]) int {
+	return c.listeners.Add(listener)
+}
+
+// RemoveListener unregisters the listener with the given ID, and reports whether it was registered
+func (c *Cache[K, V]) RemoveListener(id int) bool {
+	return c.listeners.Remove(id)
+}
+
+// SetDefaultTTL updates the defaultTTL
+func (c *Cache[K, V]) SetDefaultTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.defaultTTL = ttl
+}
+
+// SetSlidingTTL sets whether an access restarts the TTL of the entry, so entries in use do not expire
+func (c *Cache[K, V]) SetSlidingTTL(sliding bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sliding = sliding
+}
+
+// SetMaxCost updates the maximum total cost of the cache entries, zero means no limit
+func (c *Cache[K, V]) SetMaxCost(maxCost int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxCost = maxCost
+	for c.overCost(0) && len(c.entries) > 1 {
+		c.RemoveOldest()
+	}
+}
+
+// Resize changes the maximum number of entries, zero means no limit, and evicts the entries
+// over the new size. It returns the number of evicted entries
+func (c *Cache[K, V]) Resize(size int) (evicted int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := len(c.entries)
+	c.maxEntries = size
+	for size > 0 && len(c.entries) > size {
+		c.RemoveOldest()
+	}
+	return n - len(c.entries)
+}
+
+// Cost returns the total cost of the items in the cache
+func (c *Cache[K, V]) Cost() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.cost
+}
+
+// Add adds or updates a value to the cache, it costs 1
+func (c *Cache[K, V]) Set(key K, value V, duration time.Duration) {
+	c.SetWithCost(key, value, 1, duration)
+}
+
+// SetWithCost adds or updates a value weighted by cost to the cache
+func (c *Cache[K, V]) SetWithCost(key K, value V, cost int64, duration time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = make(map[K]*list.Element)
+		c.ll = list.New()
+	}
+
+	ttl := duration
+	if ttl <= 0 || (c.defaultTTL > 0 && ttl > c.defaultTTL) {
+		ttl = c.defaultTTL
+	}
+	expiration := int64(0)
+	if ttl > 0 {
+		expiration = time.Now().Add(ttl).UnixNano()
+	}
+
+	if ele, ok := c.entries[key]; ok {
+		c.ll.MoveToFront(ele)
+		entry := ele.Value.(*entry[K, V])
+		entry.value = value
+		entry.expiration = expiration
+		entry.ttl = ttl
+		c.expiry.Set(key, expiration)
+		c.cost += cost - entry.cost
+		entry.cost = cost
+		for c.overCost(0) && len(c.entries) > 1 {
+			c.RemoveOldest()
+		}
+		return
+	}
+
+	if c.maxEntries != 0 && c.ll.Len() >= c.maxEntries {
+		c.RemoveOldest()
+	}
+	for c.overCost(cost) && len(c.entries) > 0 {
+		c.RemoveOldest()
+	}
+
+	entry := &entry[K, V]{
+		key:        key,
+		value:      value,
+		cost:       cost,
+		expiration: expiration,
+		ttl:        ttl,
+	}
+	ele := c.ll.PushFront(entry)
+	c.entries[key] = ele
+	c.expiry.Set(key, expiration)
+	c.cost += cost
+}
+
+// overCost reports whether adding cost to the cache exceeds the maximum total cost
+func (c *Cache[K, V]) overCost(cost int64) bool {
+	return c.maxCost > 0 && c.cost+cost > c.maxCost
+}
+
+// Get looks up a key's value from the cache
+func (c *Cache[K, V]) Get(key K) (value V, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		return
+	}
+	if ele, hit := c.entries[key]; hit {
+		entry := ele.Value.(*entry[K, V])
+		if entry.expiration > 0 && time.Now().UnixNano() > entry.expiration {
+			c.removeElement(ele, common.ExpirationEvent)
+			return
+		}
+		c.ll.MoveToFront(ele)
+		c.slide(entry)
+		return entry.value, true
+	}
+	return
+}
+
+// Has returns true if the key exists in the cache.
+func (c *Cache[K, V]) Has(key K) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.entries == nil {
+		return false
+	}
+	if ele, hit := c.entries[key]; hit {
+		entry := ele.Value.(*entry[K, V])
+		if entry.expiration > 0 && time.Now().UnixNano() > entry.expiration {
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// Peek looks up a key's value without updating its recency
+func (c *Cache[K, V]) Peek(key K) (value V, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if ele, hit := c.entries[key]; hit {
+		entry := ele.Value.(*entry[K, V])
+		if entry.expiration > 0 && time.Now().UnixNano() > entry.expiration {
+			return
+		}
+		return entry.value, true
+	}
+	return
+}
+
+// Delete deletes a key-value from the cache, the eviction func receives a DeleteEvent
+func (c *Cache[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ele, hit := c.entries[key]; hit {
+		c.removeElement(ele, common.DeleteEvent)
+		return true
+	}
+	return false
+}
+
+// Len returns the number of items in the cache
+func (c *Cache[K, V]) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.entries == nil {
+		return 0
+	}
+	return c.ll.Len()
+}
+
+// Clear purges all stored items from the cache
+func (c *Cache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.listeners.Len() > 0 {
+		for _, e := range c.entries {
+			entry := e.Value.(*entry[K, V])
+			c.listeners.Notify(entry.key, entry.value, common.ClearEvent)
+		}
+	}
+	c.ll = nil
+	c.entries = nil
+	c.expiry.Clear()
+	c.cost = 0
+}
+
+// RemoveOldest removes the oldest item from the cache
+func (c *Cache[K, V]) RemoveOldest() {
+	if c.entries == nil {
+		return
+	}
+	ele := c.ll.Back()
+	if ele != nil {
+		c.removeElement(ele, common.EvictionEvent)
+	}
+}
+
+// removeElement removes the a item from the cache
+func (c *Cache[K, V]) removeElement(e *list.Element, event int) {
+	c.ll.Remove(e)
+	entry := e.Value.(*entry[K, V])
+	delete(c.entries, entry.key)
+	c.expiry.Remove(entry.key)
+	c.cost -= entry.cost
+	c.listeners.Notify(entry.key, entry.value, event)
+}
+
+// startCleanup cleanup expired entry periodically
+func (c *Cache[K, V]) startCleanup() {
+	ticker := time.NewTicker(c.cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			fmt.Println("Cache: Running cleanup routine...")
+			c.cleanupExpiredEntries()
+			runtime.GC()
+		case <-c.stopChan:
+			fmt.Println("Cache: Stopping cleanup routine...")
+			return
+		}
+	}
+}
+
+// cleanupExpiredEntries cleanup expired entry, in batches so that readers are not blocked for long
+func (c *Cache[K, V]) cleanupExpiredEntries() {
+	for {
+		c.mu.Lock()
+		keys := c.expiry.Expired(time.Now().UnixNano(), common.ExpiryBatch)
+		for _, key := range keys {
+			if ele, ok := c.entries[key]; ok {
+				c.removeElement(ele, common.ExpirationEvent)
+			}
+		}
+		c.mu.Unlock()
+
+		if len(keys) < common.ExpiryBatch {
+			return
+		}
+	}
+}
+
+// StopCleanup stops goroutine cleanup
+func (c *Cache[K, V]) StopCleanup() {
+	if c.stopChan != nil {
+		close(c.stopChan)
+	}
+}
+
+// Keys returns a slice of the keys in the cache
+func (c *Cache[K, V]) Keys() []K {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	keys := make([]K, 0, len(c.entries))
+	for k := range c.entries {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Close purges all key-value pairs from the cache and stop cleanup
+func (c *Cache[K, V]) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.StopCleanup()
+
+	if c.listeners.Len() > 0 {
+		for _, e := range c.entries {
+			entry := e.Value.(*entry[K, V])
+			c.listeners.Notify(entry.key, entry.value, common.ClearEvent)
+		}
+	}
+	c.ll = nil
+	c.entries = nil
+	c.expiry.Clear()
+	c.cost = 0
+}
+
+// slide restarts the TTL of an accessed entry when the TTL is sliding
+func (c *Cache[K, V]) slide(ent *entry[K, V]) {
+	if c.sliding && ent.ttl > 0 {
+		ent.expiration = time.Now().Add(ent.ttl).UnixNano()
+		c.expiry.Set(ent.key, ent.expiration)
+	}
+}
@@ -5,11 +5,11 @@ import (
 	"testing"
 	"time"
 
-	"github.com/hungpdn/grule-plus/internal/cache/common"
+	"github.com/hungpdn/grule-plus/cache/common"
 )
 
 func TestBasicSetGetDelete(t *testing.T) {
-	c := New(0, 0)
+	c := New[string, any](0, 0)
 	defer c.StopCleanup()
 
 	if got := c.Len(); got != 0 {
@@ -41,7 +41,7 @@ func TestBasicSetGetDelete(t *testing.T) {
 }
 
 func TestLRUEviction(t *testing.T) {
-	c := New(2, 0)
+	c := New[string, any](2, 0)
 	defer c.StopCleanup()
 
 	c.Set("k1", "v1", 0)
@@ -63,7 +63,7 @@ func TestLRUEviction(t *testing.T) {
 }
 
 func TestExpirationAndDefaultTTL(t *testing.T) {
-	c := New(0, 0)
+	c := New[string, any](0, 0)
 	defer c.StopCleanup()
 
 	// default TTL should cap longer durations
@@ -83,7 +83,7 @@ func TestExpirationAndDefaultTTL(t *testing.T) {
 }
 
 func TestCleanupGoroutine(t *testing.T) {
-	c := New(0, 15*time.Millisecond)
+	c := New[string, any](0, 15*time.Millisecond)
 	defer c.StopCleanup()
 
 	c.Set("z", "vz", 10*time.Millisecond)
@@ -96,13 +96,13 @@ func TestCleanupGoroutine(t *testing.T) {
 	}
 }
 
-func TestEvictedFuncAndSetEvictedFunc(t *testing.T) {
+func TestEvictedFuncAndAddListener(t *testing.T) {
 	events := make(chan int, 4)
-	f := func(key, value any, event int) {
+	f := func(key string, value any, event int) {
 		events <- event
 	}
 
-	c := NewWithEvictionFunc(2, 0, f)
+	c := NewWithEvictionFunc[string, any](2, 0, f)
 	// trigger eviction
 	c.Set("a", 1, 0)
 	c.Set("b", 2, 0)
@@ -117,14 +117,27 @@ func TestEvictedFuncAndSetEvictedFunc(t *testing.T) {
 		t.Fatalf("eviction event not received")
 	}
 
-	// SetEvictedFunc should return error if called twice
-	c2 := New(0, 0)
+	// Every listener receives the events it subscribed to, until it is removed
+	c2 := New[string, any](0, 0)
 	defer c2.StopCleanup()
-	if err := c2.SetEvictedFunc(f); err != nil {
-		t.Fatalf("unexpected error setting eviction func: %v", err)
-	}
-	if err := c2.SetEvictedFunc(f); err == nil {
-		t.Fatalf("expected error when setting eviction func twice")
+	deletes := 0
+	id := c2.AddListener(common.Listener[string, any]{Func: f})
+	c2.AddListener(common.Listener[string, any]{
+		Func:   func(key string, value any, event int) { deletes++ },
+		Events: []int{common.DeleteEvent},
+	})
+	c2.Set("a", 1, 0)
+	c2.Delete("a")
+	if ev := <-events; ev != common.DeleteEvent || deletes != 1 {
+		t.Fatalf("expected DeleteEvent for both listeners got %d, deletes %d", ev, deletes)
+	}
+	if !c2.RemoveListener(id) || c2.RemoveListener(id) {
+		t.Fatalf("RemoveListener should remove the listener once")
+	}
+	c2.Set("b", 2, 0)
+	c2.Delete("b")
+	if len(events) != 0 || deletes != 2 {
+		t.Fatalf("removed listener should not receive events, deletes %d", deletes)
 	}
 
 	// Closing cache will send ClearEvent for remaining entries
@@ -140,7 +153,7 @@ func TestEvictedFuncAndSetEvictedFunc(t *testing.T) {
 }
 
 func TestNewAndLen(t *testing.T) {
-	c := New(0, 0)
+	c := New[string, any](0, 0)
 	defer c.StopCleanup()
 
 	if c == nil {
@@ -152,7 +165,7 @@ func TestNewAndLen(t *testing.T) {
 }
 
 func TestKeysAndClear(t *testing.T) {
-	c := New(0, 0)
+	c := New[string, any](0, 0)
 	defer c.StopCleanup()
 
 	c.Set("a", 1, 0)
@@ -175,7 +188,7 @@ func TestKeysAndClear(t *testing.T) {
 }
 
 func TestRemoveOldest(t *testing.T) {
-	c := New(2, 0)
+	c := New[string, any](2, 0)
 	defer c.StopCleanup()
 
 	c.Set("k1", 1, 0)
@@ -192,7 +205,7 @@ func TestRemoveOldest(t *testing.T) {
 }
 
 func TestSetUpdatesExistingEntry(t *testing.T) {
-	c := New(0, 0)
+	c := New[string, any](0, 0)
 	defer c.StopCleanup()
 
 	c.Set("u", 1, 0)
@@ -211,7 +224,7 @@ func TestSetUpdatesExistingEntry(t *testing.T) {
 }
 
 func TestKeysReflectDelete(t *testing.T) {
-	c := New(0, 0)
+	c := New[string, any](0, 0)
 	defer c.StopCleanup()
 
 	c.Set("a", 1, 0)
@@ -233,7 +246,7 @@ func TestKeysReflectDelete(t *testing.T) {
 }
 
 func TestMain(t *testing.T) {
-	cache := New(3, 5*time.Second)
+	cache := New[string, any](3, 5*time.Second)
 	defer cache.StopCleanup()
 
 	fmt.Println("Setting initial entries...")
@@ -0,0 +1,393 @@
+// random implements a random eviction cache
+package random
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/hungpdn/grule-plus/cache/common"
+)
+
+// Cache is a random eviction cache structure
+type Cache[K comparable, V any] struct {
+	maxEntries int                    // The maximum number of cache entries before an entry is evicted, zero means no limit
+	maxCost    int64                  // The maximum total cost of the cache entries before an entry is evicted, zero means no limit
+	cost       int64                  // Total cost of the cache entries
+	entries    map[K]*entry[K, V]     // Map for quick access to cache entries
+	keys       []K                    // Slice of keys for random selection
+	expiry     *common.Expiry[K]      // Expiration times of the entries with a TTL
+	mu         sync.RWMutex           // Mutex to ensure concurrent access safety
+	listeners  common.Listeners[K, V] // Listeners notified when an entry is purged from the cache
+	// cleanup
+	defaultTTL      time.Duration // default TTL for item expire
+	sliding         bool          // whether an access restarts the TTL of the entry
+	cleanupInterval time.Duration // how often to run the expired entry cleaner
+	stopChan        chan struct{} // Channel to stop cleanup goroutine
+}
+
+// entry represents an entry in the random cache
+type entry[K comparable, V any] struct {
+	key        K
+	value      V
+	cost       int64         // weight of the entry counted against maxCost
+	index      int           // position of the key in the keys slice
+	expiration int64         // Unix timestamp (nanoseconds) when the item expires, 0 means never expires
+	ttl        time.Duration // time-to-live of the entry, restarted by each access when the TTL is sliding
+}
+
+// New creates a new random eviction cache
+func New[K comparable, V any](maxEntries int, cleanupInterval time.Duration) *Cache[K, V] {
+	cache := &Cache[K, V]{
+		maxEntries:      maxEntries,
+		entries:         make(map[K]*entry[K, V]),
+		keys:            make([]K, 0),
+		expiry:          common.NewExpiry[K](),
+		cleanupInterval: cleanupInterval,
+		stopChan:        make(chan struct{}),
+	}
+	if cache.cleanupInterval > 0 {
+		go cache.startCleanup(cache.stopChan)
+	}
+	return cache
+}
+
+// Set inserts or updates the specified key-value pair with an expiration time, it costs 1
+func (c *Cache[K, V]) Set(key K, value V, duration time.Duration) {
+	c.SetWithCost(key, value, 1, duration)
+}
+
+// SetWithCost inserts or updates the specified key-value pair weighted by cost with an expiration time
+func (c *Cache[K, V]) SetWithCost(key K, value V, cost int64, duration time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ttl := duration
+	if ttl <= 0 {
+		ttl = c.defaultTTL
+	}
+	var expiration int64
+	if ttl > 0 {
+		expiration = time.Now().Add(ttl).UnixNano()
+	}
+
+	if ent, exists := c.entries[key]; exists {
+		// Update existing entry
+		ent.value = value
+		ent.expiration = expiration
+		ent.ttl = ttl
+		c.expiry.Set(key, expiration)
+		c.cost += cost - ent.cost
+		ent.cost = cost
+		for c.overCost(0) && len(c.entries) > 1 {
+			c.evictRandom(ent)
+		}
+	} else {
+		// Make room for the cost of the new entry
+		for c.overCost(cost) && len(c.entries) > 0 {
+			c.evictRandom(nil)
+		}
+
+		// Add new entry
+		ent := &entry[K, V]{
+			key:        key,
+			value:      value,
+			cost:       cost,
+			index:      len(c.keys),
+			expiration: expiration,
+			ttl:        ttl,
+		}
+		c.entries[key] = ent
+		c.keys = append(c.keys, key)
+		c.expiry.Set(key, expiration)
+		c.cost += cost
+
+		// Evict if over capacity
+		if c.maxEntries > 0 && len(c.entries) > c.maxEntries {
+			c.evictRandom(nil)
+		}
+	}
+}
+
+// Get looks up a key's value from the cache
+func (c *Cache[K, V]) Get(key K) (value V, ok bool) {
+	c.mu.RLock()
+	if !c.sliding {
+		defer c.mu.RUnlock()
+		return c.lookup(key)
+	}
+	c.mu.RUnlock()
+
+	// Restarting the TTL needs the write lock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if value, ok = c.lookup(key); ok {
+		c.slide(c.entries[key])
+	}
+	return value, ok
+}
+
+// lookup returns the value of an unexpired entry, the caller holds the lock
+func (c *Cache[K, V]) lookup(key K) (value V, ok bool) {
+	if ent, exists := c.entries[key]; exists {
+		if ent.expiration > 0 && time.Now().UnixNano() > ent.expiration {
+			return value, false
+		}
+		return ent.value, true
+	}
+	return value, false
+}
+
+// Has returns true if the key exists in the cache
+func (c *Cache[K, V]) Has(key K) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if ent, exists := c.entries[key]; exists {
+		if ent.expiration > 0 && time.Now().UnixNano() > ent.expiration {
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// Peek looks up a key's value from the cache without restarting a sliding TTL, random eviction
+// keeps no recency to update
+func (c *Cache[K, V]) Peek(key K) (value V, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.lookup(key)
+}
+
+// Delete removes the key from the cache, the eviction func receives a DeleteEvent.
+// It reports whether the key was in the cache
+func (c *Cache[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ent, ok := c.entries[key]
+	if !ok {
+		return false
+	}
+	c.removeEntry(ent, common.DeleteEvent)
+	return true
+}
+
+// Keys returns a slice of the keys in the cache
+func (c *Cache[K, V]) Keys() []K {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	keys := make([]K, 0, len(c.entries))
+	now := time.Now().UnixNano()
+
+	for _, key := range c.keys {
+		if ent, exists := c.entries[key]; exists {
+			if ent.expiration == 0 || now <= ent.expiration {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// Len returns the number of items in the cache
+func (c *Cache[K, V]) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	count := 0
+	now := time.Now().UnixNano()
+
+	for _, ent := range c.entries {
+		if ent.expiration == 0 || now <= ent.expiration {
+			count++
+		}
+	}
+	return count
+}
+
+// Clear purges all key-value pairs from the cache
+func (c *Cache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.listeners.Len() > 0 {
+		for _, ent := range c.entries {
+			c.listeners.Notify(ent.key, ent.value, common.ClearEvent)
+		}
+	}
+
+	c.entries = make(map[K]*entry[K, V])
+	c.keys = make([]K, 0)
+	c.expiry.Clear()
+	c.cost = 0
+}
+
+// Close purges all key-value pairs from the cache and stop cleanup
+func (c *Cache[K, V]) Close() {
+	c.mu.Lock()
+	c.stopCleanup()
+	c.mu.Unlock()
+
+	// Clear all entries, Clear acquires the mutex itself
+	c.Clear()
+}
+
+// stopCleanup stops the cleanup goroutine
+func (c *Cache[K, V]) stopCleanup() {
+	if c.cleanupInterval > 0 && c.stopChan != nil {
+		close(c.stopChan)
+		c.stopChan = nil
+	}
+}
+
+// StopCleanup stops the cleanup goroutine (for testing)
+func (c *Cache[K, V]) StopCleanup() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stopCleanup()
+}
+
+// AddListener registers a listener of the entries purged from the cache and returns its ID
+func (c *Cache[K, V]) AddListener(listener common.Listener[K, V]) int {
+	return c.listeners.Add(listener)
+}
+
+// RemoveListener unregisters the listener with the given ID, and reports whether it was registered
+func (c *Cache[K, V]) RemoveListener(id int) bool {
+	return c.listeners.Remove(id)
+}
+
+// SetDefaultTTL sets the default TTL for cache entries
+func (c *Cache[K, V]) SetDefaultTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.defaultTTL = ttl
+}
+
+// SetSlidingTTL sets whether an access restarts the TTL of the entry, so entries in use do not expire
+func (c *Cache[K, V]) SetSlidingTTL(sliding bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sliding = sliding
+}
+
+// SetMaxCost sets the maximum total cost of the cache entries, zero means no limit
+func (c *Cache[K, V]) SetMaxCost(maxCost int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxCost = maxCost
+	for c.overCost(0) && len(c.entries) > 1 {
+		c.evictRandom(nil)
+	}
+}
+
+// Resize changes the maximum number of entries, zero means no limit, and evicts the entries
+// over the new size. It returns the number of evicted entries
+func (c *Cache[K, V]) Resize(size int) (evicted int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := len(c.entries)
+	c.maxEntries = size
+	for size > 0 && len(c.entries) > size {
+		c.evictRandom(nil)
+	}
+	return n - len(c.entries)
+}
+
+// Cost returns the total cost of the items in the cache
+func (c *Cache[K, V]) Cost() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cost
+}
+
+// overCost reports whether adding cost to the cache exceeds maxCost
+func (c *Cache[K, V]) overCost(cost int64) bool {
+	return c.maxCost > 0 && c.cost+cost > c.maxCost
+}
+
+// evictRandom randomly evicts one entry from the cache other than keep
+func (c *Cache[K, V]) evictRandom(keep *entry[K, V]) {
+	n := len(c.keys)
+	if keep != nil {
+		n--
+	}
+	if n <= 0 {
+		return
+	}
+
+	// Pick a random key, the last key stands in for keep
+	i := rand.Intn(n)
+	if keep != nil && i == keep.index {
+		i = n
+	}
+	if ent, exists := c.entries[c.keys[i]]; exists {
+		c.removeEntry(ent, common.EvictionEvent)
+	}
+}
+
+// removeEntry removes an entry from the cache
+func (c *Cache[K, V]) removeEntry(ent *entry[K, V], event int) {
+	delete(c.entries, ent.key)
+	c.expiry.Remove(ent.key)
+	c.cost -= ent.cost
+
+	// Remove from keys slice (swap with last element for efficiency)
+	last := len(c.keys) - 1
+	if ent.index != last {
+		c.keys[ent.index] = c.keys[last]
+		c.entries[c.keys[ent.index]].index = ent.index
+	}
+	c.keys = c.keys[:last]
+
+	c.listeners.Notify(ent.key, ent.value, event)
+}
+
+// startCleanup starts the cleanup goroutine that periodically removes expired entries, until stop is closed
+func (c *Cache[K, V]) startCleanup(stop <-chan struct{}) {
+	ticker := time.NewTicker(c.cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.cleanup()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// cleanup removes expired entries from the cache, in batches so that readers are not blocked for long
+func (c *Cache[K, V]) cleanup() {
+	for {
+		c.mu.Lock()
+		keys := c.expiry.Expired(time.Now().UnixNano(), common.ExpiryBatch)
+		for _, key := range keys {
+			if ent, exists := c.entries[key]; exists {
+				c.removeEntry(ent, common.ExpirationEvent)
+			}
+		}
+		c.mu.Unlock()
+
+		if len(keys) < common.ExpiryBatch {
+			return
+		}
+	}
+}
+
+// slide restarts the TTL of an accessed entry when the TTL is sliding
+func (c *Cache[K, V]) slide(ent *entry[K, V]) {
+	if c.sliding && ent.ttl > 0 {
+		ent.expiration = time.Now().Add(ent.ttl).UnixNano()
+		c.expiry.Set(ent.key, ent.expiration)
+	}
+}
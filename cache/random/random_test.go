@@ -3,10 +3,12 @@ package random
 import (
 	"testing"
 	"time"
+
+	"github.com/hungpdn/grule-plus/cache/common"
 )
 
 func TestBasicSetGetDelete(t *testing.T) {
-	c := New(0, 0)
+	c := New[string, string](0, 0)
 	defer c.StopCleanup()
 
 	if got := c.Len(); got != 0 {
@@ -41,7 +43,7 @@ func TestBasicSetGetDelete(t *testing.T) {
 }
 
 func TestRandomEviction(t *testing.T) {
-	c := New(2, 0)
+	c := New[string, string](2, 0)
 	defer c.StopCleanup()
 
 	c.Set("k1", "v1", 0)
@@ -62,7 +64,7 @@ func TestRandomEviction(t *testing.T) {
 }
 
 func TestExpirationAndDefaultTTL(t *testing.T) {
-	c := New(0, time.Millisecond*10)
+	c := New[string, string](0, time.Millisecond*10)
 	defer c.StopCleanup()
 
 	// Set default TTL
@@ -83,20 +85,17 @@ func TestExpirationAndDefaultTTL(t *testing.T) {
 	}
 }
 
-func TestEvictedFuncAndSetEvictedFunc(t *testing.T) {
+func TestEvictedFuncAndAddListener(t *testing.T) {
 	var evictedKey any
 	var evictedValue any
 
-	c := New(1, 0)
+	c := New[string, string](1, 0)
 	defer c.StopCleanup()
 
-	err := c.SetEvictedFunc(func(key, value any, event int) {
+	c.AddListener(common.Listener[string, string]{Func: func(key, value string, event int) {
 		evictedKey = key
 		evictedValue = value
-	})
-	if err != nil {
-		t.Fatalf("SetEvictedFunc failed: %v", err)
-	}
+	}})
 
 	c.Set("k1", "v1", 0)
 	c.Set("k1", "v2", 0) // This should evict k1
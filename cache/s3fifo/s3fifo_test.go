@@ -0,0 +1,147 @@
+package s3fifo
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hungpdn/grule-plus/cache/common"
+)
+
+func TestSetGetHas(t *testing.T) {
+	c := New[string, string](10, 0)
+	defer c.Close()
+
+	c.Set("a", "va", 0)
+	c.Set("b", "vb", 0)
+
+	if v, ok := c.Get("a"); !ok || v != "va" {
+		t.Fatalf("Get a failed: %v %v", v, ok)
+	}
+	if !c.Has("b") || c.Has("c") {
+		t.Fatalf("Has returned unexpected results")
+	}
+	if c.Len() != 2 || len(c.Keys()) != 2 {
+		t.Fatalf("Len want 2 got %d", c.Len())
+	}
+
+	c.Set("a", "va2", 0)
+	if v, _ := c.Get("a"); v != "va2" {
+		t.Fatalf("Get a after update want va2 got %v", v)
+	}
+}
+
+func TestOneHitWondersAreEvictedFirst(t *testing.T) {
+	c := New[string, int](10, 0)
+	defer c.Close()
+
+	// Popular keys accessed several times move to the main queue
+	for i := 0; i < 5; i++ {
+		key := fmt.Sprintf("hot%d", i)
+		c.Set(key, i, 0)
+		c.Get(key)
+		c.Get(key)
+	}
+
+	// A scan of keys accessed once only cycles through the small queue
+	for i := 0; i < 100; i++ {
+		c.Set(fmt.Sprintf("scan%d", i), i, 0)
+	}
+
+	for i := 0; i < 5; i++ {
+		if !c.Has(fmt.Sprintf("hot%d", i)) {
+			t.Fatalf("hot%d should survive the scan", i)
+		}
+	}
+	if c.Len() != 10 {
+		t.Fatalf("Len want 10 got %d", c.Len())
+	}
+}
+
+func TestGhostReadmission(t *testing.T) {
+	c := New[string, int](10, 0)
+	defer c.Close()
+
+	c.Set("a", 1, 0)
+	for i := 0; i < 10; i++ {
+		c.Set(fmt.Sprintf("k%d", i), i, 0)
+	}
+	if c.Has("a") {
+		t.Fatalf("a should be evicted from the small queue")
+	}
+	if _, ok := c.ghosts["a"]; !ok {
+		t.Fatalf("a should be remembered in the ghost queue")
+	}
+
+	// Coming back, a goes straight to the main queue
+	c.Set("a", 1, 0)
+	ent := c.entries["a"].Value.(*entry[string, int])
+	if !ent.inMain {
+		t.Fatalf("a should be inserted into the main queue")
+	}
+	if _, ok := c.ghosts["a"]; ok {
+		t.Fatalf("a should be removed from the ghost queue")
+	}
+}
+
+func TestExpiration(t *testing.T) {
+	c := New[string, string](10, 0)
+	defer c.Close()
+
+	c.SetDefaultTTL(20 * time.Millisecond)
+	c.Set("x", "vx", 0)
+	c.Set("y", "vy", time.Hour)
+	time.Sleep(30 * time.Millisecond)
+
+	if c.Has("x") {
+		t.Fatalf("x should be expired by default TTL")
+	}
+	if _, ok := c.Get("x"); ok {
+		t.Fatalf("Get x should miss after expiration")
+	}
+	if !c.Has("y") {
+		t.Fatalf("y should not be expired")
+	}
+}
+
+func TestCleanupGoroutine(t *testing.T) {
+	c := New[string, string](10, 10*time.Millisecond)
+	defer c.Close()
+
+	events := make(chan int, 1)
+	c.AddListener(common.Listener[string, string]{Func: func(key, value string, event int) {
+		events <- event
+	}})
+	c.Set("z", "vz", 5*time.Millisecond)
+
+	select {
+	case ev := <-events:
+		if ev != common.ExpirationEvent {
+			t.Fatalf("expected ExpirationEvent got %d", ev)
+		}
+	case <-time.After(200 * time.Millisecond):
+		t.Fatalf("z should be cleaned up by goroutine")
+	}
+}
+
+func TestEvictedFunc(t *testing.T) {
+	c := New[string, int](2, 0)
+
+	events := map[int]int{}
+	c.AddListener(common.Listener[string, int]{Func: func(key string, value int, event int) {
+		events[event]++
+	}})
+
+	c.Set("a", 1, 0)
+	c.Set("b", 2, 0)
+	c.Set("c", 3, 0)
+	if events[common.EvictionEvent] != 1 || c.Len() != 2 {
+		t.Fatalf("expected one eviction, events %v len %d", events, c.Len())
+	}
+
+	c.Clear()
+	if events[common.ClearEvent] != 2 || c.Len() != 0 {
+		t.Fatalf("expected two clear events, events %v", events)
+	}
+	c.Close()
+}
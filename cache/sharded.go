@@ -0,0 +1,236 @@
+package cache
+
+import (
+	"hash/maphash"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/hungpdn/grule-plus/cache/common"
+)
+
+// shard is one independently locked segment of a sharded cache.
+type shard[K comparable, V any] struct {
+	Cache[K, V]
+	mu  sync.Mutex // protects buf
+	buf []K        // hits waiting to be recorded by the policy
+}
+
+// sharded spreads the keys over independently locked segments, each running its own policy.
+type sharded[K comparable, V any] struct {
+	shards     []*shard[K, V]
+	seed       maphash.Seed           // seed used to hash the keys to a segment
+	listeners  common.Listeners[K, V] // listeners of the entries purged from every segment
+	readBuffer int                    // number of hits buffered before they are recorded, 0 records them immediately
+	readSample int                    // record one of every readSample hits, 0 or 1 records all of them
+}
+
+// SegmentMaxCost returns the maximum cost of each of the segments of a cache with the given maximum
+// cost, which is also the largest cost of an entry the cache can hold. An entry costing more evicts
+// every other entry of its segment.
+func SegmentMaxCost(maxCost int64, shards int) int64 {
+	if shards <= 1 {
+		return maxCost
+	}
+	n := int64(shards)
+	return (maxCost + n - 1) / n
+}
+
+// newSharded creates config.Shards segments of the configured policy, splitting the size and
+// the maximum cost evenly between them.
+func newSharded[K comparable, V any](config Config[K, V]) Cache[K, V] {
+	n := config.Shards
+	segment := config
+	segment.Shards = 0
+	segment.Size = (config.Size + n - 1) / n
+	segment.MaxCost = SegmentMaxCost(config.MaxCost, n)
+	segment.EvictedFunc = nil
+
+	c := &sharded[K, V]{
+		shards:     make([]*shard[K, V], n),
+		seed:       maphash.MakeSeed(),
+		readBuffer: config.ReadBuffer,
+		readSample: config.ReadSample,
+	}
+	for i := range c.shards {
+		s := &shard[K, V]{Cache: NewCache(segment)}
+		s.AddListener(common.Listener[K, V]{Func: c.listeners.Notify})
+		if c.buffered() {
+			s.buf = make([]K, 0, max(1, c.readBuffer))
+		}
+		c.shards[i] = s
+	}
+	if config.EvictedFunc != nil {
+		c.AddListener(common.Listener[K, V]{Func: config.EvictedFunc})
+	}
+	return c
+}
+
+// buffered reports whether hits are sampled or buffered instead of recorded on every Get.
+func (c *sharded[K, V]) buffered() bool {
+	return c.readBuffer > 0 || c.readSample > 1
+}
+
+// shard returns the segment owning the key.
+func (c *sharded[K, V]) shard(key K) *shard[K, V] {
+	return c.shards[maphash.Comparable(c.seed, key)%uint64(len(c.shards))]
+}
+
+// Set inserts or updates the specified key-value pair with an expiration time
+func (c *sharded[K, V]) Set(key K, value V, duration time.Duration) {
+	c.shard(key).Set(key, value, duration)
+}
+
+// SetWithCost inserts or updates the specified key-value pair weighted by cost with an expiration time
+func (c *sharded[K, V]) SetWithCost(key K, value V, cost int64, duration time.Duration) {
+	c.shard(key).SetWithCost(key, value, cost, duration)
+}
+
+// Get looks up a key's value from the cache. With sampled or buffered recency updates a hit
+// only takes the read lock of its segment, and the access is recorded by the policy later.
+func (c *sharded[K, V]) Get(key K) (value V, ok bool) {
+	s := c.shard(key)
+	if !c.buffered() {
+		return s.Get(key)
+	}
+
+	value, ok = s.Peek(key)
+	if !ok {
+		// Let the policy handle the miss, e.g. remove an expired entry
+		return s.Get(key)
+	}
+	c.recordHit(s, key)
+	return value, true
+}
+
+// recordHit records a sampled hit in the buffer of the segment, and replays the buffer against
+// the policy when it is full.
+func (c *sharded[K, V]) recordHit(s *shard[K, V], key K) {
+	if c.readSample > 1 && rand.IntN(c.readSample) != 0 {
+		return
+	}
+	if c.readBuffer <= 0 {
+		s.Get(key)
+		return
+	}
+
+	s.mu.Lock()
+	s.buf = append(s.buf, key)
+	if len(s.buf) < c.readBuffer {
+		s.mu.Unlock()
+		return
+	}
+	hits := s.buf
+	s.buf = make([]K, 0, c.readBuffer)
+	s.mu.Unlock()
+
+	for _, hit := range hits {
+		s.Get(hit)
+	}
+}
+
+// Peek looks up a key's value without updating its recency or frequency
+func (c *sharded[K, V]) Peek(key K) (value V, ok bool) {
+	return c.shard(key).Peek(key)
+}
+
+// Delete removes the key from the cache and reports whether it was present
+func (c *sharded[K, V]) Delete(key K) bool {
+	return c.shard(key).Delete(key)
+}
+
+// Has returns true if the key exists in the cache
+func (c *sharded[K, V]) Has(key K) bool {
+	return c.shard(key).Has(key)
+}
+
+// Keys returns a slice of the keys in the cache
+func (c *sharded[K, V]) Keys() []K {
+	keys := make([]K, 0)
+	for _, s := range c.shards {
+		keys = append(keys, s.Keys()...)
+	}
+	return keys
+}
+
+// Len returns the number of items in the cache
+func (c *sharded[K, V]) Len() int {
+	n := 0
+	for _, s := range c.shards {
+		n += s.Len()
+	}
+	return n
+}
+
+// Clear purges all key-value pairs from the cache
+func (c *sharded[K, V]) Clear() {
+	for _, s := range c.shards {
+		s.Clear()
+		s.dropHits()
+	}
+}
+
+// Close purges all key-value pairs from the cache and stop cleanup
+func (c *sharded[K, V]) Close() {
+	for _, s := range c.shards {
+		s.Close()
+		s.dropHits()
+	}
+}
+
+// AddListener registers a listener of the entries purged from every segment and returns its ID
+func (c *sharded[K, V]) AddListener(listener common.Listener[K, V]) int {
+	return c.listeners.Add(listener)
+}
+
+// RemoveListener unregisters the listener with the given ID, and reports whether it was registered
+func (c *sharded[K, V]) RemoveListener(id int) bool {
+	return c.listeners.Remove(id)
+}
+
+// SetDefaultTTL sets the default TTL for cache entries
+func (c *sharded[K, V]) SetDefaultTTL(ttl time.Duration) {
+	for _, s := range c.shards {
+		s.SetDefaultTTL(ttl)
+	}
+}
+
+// SetSlidingTTL sets whether an access restarts the TTL of the entry in every segment
+func (c *sharded[K, V]) SetSlidingTTL(sliding bool) {
+	for _, s := range c.shards {
+		s.SetSlidingTTL(sliding)
+	}
+}
+
+// SetMaxCost sets the maximum total cost of the cache entries, split evenly between the segments
+func (c *sharded[K, V]) SetMaxCost(maxCost int64) {
+	for _, s := range c.shards {
+		s.SetMaxCost(SegmentMaxCost(maxCost, len(c.shards)))
+	}
+}
+
+// Resize changes the maximum number of entries, split evenly between the segments, and returns
+// the number of evicted entries
+func (c *sharded[K, V]) Resize(size int) (evicted int) {
+	n := len(c.shards)
+	for _, s := range c.shards {
+		evicted += s.Resize((size + n - 1) / n)
+	}
+	return evicted
+}
+
+// Cost returns the total cost of the items in the cache
+func (c *sharded[K, V]) Cost() int64 {
+	var cost int64
+	for _, s := range c.shards {
+		cost += s.Cost()
+	}
+	return cost
+}
+
+// dropHits discards the buffered hits of a segment
+func (s *shard[K, V]) dropHits() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buf = s.buf[:0]
+}
@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/hungpdn/grule-plus/cache/common"
+)
+
+func TestSharded(t *testing.T) {
+	for _, typ := range []int{LRU, LFU, ARC, TWOQ, RANDOM, TINYLFU, SIEVE, S3FIFO} {
+		evicted := 0
+		c := NewCache(Config[string, int]{
+			Type:   typ,
+			Size:   40,
+			Shards: 4,
+			EvictedFunc: func(key string, value int, event int) {
+				if event == common.EvictionEvent {
+					evicted++
+				}
+			},
+		})
+		if _, ok := c.(*sharded[string, int]); !ok {
+			t.Fatalf("type %d: Shards 4 should create a sharded cache", typ)
+		}
+
+		for i := 0; i < 100; i++ {
+			c.Set(fmt.Sprint(i), i, 0)
+		}
+		if c.Len() > 40 || c.Len()+evicted != 100 || len(c.Keys()) != c.Len() {
+			t.Fatalf("type %d: want at most 40 entries, len %d evicted %d", typ, c.Len(), evicted)
+		}
+		for _, key := range c.Keys() {
+			if v, ok := c.Get(key); !ok || fmt.Sprint(v) != key {
+				t.Fatalf("type %d: Get %s want %s got %v %v", typ, key, key, v, ok)
+			}
+		}
+
+		key := c.Keys()[0]
+		if _, ok := c.Peek(key); !ok || !c.Delete(key) || c.Has(key) {
+			t.Fatalf("type %d: Delete %s should remove it from its segment", typ, key)
+		}
+		if n := c.Len(); c.Resize(20) != n-c.Len() || c.Len() > 20 {
+			t.Fatalf("type %d: Resize 20 want at most 20 entries, len %d", typ, c.Len())
+		}
+
+		c.Clear()
+		if c.Len() != 0 || c.Cost() != 0 {
+			t.Fatalf("type %d: Clear should empty every segment, len %d", typ, c.Len())
+		}
+		c.Close()
+	}
+}
+
+func TestShardedMaxCost(t *testing.T) {
+	c := NewCache(Config[int, int]{Type: LRU, Shards: 4, MaxCost: 400})
+	defer c.Close()
+
+	for i := 0; i < 100; i++ {
+		c.SetWithCost(i, i, 10, 0)
+	}
+	if c.Cost() > 400 || c.Cost() != int64(10*c.Len()) {
+		t.Fatalf("want each segment within 100, cost %d len %d", c.Cost(), c.Len())
+	}
+
+	for _, tc := range []struct {
+		maxCost int64
+		shards  int
+		want    int64
+	}{{400, 4, 100}, {401, 4, 101}, {400, 1, 400}, {400, 0, 400}, {0, 4, 0}} {
+		if got := SegmentMaxCost(tc.maxCost, tc.shards); got != tc.want {
+			t.Fatalf("SegmentMaxCost(%d, %d) want %d got %d", tc.maxCost, tc.shards, tc.want, got)
+		}
+	}
+}
+
+// sameShard returns n keys owned by the same segment
+func sameShard(c *sharded[string, int], n int) []string {
+	keys := make([]string, 0, n)
+	want := c.shard("k0")
+	for i := 0; len(keys) < n; i++ {
+		if key := fmt.Sprint("k", i); c.shard(key) == want {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func TestShardedReadBuffer(t *testing.T) {
+	c := NewCache(Config[string, int]{Type: LRU, Size: 4, Shards: 2, ReadBuffer: 3}).(*sharded[string, int])
+	defer c.Close()
+	keys := sameShard(c, 3)
+
+	c.Set(keys[0], 0, 0)
+	c.Set(keys[1], 1, 0)
+
+	// The hit is buffered, so keys[0] is still the least recently used entry
+	if _, ok := c.Get(keys[0]); !ok {
+		t.Fatalf("Get %s should hit", keys[0])
+	}
+	c.Set(keys[2], 2, 0)
+	if c.Has(keys[0]) {
+		t.Fatalf("%s should be evicted, its hit is not recorded yet", keys[0])
+	}
+
+	// A full buffer is replayed against the policy, keys[1] becomes the most recently used
+	c.Get(keys[2])
+	c.Get(keys[1])
+	c.Set(keys[0], 0, 0)
+	if c.Has(keys[2]) || !c.Has(keys[1]) {
+		t.Fatalf("%s should be kept after the buffered hits are recorded", keys[1])
+	}
+}
+
+func TestShardedReadSample(t *testing.T) {
+	c := NewCache(Config[string, int]{Type: LRU, Size: 4, Shards: 2, ReadSample: 1000}).(*sharded[string, int])
+	defer c.Close()
+
+	c.Set("a", 1, 0)
+	for i := 0; i < 100; i++ {
+		if v, ok := c.Get("a"); !ok || v != 1 {
+			t.Fatalf("sampled Get want 1 got %v %v", v, ok)
+		}
+	}
+	if _, ok := c.Get("missing"); ok {
+		t.Fatalf("Get missing should miss")
+	}
+}
+
+func TestShardedConcurrent(t *testing.T) {
+	c := NewCache(Config[int, int]{Type: LRU, Size: 100, Shards: 8, ReadBuffer: 16})
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				key := (g*31 + i) % 200
+				if _, ok := c.Get(key); !ok {
+					c.Set(key, key, 0)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if c.Len() > 100+8 {
+		t.Fatalf("want about 100 entries, len %d", c.Len())
+	}
+}
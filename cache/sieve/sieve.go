@@ -0,0 +1,408 @@
+// sieve implements a SIEVE cache.
+//
+// Entries are kept in insertion order and a hit only marks the entry as visited. On eviction
+// a hand sweeps from the oldest entry towards the newest, clearing the visited marks, and
+// evicts the first entry that was not visited since the hand last passed it.
+package sieve
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hungpdn/grule-plus/cache/common"
+)
+
+// Cache is a SIEVE cache structure
+type Cache[K comparable, V any] struct {
+	maxEntries int                    // The maximum number of cache entries before an entry is evicted, zero means no limit
+	maxCost    int64                  // The maximum total cost of the cache entries before an entry is evicted, zero means no limit
+	cost       int64                  // Total cost of the cache entries
+	entries    map[K]*list.Element    // Map for quick access to cache entries
+	expiry     *common.Expiry[K]      // Expiration times of the entries with a TTL
+	queue      *list.List             // FIFO queue, newest entries at the front
+	hand       *list.Element          // Next eviction candidate, nil means the back of the queue
+	mu         sync.RWMutex           // Mutex to ensure concurrent access safety
+	listeners  common.Listeners[K, V] // Listeners notified when an entry is purged from the cache
+	// cleanup
+	defaultTTL      time.Duration // default TTL for item expire
+	sliding         bool          // whether an access restarts the TTL of the entry
+	cleanupInterval time.Duration // how often to run the expired entry cleaner
+	stopChan        chan struct{} // Channel to stop cleanup goroutine
+	closed          bool          // Flag to indicate if cache is closed
+}
+
+// entry represents an entry in the SIEVE cache
+type entry[K comparable, V any] struct {
+	key        K
+	value      V
+	visited    atomic.Bool   // whether the entry was accessed since the hand last passed it, set by hits under the read lock
+	cost       int64         // weight of the entry counted against maxCost
+	expiration int64         // Unix timestamp (nanoseconds) when the item expires, 0 means never expires
+	ttl        time.Duration // time-to-live of the entry, restarted by each access when the TTL is sliding
+}
+
+// New creates a new SIEVE cache
+// maxEntries: the maximum number of cache entries before an entry is evicted, zero means no limit
+// cleanupInterval: how often to run the expired entry cleaner
+func New[K comparable, V any](maxEntries int, cleanupInterval time.Duration) *Cache[K, V] {
+	cache := &Cache[K, V]{
+		maxEntries:      maxEntries,
+		entries:         make(map[K]*list.Element),
+		expiry:          common.NewExpiry[K](),
+		queue:           list.New(),
+		cleanupInterval: cleanupInterval,
+		stopChan:        make(chan struct{}),
+	}
+	if cache.cleanupInterval > 0 {
+		go cache.startCleanup()
+	}
+	return cache
+}
+
+// Set inserts or updates the specified key-value pair with an expiration time, it costs 1
+func (c *Cache[K, V]) Set(key K, value V, duration time.Duration) {
+	c.SetWithCost(key, value, 1, duration)
+}
+
+// SetWithCost inserts or updates the specified key-value pair weighted by cost with an expiration time
+func (c *Cache[K, V]) SetWithCost(key K, value V, cost int64, duration time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ttl := duration
+	if ttl <= 0 {
+		ttl = c.defaultTTL
+	}
+	var expiration int64
+	if ttl > 0 {
+		expiration = time.Now().Add(ttl).UnixNano()
+	}
+
+	if ele, ok := c.entries[key]; ok {
+		// Update existing entry, it stays at its position in the queue
+		ent := ele.Value.(*entry[K, V])
+		ent.value = value
+		ent.expiration = expiration
+		ent.ttl = ttl
+		c.expiry.Set(key, expiration)
+		ent.visited.Store(true)
+		c.cost += cost - ent.cost
+		ent.cost = cost
+		for c.overCost(0) && len(c.entries) > 1 {
+			c.evict(ele)
+		}
+		return
+	}
+
+	if c.maxEntries > 0 && c.queue.Len() >= c.maxEntries {
+		c.evict(nil)
+	}
+	for c.overCost(cost) && len(c.entries) > 0 {
+		c.evict(nil)
+	}
+
+	ent := &entry[K, V]{key: key, value: value, cost: cost, expiration: expiration, ttl: ttl}
+	c.entries[key] = c.queue.PushFront(ent)
+	c.expiry.Set(key, expiration)
+	c.cost += cost
+}
+
+// Get looks up a key's value from the cache. A hit only marks the entry as visited and is served
+// under the read lock, unless it restarts a sliding TTL.
+func (c *Cache[K, V]) Get(key K) (value V, ok bool) {
+	c.mu.RLock()
+	ele, exists := c.entries[key]
+	if !exists {
+		c.mu.RUnlock()
+		return value, false
+	}
+	ent := ele.Value.(*entry[K, V])
+	if (ent.expiration == 0 || time.Now().UnixNano() <= ent.expiration) && !(c.sliding && ent.ttl > 0) {
+		ent.visited.Store(true)
+		value = ent.value
+		c.mu.RUnlock()
+		return value, true
+	}
+	c.mu.RUnlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ele, exists = c.entries[key]
+	if !exists {
+		return value, false
+	}
+	ent = ele.Value.(*entry[K, V])
+	if ent.expiration > 0 && time.Now().UnixNano() > ent.expiration {
+		c.removeElement(ele, common.ExpirationEvent)
+		return value, false
+	}
+	ent.visited.Store(true)
+	c.slide(ent)
+	return ent.value, true
+}
+
+// Has returns true if the key exists in the cache
+func (c *Cache[K, V]) Has(key K) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ele, ok := c.entries[key]
+	if !ok {
+		return false
+	}
+	ent := ele.Value.(*entry[K, V])
+	if ent.expiration > 0 && time.Now().UnixNano() > ent.expiration {
+		return false
+	}
+	return true
+}
+
+// Peek looks up a key's value without updating its recency or frequency
+func (c *Cache[K, V]) Peek(key K) (value V, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ele, exists := c.entries[key]
+	if !exists {
+		return value, false
+	}
+	ent := ele.Value.(*entry[K, V])
+	if ent.expiration > 0 && time.Now().UnixNano() > ent.expiration {
+		return value, false
+	}
+	return ent.value, true
+}
+
+// Delete removes the key from the cache, the eviction func receives a DeleteEvent.
+// It reports whether the key was in the cache
+func (c *Cache[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ele, ok := c.entries[key]
+	if !ok {
+		return false
+	}
+	c.removeElement(ele, common.DeleteEvent)
+	return true
+}
+
+// Keys returns a slice of the keys in the cache
+func (c *Cache[K, V]) Keys() []K {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	keys := make([]K, 0, len(c.entries))
+	now := time.Now().UnixNano()
+
+	for key, ele := range c.entries {
+		ent := ele.Value.(*entry[K, V])
+		if ent.expiration == 0 || now <= ent.expiration {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Len returns the number of items in the cache
+func (c *Cache[K, V]) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	count := 0
+	now := time.Now().UnixNano()
+
+	for _, ele := range c.entries {
+		ent := ele.Value.(*entry[K, V])
+		if ent.expiration == 0 || now <= ent.expiration {
+			count++
+		}
+	}
+	return count
+}
+
+// Clear purges all key-value pairs from the cache
+func (c *Cache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.clear()
+}
+
+// Close purges all key-value pairs from the cache and stop cleanup
+func (c *Cache[K, V]) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed {
+		close(c.stopChan)
+		c.closed = true
+	}
+	c.clear()
+}
+
+// AddListener registers a listener of the entries purged from the cache and returns its ID
+func (c *Cache[K, V]) AddListener(listener common.Listener[K, V]) int {
+	return c.listeners.Add(listener)
+}
+
+// RemoveListener unregisters the listener with the given ID, and reports whether it was registered
+func (c *Cache[K, V]) RemoveListener(id int) bool {
+	return c.listeners.Remove(id)
+}
+
+// SetMaxCost sets the maximum total cost of the cache entries, zero means no limit
+func (c *Cache[K, V]) SetMaxCost(maxCost int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxCost = maxCost
+	for c.overCost(0) && len(c.entries) > 1 {
+		c.evict(nil)
+	}
+}
+
+// Resize changes the maximum number of entries, zero means no limit, and evicts the entries
+// over the new size. It returns the number of evicted entries
+func (c *Cache[K, V]) Resize(size int) (evicted int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := len(c.entries)
+	c.maxEntries = size
+	for size > 0 && len(c.entries) > size {
+		c.evict(nil)
+	}
+	return n - len(c.entries)
+}
+
+// Cost returns the total cost of the items in the cache
+func (c *Cache[K, V]) Cost() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cost
+}
+
+// SetDefaultTTL sets the default TTL for cache entries
+func (c *Cache[K, V]) SetDefaultTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.defaultTTL = ttl
+}
+
+// SetSlidingTTL sets whether an access restarts the TTL of the entry, so entries in use do not expire
+func (c *Cache[K, V]) SetSlidingTTL(sliding bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sliding = sliding
+}
+
+// clear purges all key-value pairs, the caller must hold the mutex
+func (c *Cache[K, V]) clear() {
+	if c.listeners.Len() > 0 {
+		for key, ele := range c.entries {
+			c.listeners.Notify(key, ele.Value.(*entry[K, V]).value, common.ClearEvent)
+		}
+	}
+
+	c.entries = make(map[K]*list.Element)
+	c.expiry.Clear()
+	c.cost = 0
+	c.queue.Init()
+	c.hand = nil
+}
+
+// evict moves the hand from the oldest entry towards the newest, clearing the visited
+// marks, and evicts the first entry that was not visited other than keep
+func (c *Cache[K, V]) evict(keep *list.Element) {
+	ele := c.hand
+	if ele == nil {
+		ele = c.queue.Back()
+	}
+	for ele != nil {
+		ent := ele.Value.(*entry[K, V])
+		if ele != keep && !ent.visited.Load() {
+			break
+		}
+		ent.visited.Store(false)
+		ele = ele.Prev()
+		if ele == nil {
+			ele = c.queue.Back()
+		}
+	}
+	if ele == nil {
+		return
+	}
+
+	c.hand = ele.Prev()
+	c.removeElement(ele, common.EvictionEvent)
+}
+
+// overCost reports whether adding cost to the cache exceeds maxCost
+func (c *Cache[K, V]) overCost(cost int64) bool {
+	return c.maxCost > 0 && c.cost+cost > c.maxCost
+}
+
+// removeElement removes an element from the cache
+func (c *Cache[K, V]) removeElement(ele *list.Element, event int) {
+	if c.hand == ele {
+		c.hand = ele.Prev()
+	}
+
+	ent := ele.Value.(*entry[K, V])
+	delete(c.entries, ent.key)
+	c.cost -= ent.cost
+	c.expiry.Remove(ent.key)
+	c.queue.Remove(ele)
+
+	c.listeners.Notify(ent.key, ent.value, event)
+}
+
+// startCleanup starts the cleanup goroutine
+func (c *Cache[K, V]) startCleanup() {
+	ticker := time.NewTicker(c.cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.cleanup()
+		case <-c.stopChan:
+			return
+		}
+	}
+}
+
+// cleanup removes expired entries, in batches so that readers are not blocked for long
+func (c *Cache[K, V]) cleanup() {
+	for {
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+			return
+		}
+		keys := c.expiry.Expired(time.Now().UnixNano(), common.ExpiryBatch)
+		for _, key := range keys {
+			if ele, ok := c.entries[key]; ok {
+				c.removeElement(ele, common.ExpirationEvent)
+			}
+		}
+		c.mu.Unlock()
+
+		if len(keys) < common.ExpiryBatch {
+			return
+		}
+	}
+}
+
+// slide restarts the TTL of an accessed entry when the TTL is sliding
+func (c *Cache[K, V]) slide(ent *entry[K, V]) {
+	if c.sliding && ent.ttl > 0 {
+		ent.expiration = time.Now().Add(ent.ttl).UnixNano()
+		c.expiry.Set(ent.key, ent.expiration)
+	}
+}
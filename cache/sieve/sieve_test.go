@@ -0,0 +1,176 @@
+package sieve
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hungpdn/grule-plus/cache/common"
+)
+
+func TestSetGetHas(t *testing.T) {
+	c := New[string, string](10, 0)
+	defer c.Close()
+
+	c.Set("a", "va", 0)
+	c.Set("b", "vb", 0)
+
+	if v, ok := c.Get("a"); !ok || v != "va" {
+		t.Fatalf("Get a failed: %v %v", v, ok)
+	}
+	if !c.Has("b") || c.Has("c") {
+		t.Fatalf("Has returned unexpected results")
+	}
+	if c.Len() != 2 || len(c.Keys()) != 2 {
+		t.Fatalf("Len want 2 got %d", c.Len())
+	}
+
+	c.Set("a", "va2", 0)
+	if v, _ := c.Get("a"); v != "va2" {
+		t.Fatalf("Get a after update want va2 got %v", v)
+	}
+}
+
+func TestEvictionKeepsVisitedEntries(t *testing.T) {
+	c := New[string, int](3, 0)
+	defer c.Close()
+
+	c.Set("a", 1, 0)
+	c.Set("b", 2, 0)
+	c.Set("c", 3, 0)
+	c.Get("a")
+
+	// a is the oldest but was visited, so the hand skips it and evicts b
+	c.Set("d", 4, 0)
+	if c.Has("b") {
+		t.Fatalf("b should be evicted")
+	}
+	if !c.Has("a") || !c.Has("c") || !c.Has("d") {
+		t.Fatalf("expected a, c and d present")
+	}
+
+	// The hand continues from c, which was not visited
+	c.Set("e", 5, 0)
+	if c.Has("c") || !c.Has("a") {
+		t.Fatalf("c should be evicted and a kept")
+	}
+
+	// Every entry visited: the hand clears the marks, wraps around and evicts
+	// the first entry it passed, d
+	c.Get("a")
+	c.Get("d")
+	c.Get("e")
+	c.Set("f", 6, 0)
+	if c.Has("d") || !c.Has("a") || !c.Has("e") || c.Len() != 3 {
+		t.Fatalf("d should be evicted after a full sweep, len %d", c.Len())
+	}
+}
+
+func TestExpiration(t *testing.T) {
+	c := New[string, string](10, 0)
+	defer c.Close()
+
+	c.SetDefaultTTL(20 * time.Millisecond)
+	c.Set("x", "vx", 0)
+	c.Set("y", "vy", time.Hour)
+	time.Sleep(30 * time.Millisecond)
+
+	if c.Has("x") {
+		t.Fatalf("x should be expired by default TTL")
+	}
+	if _, ok := c.Get("x"); ok {
+		t.Fatalf("Get x should miss after expiration")
+	}
+	if !c.Has("y") {
+		t.Fatalf("y should not be expired")
+	}
+}
+
+func TestCleanupGoroutine(t *testing.T) {
+	c := New[string, string](10, 10*time.Millisecond)
+	defer c.Close()
+
+	events := make(chan int, 1)
+	c.AddListener(common.Listener[string, string]{Func: func(key, value string, event int) {
+		events <- event
+	}})
+	c.Set("z", "vz", 5*time.Millisecond)
+
+	select {
+	case ev := <-events:
+		if ev != common.ExpirationEvent {
+			t.Fatalf("expected ExpirationEvent got %d", ev)
+		}
+	case <-time.After(200 * time.Millisecond):
+		t.Fatalf("z should be cleaned up by goroutine")
+	}
+}
+
+func TestEvictedFunc(t *testing.T) {
+	c := New[string, int](2, 0)
+
+	events := map[int]int{}
+	c.AddListener(common.Listener[string, int]{Func: func(key string, value int, event int) {
+		events[event]++
+	}})
+
+	c.Set("a", 1, 0)
+	c.Set("b", 2, 0)
+	c.Set("c", 3, 0)
+	if events[common.EvictionEvent] != 1 || c.Len() != 2 {
+		t.Fatalf("expected one eviction, events %v len %d", events, c.Len())
+	}
+
+	c.Clear()
+	if events[common.ClearEvent] != 2 || c.Len() != 0 {
+		t.Fatalf("expected two clear events, events %v", events)
+	}
+	c.Close()
+}
+
+func TestConcurrentHits(t *testing.T) {
+	c := New[int, int](100, 0)
+	defer c.Close()
+	for i := 0; i < 100; i++ {
+		c.Set(i, i, 0)
+	}
+
+	// Hits mark the entries under the read lock while writers move the hand
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				if g%4 == 0 {
+					c.Set(100+g*1000+i, i, 0)
+					continue
+				}
+				if v, ok := c.Get(i % 100); ok && v != i%100 {
+					t.Errorf("Get %d want %d got %d", i%100, i%100, v)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	if c.Len() != 100 {
+		t.Fatalf("Len want 100 got %d", c.Len())
+	}
+}
+
+func BenchmarkGetParallel(b *testing.B) {
+	c := New[int, int](1000, 0)
+	defer c.Close()
+	for i := 0; i < 1000; i++ {
+		c.Set(i, i, 0)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			c.Get(i % 1000)
+			i++
+		}
+	})
+}
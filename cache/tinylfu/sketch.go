@@ -0,0 +1,151 @@
+package tinylfu
+
+// sketchDepth is the number of rows of the count-min sketch
+const sketchDepth = 4
+
+// sketchMaxCount is the saturation value of the 4-bit counters
+const sketchMaxCount = 15
+
+// sketch is a count-min sketch of 4-bit counters estimating the access frequency of keys,
+// fronted by a doorkeeper so that keys seen only once do not pollute the counters
+type sketch struct {
+	counters   [sketchDepth][]uint8 // 4-bit counters, two per byte
+	mask       uint64               // width of a row minus one, the width is a power of two
+	doorkeeper []uint64             // bloom filter of the keys seen since the last reset
+	dkMask     uint64               // number of doorkeeper bits minus one
+	additions  int                  // number of increments since the last reset
+	sampleSize int                  // number of increments after which all counters are halved
+}
+
+// newSketch creates a sketch sized for the given number of entries
+func newSketch(maxEntries int) *sketch {
+	maxEntries = max(maxEntries, 16)
+	width := nextPowerOfTwo(8 * maxEntries)
+	s := &sketch{
+		mask:       uint64(width - 1),
+		doorkeeper: make([]uint64, width/64),
+		dkMask:     uint64(width - 1),
+		sampleSize: 10 * maxEntries,
+	}
+	for i := range s.counters {
+		s.counters[i] = make([]uint8, width/2)
+	}
+	return s
+}
+
+// resize returns a sketch sized for the given number of entries which keeps the frequencies of s.
+// The rows are folded into narrower rows by adding the counters and the doorkeeper bits sharing an
+// index, or repeated over wider rows, so that no key loses its estimate.
+func (s *sketch) resize(maxEntries int) *sketch {
+	r := newSketch(maxEntries)
+	for i := range r.counters {
+		for idx := uint64(0); idx <= max(s.mask, r.mask); idx++ {
+			count := r.counter(i, idx&r.mask) + s.counter(i, idx&s.mask)
+			r.setCounter(i, idx&r.mask, min(count, sketchMaxCount))
+		}
+	}
+	for bit := uint64(0); bit <= max(s.dkMask, r.dkMask); bit++ {
+		if old := bit & s.dkMask; s.doorkeeper[old/64]&(1<<(old%64)) != 0 {
+			r.doorkeeper[(bit&r.dkMask)/64] |= 1 << ((bit & r.dkMask) % 64)
+		}
+	}
+	// the sketch keeps its progress towards the next aging reset
+	r.additions = s.additions * r.sampleSize / s.sampleSize
+	return r
+}
+
+// increment records an access of the key with the given hash
+func (s *sketch) increment(hash uint64) {
+	if !s.admitDoorkeeper(hash) {
+		s.additions++
+		return
+	}
+
+	for i := range s.counters {
+		idx := s.index(hash, i)
+		if s.counter(i, idx) < sketchMaxCount {
+			s.counters[i][idx/2] += 1 << ((idx & 1) * 4)
+		}
+	}
+
+	s.additions++
+	if s.additions >= s.sampleSize {
+		s.reset()
+	}
+}
+
+// estimate returns the estimated access frequency of the key with the given hash
+func (s *sketch) estimate(hash uint64) int {
+	freq := uint8(sketchMaxCount)
+	for i := range s.counters {
+		freq = min(freq, s.counter(i, s.index(hash, i)))
+	}
+	if s.inDoorkeeper(hash) {
+		freq++
+	}
+	return int(freq)
+}
+
+// reset halves all counters and clears the doorkeeper, aging the frequencies
+func (s *sketch) reset() {
+	for i := range s.counters {
+		for j := range s.counters[i] {
+			s.counters[i][j] = (s.counters[i][j] >> 1) & 0x77
+		}
+	}
+	clear(s.doorkeeper)
+	s.additions /= 2
+}
+
+// counter returns the 4-bit counter at idx of the given row
+func (s *sketch) counter(row int, idx uint64) uint8 {
+	return (s.counters[row][idx/2] >> ((idx & 1) * 4)) & 0x0f
+}
+
+// setCounter sets the counter at idx of the given row
+func (s *sketch) setCounter(row int, idx uint64, count uint8) {
+	shift := (idx & 1) * 4
+	s.counters[row][idx/2] = s.counters[row][idx/2]&^(0x0f<<shift) | count<<shift
+}
+
+// index returns the counter index of the hash in the given row
+func (s *sketch) index(hash uint64, row int) uint64 {
+	h := hash + uint64(row)*(hash>>32|1)*0x9e3779b97f4a7c15
+	return (h ^ h>>29) & s.mask
+}
+
+// admitDoorkeeper adds the hash to the doorkeeper and reports whether it was already present
+func (s *sketch) admitDoorkeeper(hash uint64) bool {
+	present := true
+	for _, bit := range s.doorkeeperBits(hash) {
+		if s.doorkeeper[bit/64]&(1<<(bit%64)) == 0 {
+			present = false
+			s.doorkeeper[bit/64] |= 1 << (bit % 64)
+		}
+	}
+	return present
+}
+
+// inDoorkeeper reports whether the hash is in the doorkeeper
+func (s *sketch) inDoorkeeper(hash uint64) bool {
+	for _, bit := range s.doorkeeperBits(hash) {
+		if s.doorkeeper[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// doorkeeperBits returns the bloom filter bits of the hash
+func (s *sketch) doorkeeperBits(hash uint64) [2]uint64 {
+	return [2]uint64{hash & s.dkMask, (hash >> 32) & s.dkMask}
+}
+
+// nextPowerOfTwo returns the smallest power of two greater than or equal to n
+func nextPowerOfTwo(n int) int {
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
@@ -0,0 +1,498 @@
+// tinylfu implements a Window-TinyLFU cache.
+//
+// New entries enter a small LRU window. Entries leaving the window compete with the
+// eviction victim of the main segmented LRU (probation and protected), and are only
+// admitted when their estimated access frequency is higher. Frequencies are estimated
+// by a count-min sketch fronted by a doorkeeper bloom filter, and are aged by halving
+// all counters periodically, so the cache resists both scans and stale popular keys.
+package tinylfu
+
+import (
+	"container/list"
+	"hash/maphash"
+	"sync"
+	"time"
+
+	"github.com/hungpdn/grule-plus/cache/common"
+)
+
+// segment identifies the list an entry belongs to
+type segment int
+
+const (
+	windowSegment segment = iota
+	probationSegment
+	protectedSegment
+)
+
+// Cache is a W-TinyLFU cache structure
+type Cache[K comparable, V any] struct {
+	maxEntries   int                    // The maximum number of cache entries before an entry is evicted, zero means no limit
+	windowCap    int                    // Maximum size of the window LRU, 1% of maxEntries
+	protectedCap int                    // Maximum size of the protected segment, 80% of the main segments
+	maxCost      int64                  // The maximum total cost of the cache entries before an entry is evicted, zero means no limit
+	cost         int64                  // Total cost of the cache entries
+	entries      map[K]*list.Element    // Map for quick access to cache entries
+	expiry       *common.Expiry[K]      // Expiration times of the entries with a TTL
+	window       *list.List             // Window: LRU of the newly added entries
+	probation    *list.List             // Probation: admitted entries accessed once in the main segments
+	protected    *list.List             // Protected: entries accessed again while in probation
+	sketch       *sketch                // Frequency estimator used by the admission filter
+	seed         maphash.Seed           // Seed used to hash the keys
+	mu           sync.RWMutex           // Mutex to ensure concurrent access safety
+	listeners    common.Listeners[K, V] // Listeners notified when an entry is purged from the cache
+	// cleanup
+	defaultTTL      time.Duration // default TTL for item expire
+	sliding         bool          // whether an access restarts the TTL of the entry
+	cleanupInterval time.Duration // how often to run the expired entry cleaner
+	stopChan        chan struct{} // Channel to stop cleanup goroutine
+	closed          bool          // Flag to indicate if cache is closed
+}
+
+// entry represents an entry in the W-TinyLFU cache
+type entry[K comparable, V any] struct {
+	key        K
+	value      V
+	segment    segment
+	cost       int64         // weight of the entry counted against maxCost
+	expiration int64         // Unix timestamp (nanoseconds) when the item expires, 0 means never expires
+	ttl        time.Duration // time-to-live of the entry, restarted by each access when the TTL is sliding
+}
+
+// New creates a new W-TinyLFU cache
+// maxEntries: the maximum number of cache entries before an entry is evicted, zero means no limit
+// cleanupInterval: how often to run the expired entry cleaner
+func New[K comparable, V any](maxEntries int, cleanupInterval time.Duration) *Cache[K, V] {
+	windowCap := max(1, maxEntries/100)
+	cache := &Cache[K, V]{
+		maxEntries:      maxEntries,
+		windowCap:       windowCap,
+		protectedCap:    (maxEntries - windowCap) * 80 / 100,
+		entries:         make(map[K]*list.Element),
+		expiry:          common.NewExpiry[K](),
+		window:          list.New(),
+		probation:       list.New(),
+		protected:       list.New(),
+		sketch:          newSketch(maxEntries),
+		seed:            maphash.MakeSeed(),
+		cleanupInterval: cleanupInterval,
+		stopChan:        make(chan struct{}),
+	}
+	if cache.cleanupInterval > 0 {
+		go cache.startCleanup()
+	}
+	return cache
+}
+
+// Set inserts or updates the specified key-value pair with an expiration time, it costs 1
+func (c *Cache[K, V]) Set(key K, value V, duration time.Duration) {
+	c.SetWithCost(key, value, 1, duration)
+}
+
+// SetWithCost inserts or updates the specified key-value pair weighted by cost with an expiration time
+func (c *Cache[K, V]) SetWithCost(key K, value V, cost int64, duration time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ttl := duration
+	if ttl <= 0 {
+		ttl = c.defaultTTL
+	}
+	var expiration int64
+	if ttl > 0 {
+		expiration = time.Now().Add(ttl).UnixNano()
+	}
+
+	c.sketch.increment(c.hash(key))
+
+	if ele, ok := c.entries[key]; ok {
+		// Update existing entry
+		ent := ele.Value.(*entry[K, V])
+		ent.value = value
+		ent.expiration = expiration
+		ent.ttl = ttl
+		c.expiry.Set(key, expiration)
+		c.cost += cost - ent.cost
+		ent.cost = cost
+		c.onHit(ele)
+		for c.overCost(0) && len(c.entries) > 1 {
+			c.evictCost(c.entries[key])
+		}
+		return
+	}
+
+	for c.overCost(cost) && len(c.entries) > 0 {
+		c.evictCost(nil)
+	}
+
+	// New entry always enters the window
+	ent := &entry[K, V]{key: key, value: value, segment: windowSegment, cost: cost, expiration: expiration, ttl: ttl}
+	c.entries[key] = c.window.PushFront(ent)
+	c.expiry.Set(key, expiration)
+	c.cost += cost
+
+	if c.window.Len() > c.windowCap {
+		c.evict()
+	}
+}
+
+// Get looks up a key's value from the cache
+func (c *Cache[K, V]) Get(key K) (value V, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sketch.increment(c.hash(key))
+
+	ele, exists := c.entries[key]
+	if !exists {
+		return value, false
+	}
+	ent := ele.Value.(*entry[K, V])
+	if ent.expiration > 0 && time.Now().UnixNano() > ent.expiration {
+		c.removeElement(ele, common.ExpirationEvent)
+		return value, false
+	}
+	c.onHit(ele)
+	c.slide(ent)
+	return ent.value, true
+}
+
+// Has returns true if the key exists in the cache
+func (c *Cache[K, V]) Has(key K) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ele, ok := c.entries[key]
+	if !ok {
+		return false
+	}
+	ent := ele.Value.(*entry[K, V])
+	if ent.expiration > 0 && time.Now().UnixNano() > ent.expiration {
+		return false
+	}
+	return true
+}
+
+// Peek looks up a key's value without updating its recency or frequency
+func (c *Cache[K, V]) Peek(key K) (value V, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ele, exists := c.entries[key]
+	if !exists {
+		return value, false
+	}
+	ent := ele.Value.(*entry[K, V])
+	if ent.expiration > 0 && time.Now().UnixNano() > ent.expiration {
+		return value, false
+	}
+	return ent.value, true
+}
+
+// Delete removes the key from the cache, the eviction func receives a DeleteEvent.
+// It reports whether the key was in the cache
+func (c *Cache[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ele, ok := c.entries[key]
+	if !ok {
+		return false
+	}
+	c.removeElement(ele, common.DeleteEvent)
+	return true
+}
+
+// Keys returns a slice of the keys in the cache
+func (c *Cache[K, V]) Keys() []K {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	keys := make([]K, 0, len(c.entries))
+	now := time.Now().UnixNano()
+
+	for key, ele := range c.entries {
+		ent := ele.Value.(*entry[K, V])
+		if ent.expiration == 0 || now <= ent.expiration {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Len returns the number of items in the cache
+func (c *Cache[K, V]) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	count := 0
+	now := time.Now().UnixNano()
+
+	for _, ele := range c.entries {
+		ent := ele.Value.(*entry[K, V])
+		if ent.expiration == 0 || now <= ent.expiration {
+			count++
+		}
+	}
+	return count
+}
+
+// Clear purges all key-value pairs from the cache
+func (c *Cache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.clear()
+}
+
+// Close purges all key-value pairs from the cache and stop cleanup
+func (c *Cache[K, V]) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed {
+		close(c.stopChan)
+		c.closed = true
+	}
+	c.clear()
+}
+
+// AddListener registers a listener of the entries purged from the cache and returns its ID
+func (c *Cache[K, V]) AddListener(listener common.Listener[K, V]) int {
+	return c.listeners.Add(listener)
+}
+
+// RemoveListener unregisters the listener with the given ID, and reports whether it was registered
+func (c *Cache[K, V]) RemoveListener(id int) bool {
+	return c.listeners.Remove(id)
+}
+
+// SetDefaultTTL sets the default TTL for cache entries
+func (c *Cache[K, V]) SetDefaultTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.defaultTTL = ttl
+}
+
+// SetSlidingTTL sets whether an access restarts the TTL of the entry, so entries in use do not expire
+func (c *Cache[K, V]) SetSlidingTTL(sliding bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sliding = sliding
+}
+
+// SetMaxCost sets the maximum total cost of the cache entries, zero means no limit
+func (c *Cache[K, V]) SetMaxCost(maxCost int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxCost = maxCost
+	for c.overCost(0) && len(c.entries) > 1 {
+		c.evictCost(nil)
+	}
+}
+
+// Resize changes the maximum number of entries, zero means no limit, and evicts the entries
+// over the new size. The sketch is resized with its frequency history. It returns the number
+// of evicted entries
+func (c *Cache[K, V]) Resize(size int) (evicted int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := len(c.entries)
+	c.maxEntries = size
+	c.windowCap = max(1, size/100)
+	c.protectedCap = (size - c.windowCap) * 80 / 100
+	c.sketch = c.sketch.resize(size)
+	for size > 0 && len(c.entries) > size {
+		c.evictCost(nil)
+	}
+	for c.window.Len() > c.windowCap {
+		c.evict()
+	}
+	for c.protected.Len() > c.protectedCap {
+		c.demote()
+	}
+	return n - len(c.entries)
+}
+
+// Cost returns the total cost of the items in the cache
+func (c *Cache[K, V]) Cost() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cost
+}
+
+// clear purges all key-value pairs, the caller must hold the mutex
+func (c *Cache[K, V]) clear() {
+	if c.listeners.Len() > 0 {
+		for key, ele := range c.entries {
+			c.listeners.Notify(key, ele.Value.(*entry[K, V]).value, common.ClearEvent)
+		}
+	}
+
+	c.entries = make(map[K]*list.Element)
+	c.expiry.Clear()
+	c.window.Init()
+	c.probation.Init()
+	c.protected.Init()
+	c.cost = 0
+}
+
+// onHit updates the recency of an accessed entry, promoting it from probation to protected
+func (c *Cache[K, V]) onHit(ele *list.Element) {
+	ent := ele.Value.(*entry[K, V])
+	switch ent.segment {
+	case windowSegment:
+		c.window.MoveToFront(ele)
+	case protectedSegment:
+		c.protected.MoveToFront(ele)
+	case probationSegment:
+		c.probation.Remove(ele)
+		ent.segment = protectedSegment
+		c.entries[ent.key] = c.protected.PushFront(ent)
+
+		if c.protected.Len() > c.protectedCap {
+			c.demote()
+		}
+	}
+}
+
+// demote moves the least recently used protected entry back to probation
+func (c *Cache[K, V]) demote() {
+	demoted := c.protected.Remove(c.protected.Back()).(*entry[K, V])
+	demoted.segment = probationSegment
+	c.entries[demoted.key] = c.probation.PushFront(demoted)
+}
+
+// evict moves the window victim to the main segments if the admission filter prefers it
+// over the main victim, and evicts the other one
+func (c *Cache[K, V]) evict() {
+	candidate := c.window.Remove(c.window.Back()).(*entry[K, V])
+	delete(c.entries, candidate.key)
+
+	if c.maxEntries == 0 || c.probation.Len()+c.protected.Len() < c.maxEntries-c.windowCap {
+		c.admit(candidate)
+		return
+	}
+
+	victimList := c.probation
+	if victimList.Len() == 0 {
+		victimList = c.protected
+	}
+	victim := victimList.Back()
+	if victim == nil {
+		c.notifyEvicted(candidate)
+		return
+	}
+
+	if c.sketch.estimate(c.hash(candidate.key)) > c.sketch.estimate(c.hash(victim.Value.(*entry[K, V]).key)) {
+		c.removeElement(victim, common.EvictionEvent)
+		c.admit(candidate)
+		return
+	}
+	c.notifyEvicted(candidate)
+}
+
+// overCost reports whether adding cost to the cache exceeds maxCost
+func (c *Cache[K, V]) overCost(cost int64) bool {
+	return c.maxCost > 0 && c.cost+cost > c.maxCost
+}
+
+// evictCost evicts the main victim, or the window victim when the main segments are empty,
+// other than the element keep
+func (c *Cache[K, V]) evictCost(keep *list.Element) {
+	for _, l := range []*list.List{c.probation, c.protected, c.window} {
+		victim := l.Back()
+		if victim == keep && victim != nil {
+			victim = victim.Prev()
+		}
+		if victim != nil {
+			c.removeElement(victim, common.EvictionEvent)
+			return
+		}
+	}
+}
+
+// admit inserts an entry leaving the window into the probation segment
+func (c *Cache[K, V]) admit(ent *entry[K, V]) {
+	ent.segment = probationSegment
+	c.entries[ent.key] = c.probation.PushFront(ent)
+}
+
+// notifyEvicted calls the eviction func for an entry rejected by the admission filter
+func (c *Cache[K, V]) notifyEvicted(ent *entry[K, V]) {
+	c.cost -= ent.cost
+	c.expiry.Remove(ent.key)
+	c.listeners.Notify(ent.key, ent.value, common.EvictionEvent)
+}
+
+// removeElement removes an element from the cache
+func (c *Cache[K, V]) removeElement(ele *list.Element, event int) {
+	ent := ele.Value.(*entry[K, V])
+	delete(c.entries, ent.key)
+	c.cost -= ent.cost
+	c.expiry.Remove(ent.key)
+
+	switch ent.segment {
+	case windowSegment:
+		c.window.Remove(ele)
+	case probationSegment:
+		c.probation.Remove(ele)
+	case protectedSegment:
+		c.protected.Remove(ele)
+	}
+
+	c.listeners.Notify(ent.key, ent.value, event)
+}
+
+// hash returns the hash of a key used by the sketch
+func (c *Cache[K, V]) hash(key K) uint64 {
+	return maphash.Comparable(c.seed, key)
+}
+
+// startCleanup starts the cleanup goroutine
+func (c *Cache[K, V]) startCleanup() {
+	ticker := time.NewTicker(c.cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.cleanup()
+		case <-c.stopChan:
+			return
+		}
+	}
+}
+
+// cleanup removes expired entries, in batches so that readers are not blocked for long
+func (c *Cache[K, V]) cleanup() {
+	for {
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+			return
+		}
+		keys := c.expiry.Expired(time.Now().UnixNano(), common.ExpiryBatch)
+		for _, key := range keys {
+			if ele, ok := c.entries[key]; ok {
+				c.removeElement(ele, common.ExpirationEvent)
+			}
+		}
+		c.mu.Unlock()
+
+		if len(keys) < common.ExpiryBatch {
+			return
+		}
+	}
+}
+
+// slide restarts the TTL of an accessed entry when the TTL is sliding
+func (c *Cache[K, V]) slide(ent *entry[K, V]) {
+	if c.sliding && ent.ttl > 0 {
+		ent.expiration = time.Now().Add(ent.ttl).UnixNano()
+		c.expiry.Set(ent.key, ent.expiration)
+	}
+}
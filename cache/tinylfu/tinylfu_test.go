@@ -0,0 +1,259 @@
+package tinylfu
+
+import (
+	"bufio"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hungpdn/grule-plus/cache/arc"
+	"github.com/hungpdn/grule-plus/cache/common"
+	"github.com/hungpdn/grule-plus/cache/lfu"
+	"github.com/hungpdn/grule-plus/cache/lru"
+	"github.com/hungpdn/grule-plus/cache/twoq"
+)
+
+func TestSetGetHas(t *testing.T) {
+	c := New[string, string](100, 0)
+	defer c.Close()
+
+	c.Set("a", "va", 0)
+	c.Set("b", "vb", 0)
+
+	if v, ok := c.Get("a"); !ok || v != "va" {
+		t.Fatalf("Get a failed: %v %v", v, ok)
+	}
+	if !c.Has("b") || c.Has("c") {
+		t.Fatalf("Has returned unexpected results")
+	}
+	if c.Len() != 2 || len(c.Keys()) != 2 {
+		t.Fatalf("Len want 2 got %d", c.Len())
+	}
+
+	c.Set("a", "va2", 0)
+	if v, _ := c.Get("a"); v != "va2" {
+		t.Fatalf("Get a after update want va2 got %v", v)
+	}
+}
+
+func TestAdmissionPrefersFrequentKeys(t *testing.T) {
+	c := New[string, int](10, 0)
+	defer c.Close()
+
+	for i := 0; i < 10; i++ {
+		c.Set(string(rune('a'+i)), i, 0)
+	}
+	// Make the admitted keys frequent
+	for n := 0; n < 5; n++ {
+		for i := 0; i < 10; i++ {
+			c.Get(string(rune('a' + i)))
+		}
+	}
+
+	// A scan of one-off keys must not flush the frequent keys
+	for i := 0; i < 100; i++ {
+		c.Set(string(rune('A'+i)), i, 0)
+	}
+
+	present := 0
+	for i := 0; i < 10; i++ {
+		if c.Has(string(rune('a' + i))) {
+			present++
+		}
+	}
+	if present < 9 {
+		t.Fatalf("frequent keys should survive the scan, %d of 10 present", present)
+	}
+	if c.Len() != 10 {
+		t.Fatalf("Len want 10 got %d", c.Len())
+	}
+}
+
+func TestExpiration(t *testing.T) {
+	c := New[string, string](10, 0)
+	defer c.Close()
+
+	c.SetDefaultTTL(20 * time.Millisecond)
+	c.Set("x", "vx", 0)
+	c.Set("y", "vy", time.Hour)
+	time.Sleep(30 * time.Millisecond)
+
+	if c.Has("x") {
+		t.Fatalf("x should be expired by default TTL")
+	}
+	if _, ok := c.Get("x"); ok {
+		t.Fatalf("Get x should miss after expiration")
+	}
+	if !c.Has("y") {
+		t.Fatalf("y should not be expired")
+	}
+}
+
+func TestCleanupGoroutine(t *testing.T) {
+	c := New[string, string](10, 10*time.Millisecond)
+	defer c.Close()
+
+	events := make(chan int, 1)
+	c.AddListener(common.Listener[string, string]{Func: func(key, value string, event int) {
+		events <- event
+	}})
+	c.Set("z", "vz", 5*time.Millisecond)
+
+	select {
+	case ev := <-events:
+		if ev != common.ExpirationEvent {
+			t.Fatalf("expected ExpirationEvent got %d", ev)
+		}
+	case <-time.After(200 * time.Millisecond):
+		t.Fatalf("z should be cleaned up by goroutine")
+	}
+}
+
+func TestEvictedFunc(t *testing.T) {
+	c := New[string, int](2, 0)
+
+	events := map[int]int{}
+	c.AddListener(common.Listener[string, int]{Func: func(key string, value int, event int) {
+		events[event]++
+	}})
+
+	c.Set("a", 1, 0)
+	c.Set("b", 2, 0)
+	c.Set("c", 3, 0)
+	if events[common.EvictionEvent] != 1 || c.Len() != 2 {
+		t.Fatalf("expected one eviction, events %v len %d", events, c.Len())
+	}
+
+	c.Close()
+	if events[common.ClearEvent] != 2 {
+		t.Fatalf("expected two clear events on close, events %v", events)
+	}
+}
+
+func TestSketch(t *testing.T) {
+	s := newSketch(100)
+
+	s.increment(1)
+	if got := s.estimate(1); got != 1 {
+		t.Fatalf("first access should only be recorded by the doorkeeper, estimate %d", got)
+	}
+	for i := 0; i < 20; i++ {
+		s.increment(1)
+	}
+	if got := s.estimate(1); got != sketchMaxCount+1 {
+		t.Fatalf("counter should saturate, estimate %d", got)
+	}
+	if got := s.estimate(2); got != 0 {
+		t.Fatalf("unseen key estimate want 0 got %d", got)
+	}
+
+	s.reset()
+	if got := s.estimate(1); got != sketchMaxCount/2 {
+		t.Fatalf("reset should halve the counters, estimate %d", got)
+	}
+}
+
+func TestSketchResize(t *testing.T) {
+	s := newSketch(100)
+	for key := uint64(1); key <= 50; key++ {
+		for i := uint64(0); i <= key%10; i++ {
+			s.increment(key * 0x9e3779b97f4a7c15)
+		}
+	}
+
+	// Growing or shrinking the sketch keeps the estimate of every key, or raises it on collisions
+	for _, size := range []int{1000, 20} {
+		r := s.resize(size)
+		if r.mask != uint64(nextPowerOfTwo(8*max(size, 16))-1) {
+			t.Fatalf("resize(%d) width %d", size, r.mask+1)
+		}
+		for key := uint64(1); key <= 50; key++ {
+			hash := key * 0x9e3779b97f4a7c15
+			if got, want := r.estimate(hash), s.estimate(hash); got < want {
+				t.Fatalf("resize(%d) key %d estimate %d, want at least %d", size, key, got, want)
+			}
+		}
+	}
+}
+
+func TestResizeKeepsFrequencies(t *testing.T) {
+	c := New[string, int](100, 0)
+	c.Set("hot", 1, 0)
+	for i := 0; i < 10; i++ {
+		c.Get("hot")
+	}
+	want := c.sketch.estimate(c.hash("hot"))
+
+	c.Resize(1000)
+	if got := c.sketch.estimate(c.hash("hot")); got < want {
+		t.Fatalf("Resize should keep the frequency history, estimate %d want %d", got, want)
+	}
+}
+
+// cache is the subset of the cache policies replayed by the trace tests
+type cache interface {
+	Get(key string) (int, bool)
+	Set(key string, value int, duration time.Duration)
+}
+
+// loadTrace reads a trace of rule accesses, one rule name per line
+func loadTrace(t *testing.T, name string) []string {
+	t.Helper()
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatalf("open trace: %v", err)
+	}
+	defer f.Close()
+
+	keys := make([]string, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		keys = append(keys, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("read trace: %v", err)
+	}
+	return keys
+}
+
+// hitRatio replays the trace against the cache, loading the missing keys
+func hitRatio(c cache, keys []string) float64 {
+	hits := 0
+	for _, key := range keys {
+		if _, ok := c.Get(key); ok {
+			hits++
+			continue
+		}
+		c.Set(key, 0, 0)
+	}
+	return float64(hits) / float64(len(keys))
+}
+
+// TestHitRatioTraces replays traces of rule executions following a Zipf distribution over 2000
+// rules, interleaved with scans of rules executed once, with and without popularity drift.
+func TestHitRatioTraces(t *testing.T) {
+	traces := []string{"testdata/skewed_scan.trace", "testdata/drifting_scan.trace"}
+
+	for _, trace := range traces {
+		keys := loadTrace(t, trace)
+		for _, size := range []int{50, 200, 500} {
+			tinyLFU := hitRatio(New[string, int](size, 0), keys)
+			lruRatio := hitRatio(lru.New[string, int](size, 0), keys)
+			others := map[string]float64{
+				"lfu":  hitRatio(lfu.New[string, int](size, 0), keys),
+				"arc":  hitRatio(arc.New[string, int](size, 0), keys),
+				"twoq": hitRatio(twoq.New[string, int](size, 0), keys),
+			}
+			t.Logf("%s size %d: tinylfu %.3f, lru %.3f, others %v", trace, size, tinyLFU, lruRatio, others)
+
+			if tinyLFU < lruRatio+0.03 {
+				t.Errorf("%s size %d: tinylfu hit ratio %.3f should beat lru %.3f", trace, size, tinyLFU, lruRatio)
+			}
+			for name, ratio := range others {
+				if tinyLFU < ratio-0.02 {
+					t.Errorf("%s size %d: tinylfu hit ratio %.3f lower than %s %.3f", trace, size, tinyLFU, name, ratio)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,196 @@
+// trace records and replays sequences of cache key accesses, to compare the hit ratios of the
+// cache policies on real workloads.
+//
+// A trace file starts with a magic header followed by one uvarint per access: 0 introduces a key
+// seen for the first time, followed by its length and bytes, and n > 0 repeats the n-th distinct
+// key of the trace. Popular keys therefore cost one or two bytes per access. Plain text files with
+// one key per line are read as traces too.
+package trace
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/hungpdn/grule-plus/cache"
+)
+
+// magic is the header of a trace file
+const magic = "GRTRACE1"
+
+// maxKeyLen is the maximum length of a key read from a trace file
+const maxKeyLen = 1 << 20
+
+// Writer records key accesses to a trace file. It is safe for concurrent use.
+type Writer struct {
+	mu  sync.Mutex
+	w   *bufio.Writer
+	ids map[string]uint64 // number of each key seen so far, from 1
+	buf [binary.MaxVarintLen64]byte
+	n   int   // number of recorded accesses
+	err error // first write error, returned by every later call
+}
+
+// NewWriter writes the trace header to w and returns a Writer recording accesses to it.
+// Flush must be called once recording is done.
+func NewWriter(w io.Writer) (*Writer, error) {
+	tw := &Writer{w: bufio.NewWriter(w), ids: make(map[string]uint64)}
+	if _, err := tw.w.WriteString(magic); err != nil {
+		return nil, err
+	}
+	return tw, nil
+}
+
+// Record appends an access of the key to the trace
+func (w *Writer) Record(key string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.err != nil {
+		return w.err
+	}
+	if id, ok := w.ids[key]; ok {
+		w.writeUvarint(id)
+	} else {
+		w.ids[key] = uint64(len(w.ids) + 1)
+		w.writeUvarint(0)
+		w.writeUvarint(uint64(len(key)))
+		if w.err == nil {
+			_, w.err = w.w.WriteString(key)
+		}
+	}
+	w.n++
+	return w.err
+}
+
+// Len returns the number of recorded accesses
+func (w *Writer) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.n
+}
+
+// Flush writes the buffered accesses to the underlying writer
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.err != nil {
+		return w.err
+	}
+	w.err = w.w.Flush()
+	return w.err
+}
+
+// writeUvarint writes v unless a previous write failed
+func (w *Writer) writeUvarint(v uint64) {
+	if w.err != nil {
+		return
+	}
+	n := binary.PutUvarint(w.buf[:], v)
+	_, w.err = w.w.Write(w.buf[:n])
+}
+
+// Read reads all the key accesses of a trace file, or of a text file with one key per line.
+func Read(r io.Reader) ([]string, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(len(magic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if !bytes.Equal(header, []byte(magic)) {
+		return readText(br)
+	}
+	_, _ = br.Discard(len(magic))
+
+	keys := make([]string, 0)
+	names := make([]string, 0)
+	for {
+		id, err := binary.ReadUvarint(br)
+		if errors.Is(err, io.EOF) {
+			return keys, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("access %d: %w", len(keys), err)
+		}
+
+		if id == 0 {
+			key, err := readKey(br)
+			if err != nil {
+				return nil, fmt.Errorf("access %d: %w", len(keys), err)
+			}
+			names = append(names, key)
+			keys = append(keys, key)
+			continue
+		}
+		if id > uint64(len(names)) {
+			return nil, fmt.Errorf("access %d: unknown key %d", len(keys), id)
+		}
+		keys = append(keys, names[id-1])
+	}
+}
+
+// readKey reads the length and the bytes of a key seen for the first time
+func readKey(br *bufio.Reader) (string, error) {
+	n, err := binary.ReadUvarint(br)
+	if err != nil {
+		return "", err
+	}
+	if n > maxKeyLen {
+		return "", fmt.Errorf("key of %d bytes is too long", n)
+	}
+	key := make([]byte, n)
+	if _, err := io.ReadFull(br, key); err != nil {
+		return "", err
+	}
+	return string(key), nil
+}
+
+// readText reads one key per line, skipping empty lines
+func readText(r io.Reader) ([]string, error) {
+	keys := make([]string, 0)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if key := scanner.Text(); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// HitRatio replays the accesses against a new cache of the given type and size, adding the keys
+// missing from the cache, and returns the fraction of accesses found in the cache.
+func HitRatio(typ, size int, keys []string) float64 {
+	if len(keys) == 0 {
+		return 0
+	}
+
+	c := cache.NewCache(cache.Config[string, struct{}]{Type: typ, Size: size})
+	defer c.Close()
+
+	hits := 0
+	for _, key := range keys {
+		if _, ok := c.Get(key); ok {
+			hits++
+			continue
+		}
+		c.Set(key, struct{}{}, 0)
+	}
+	return float64(hits) / float64(len(keys))
+}
+
+// Distinct returns the number of distinct keys of the accesses
+func Distinct(keys []string) int {
+	seen := make(map[string]struct{}, len(keys)/4)
+	for _, key := range keys {
+		seen[key] = struct{}{}
+	}
+	return len(seen)
+}
@@ -0,0 +1,80 @@
+package trace
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hungpdn/grule-plus/cache"
+)
+
+func TestWriterRead(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	if err != nil {
+		t.Fatalf("NewWriter error: %v", err)
+	}
+
+	want := []string{"a", "b", "a", "", "c", "b", "a"}
+	for _, key := range want {
+		if err := w.Record(key); err != nil {
+			t.Fatalf("Record error: %v", err)
+		}
+	}
+	if w.Len() != len(want) {
+		t.Fatalf("Len want %d got %d", len(want), w.Len())
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush error: %v", err)
+	}
+
+	got, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("want %v got %v", want, got)
+	}
+	if Distinct(got) != 4 {
+		t.Fatalf("Distinct want 4 got %d", Distinct(got))
+	}
+}
+
+func TestReadText(t *testing.T) {
+	got, err := Read(strings.NewReader("a\nb\n\na\n"))
+	if err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	if strings.Join(got, ",") != "a,b,a" {
+		t.Fatalf("want [a b a] got %v", got)
+	}
+
+	if got, err := Read(strings.NewReader("")); err != nil || len(got) != 0 {
+		t.Fatalf("empty trace want no accesses got %v, %v", got, err)
+	}
+}
+
+func TestReadCorrupted(t *testing.T) {
+	// The second access repeats a key which was never introduced
+	if _, err := Read(strings.NewReader(magic + "\x00\x01a\x02")); err == nil {
+		t.Fatalf("unknown key should fail")
+	}
+	// The key is shorter than its length
+	if _, err := Read(strings.NewReader(magic + "\x00\x05ab")); err == nil {
+		t.Fatalf("truncated key should fail")
+	}
+}
+
+func TestHitRatio(t *testing.T) {
+	keys := strings.Split("a,b,a,b,a,b,c,a", ",")
+	// a and b miss once, then hit until c evicts one of them
+	if got := HitRatio(cache.LRU, 2, keys); got != 4.0/8 {
+		t.Fatalf("LRU hit ratio want 0.5 got %v", got)
+	}
+	if got := HitRatio(cache.LRU, 3, keys); got != 5.0/8 {
+		t.Fatalf("LRU hit ratio want 0.625 got %v", got)
+	}
+	if got := HitRatio(cache.LRU, 2, nil); got != 0 {
+		t.Fatalf("empty trace hit ratio want 0 got %v", got)
+	}
+}
@@ -0,0 +1,455 @@
+// twoq implements a 2Q cache.
+package twoq
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hungpdn/grule-plus/cache/common"
+)
+
+// Cache is a 2Q cache structure
+type Cache[K comparable, V any] struct {
+	maxEntries int                    // The maximum number of cache entries before an entry is evicted, zero means no limit
+	maxCost    int64                  // The maximum total cost of the cache entries before an entry is evicted, zero means no limit
+	cost       int64                  // Total cost of the cache entries
+	entries    map[K]*list.Element    // Map for quick access to cache entries
+	expiry     *common.Expiry[K]      // Expiration times of the entries with a TTL
+	a1         *list.List             // A1: FIFO queue for new entries
+	a2         *list.List             // A2: LRU queue for frequently accessed entries
+	b          *list.List             // B: ghost queue for evicted entries
+	kin        int                    // Size of A1 queue (typically maxEntries/4)
+	mu         sync.RWMutex           // Mutex to ensure concurrent access safety
+	listeners  common.Listeners[K, V] // Listeners notified when an entry is purged from the cache
+	// cleanup
+	defaultTTL      time.Duration // default TTL for item expire
+	sliding         bool          // whether an access restarts the TTL of the entry
+	cleanupInterval time.Duration // how often to run the expired entry cleaner
+	stopChan        chan struct{} // Channel to stop cleanup goroutine
+	closed          bool          // Flag to indicate if cache is closed
+}
+
+// entry represents an entry in the 2Q cache
+type entry[K comparable, V any] struct {
+	key        K
+	value      V
+	inA2       bool          // whether the entry is in A2
+	cost       int64         // weight of the entry counted against maxCost
+	expiration int64         // Unix timestamp (nanoseconds) when the item expires, 0 means never expires
+	ttl        time.Duration // time-to-live of the entry, restarted by each access when the TTL is sliding
+}
+
+// New creates a new 2Q cache
+// maxEntries: the maximum number of cache entries before an entry is evicted, zero means no limit
+// cleanupInterval: how often to run the expired entry cleaner
+func New[K comparable, V any](maxEntries int, cleanupInterval time.Duration) *Cache[K, V] {
+	kin := maxEntries / 4
+	if kin < 1 {
+		kin = 1
+	}
+
+	cache := &Cache[K, V]{
+		maxEntries:      maxEntries,
+		entries:         make(map[K]*list.Element),
+		expiry:          common.NewExpiry[K](),
+		a1:              list.New(),
+		a2:              list.New(),
+		b:               list.New(),
+		kin:             kin,
+		cleanupInterval: cleanupInterval,
+		stopChan:        make(chan struct{}),
+	}
+	if cache.cleanupInterval > 0 {
+		go cache.startCleanup()
+	}
+	return cache
+}
+
+// Set inserts or updates the specified key-value pair with an expiration time, it costs 1
+func (c *Cache[K, V]) Set(key K, value V, duration time.Duration) {
+	c.SetWithCost(key, value, 1, duration)
+}
+
+// SetWithCost inserts or updates the specified key-value pair weighted by cost with an expiration time
+func (c *Cache[K, V]) SetWithCost(key K, value V, cost int64, duration time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ttl := duration
+	if ttl <= 0 {
+		ttl = c.defaultTTL
+	}
+	var expiration int64
+	if ttl > 0 {
+		expiration = time.Now().Add(ttl).UnixNano()
+	}
+
+	if ele, ok := c.entries[key]; ok {
+		// Update existing entry
+		ent := ele.Value.(*entry[K, V])
+		ent.value = value
+		ent.expiration = expiration
+		ent.ttl = ttl
+		c.expiry.Set(key, expiration)
+		// If in A1, move to front of A2
+		if !ent.inA2 {
+			c.a1.Remove(ele)
+			ent.inA2 = true
+			c.entries[key] = c.a2.PushFront(ent)
+		} else {
+			// Already in A2, move to front
+			c.a2.MoveToFront(ele)
+		}
+		c.cost += cost - ent.cost
+		ent.cost = cost
+		for c.overCost(0) && len(c.entries) > 1 {
+			c.evict()
+		}
+		return
+	}
+
+	// Make room for the cost of the new entry
+	for c.overCost(cost) && len(c.entries) > 0 {
+		c.evict()
+	}
+
+	// New entry
+	ent := &entry[K, V]{key: key, value: value, cost: cost, expiration: expiration, ttl: ttl}
+	c.entries[key] = c.a1.PushFront(ent)
+	c.expiry.Set(key, expiration)
+	c.cost += cost
+
+	// Check if we need to evict
+	if c.maxEntries > 0 && c.a1.Len()+c.a2.Len() > c.maxEntries {
+		c.evict()
+	}
+}
+
+// Get looks up a key's value from the cache
+func (c *Cache[K, V]) Get(key K) (value V, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ele, exists := c.entries[key]; exists {
+		ent := ele.Value.(*entry[K, V])
+		if ent.expiration > 0 && time.Now().UnixNano() > ent.expiration {
+			// Expired, remove it
+			c.removeElement(ele, common.ExpirationEvent)
+			return value, false
+		}
+
+		// Move from A1 to A2 if in A1
+		if !ent.inA2 {
+			c.a1.Remove(ele)
+			ent.inA2 = true
+			c.entries[key] = c.a2.PushFront(ent)
+		} else {
+			// Already in A2, move to front
+			c.a2.MoveToFront(ele)
+		}
+		c.slide(ent)
+		return ent.value, true
+	}
+
+	// Cache miss - check ghost queue
+	if c.checkGhost(key) {
+		// Was in B, don't add to cache (2Q policy)
+		return value, false
+	}
+
+	return value, false
+}
+
+// Has returns true if the key exists in the cache
+func (c *Cache[K, V]) Has(key K) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ele, ok := c.entries[key]
+	if !ok {
+		return false
+	}
+	ent := ele.Value.(*entry[K, V])
+	if ent.expiration > 0 && time.Now().UnixNano() > ent.expiration {
+		return false
+	}
+	return true
+}
+
+// Peek looks up a key's value without updating its recency or frequency
+func (c *Cache[K, V]) Peek(key K) (value V, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ele, exists := c.entries[key]
+	if !exists {
+		return value, false
+	}
+	ent := ele.Value.(*entry[K, V])
+	if ent.expiration > 0 && time.Now().UnixNano() > ent.expiration {
+		return value, false
+	}
+	return ent.value, true
+}
+
+// Delete removes the key from the cache, the eviction func receives a DeleteEvent.
+// It reports whether the key was in the cache
+func (c *Cache[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ele, ok := c.entries[key]
+	if !ok {
+		return false
+	}
+	c.removeElement(ele, common.DeleteEvent)
+	return true
+}
+
+// Keys returns a slice of the keys in the cache
+func (c *Cache[K, V]) Keys() []K {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	keys := make([]K, 0, len(c.entries))
+	now := time.Now().UnixNano()
+
+	for key, ele := range c.entries {
+		ent := ele.Value.(*entry[K, V])
+		if ent.expiration == 0 || now <= ent.expiration {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Len returns the number of items in the cache
+func (c *Cache[K, V]) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	count := 0
+	now := time.Now().UnixNano()
+
+	for _, ele := range c.entries {
+		ent := ele.Value.(*entry[K, V])
+		if ent.expiration == 0 || now <= ent.expiration {
+			count++
+		}
+	}
+	return count
+}
+
+// Clear purges all key-value pairs from the cache
+func (c *Cache[K, V]) Clear() {
+	// Note: This function assumes the caller has already acquired the mutex
+	for key, ele := range c.entries {
+		if c.listeners.Len() > 0 {
+			ent := ele.Value.(*entry[K, V])
+			c.listeners.Notify(key, ent.value, common.ClearEvent)
+		}
+	}
+
+	c.entries = make(map[K]*list.Element)
+	c.expiry.Clear()
+	c.a1.Init()
+	c.a2.Init()
+	c.b.Init()
+	c.cost = 0
+}
+
+// Close purges all key-value pairs from the cache and stop cleanup
+func (c *Cache[K, V]) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Stop cleanup goroutine first
+	if !c.closed {
+		close(c.stopChan)
+		c.closed = true
+	}
+	c.Clear()
+}
+
+// AddListener registers a listener of the entries purged from the cache and returns its ID
+func (c *Cache[K, V]) AddListener(listener common.Listener[K, V]) int {
+	return c.listeners.Add(listener)
+}
+
+// RemoveListener unregisters the listener with the given ID, and reports whether it was registered
+func (c *Cache[K, V]) RemoveListener(id int) bool {
+	return c.listeners.Remove(id)
+}
+
+// SetMaxCost sets the maximum total cost of the cache entries, zero means no limit
+func (c *Cache[K, V]) SetMaxCost(maxCost int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxCost = maxCost
+	for c.overCost(0) && len(c.entries) > 1 {
+		c.evict()
+	}
+}
+
+// Resize changes the maximum number of entries, zero means no limit, and evicts the entries
+// over the new size. It returns the number of evicted entries
+func (c *Cache[K, V]) Resize(size int) (evicted int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := len(c.entries)
+	c.maxEntries = size
+	c.kin = max(1, size/4)
+	if size > 0 {
+		for len(c.entries) > size {
+			c.evict()
+		}
+		for c.b.Len() > size {
+			c.b.Remove(c.b.Back())
+		}
+	}
+	return n - len(c.entries)
+}
+
+// Cost returns the total cost of the items in the cache
+func (c *Cache[K, V]) Cost() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cost
+}
+
+// SetDefaultTTL sets the default TTL for cache entries
+func (c *Cache[K, V]) SetDefaultTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.defaultTTL = ttl
+}
+
+// SetSlidingTTL sets whether an access restarts the TTL of the entry, so entries in use do not expire
+func (c *Cache[K, V]) SetSlidingTTL(sliding bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sliding = sliding
+}
+
+// overCost reports whether adding cost to the cache exceeds maxCost
+func (c *Cache[K, V]) overCost(cost int64) bool {
+	return c.maxCost > 0 && c.cost+cost > c.maxCost
+}
+
+// evict implements the 2Q eviction policy
+func (c *Cache[K, V]) evict() {
+	// First try to evict from A1 (FIFO)
+	if c.a1.Len() > 0 {
+		ele := c.a1.Back()
+		c.a1.Remove(ele)
+		ent := ele.Value.(*entry[K, V])
+		delete(c.entries, ent.key)
+		c.cost -= ent.cost
+		c.expiry.Remove(ent.key)
+
+		// Add to ghost queue B
+		c.b.PushFront(ent)
+		if c.b.Len() > c.maxEntries {
+			c.b.Remove(c.b.Back())
+		}
+
+		c.listeners.Notify(ent.key, ent.value, common.EvictionEvent)
+		return
+	}
+
+	// If A1 is empty, evict from A2 (LRU)
+	if c.a2.Len() > 0 {
+		ele := c.a2.Back()
+		c.a2.Remove(ele)
+		ent := ele.Value.(*entry[K, V])
+		delete(c.entries, ent.key)
+		c.cost -= ent.cost
+		c.expiry.Remove(ent.key)
+
+		c.listeners.Notify(ent.key, ent.value, common.EvictionEvent)
+	}
+}
+
+// checkGhost checks if key exists in ghost queue B and removes it if found
+func (c *Cache[K, V]) checkGhost(key K) bool {
+	for ele := c.b.Front(); ele != nil; ele = ele.Next() {
+		if ele.Value.(*entry[K, V]).key == key {
+			c.b.Remove(ele)
+			return true
+		}
+	}
+	return false
+}
+
+// startCleanup starts the cleanup goroutine
+func (c *Cache[K, V]) startCleanup() {
+	ticker := time.NewTicker(c.cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.cleanup()
+		case <-c.stopChan:
+			return
+		}
+	}
+}
+
+// cleanup removes expired entries, in batches so that readers are not blocked for long
+func (c *Cache[K, V]) cleanup() {
+	removed := 0
+	for {
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+			break
+		}
+		keys := c.expiry.Expired(time.Now().UnixNano(), common.ExpiryBatch)
+		for _, key := range keys {
+			if ele, ok := c.entries[key]; ok {
+				c.removeElement(ele, common.ExpirationEvent)
+			}
+		}
+		c.mu.Unlock()
+
+		removed += len(keys)
+		if len(keys) < common.ExpiryBatch {
+			break
+		}
+	}
+
+	if removed > 0 {
+		fmt.Printf("Cache: Running cleanup routine, removed %d expired entries\n", removed)
+	}
+}
+
+// removeElement removes an element from the cache
+func (c *Cache[K, V]) removeElement(ele *list.Element, event int) {
+	ent := ele.Value.(*entry[K, V])
+	delete(c.entries, ent.key)
+	c.cost -= ent.cost
+	c.expiry.Remove(ent.key)
+
+	// Remove from whichever list it's in, list.Remove returns the value even for another list
+	if ent.inA2 {
+		c.a2.Remove(ele)
+	} else {
+		c.a1.Remove(ele)
+	}
+
+	c.listeners.Notify(ent.key, ent.value, event)
+}
+
+// slide restarts the TTL of an accessed entry when the TTL is sliding
+func (c *Cache[K, V]) slide(ent *entry[K, V]) {
+	if c.sliding && ent.ttl > 0 {
+		ent.expiration = time.Now().Add(ent.ttl).UnixNano()
+		c.expiry.Set(ent.key, ent.expiration)
+	}
+}
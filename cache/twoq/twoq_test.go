@@ -4,18 +4,18 @@ import (
 	"testing"
 	"time"
 
-	"github.com/hungpdn/grule-plus/internal/cache/common"
+	"github.com/hungpdn/grule-plus/cache/common"
 )
 
 func TestNewAndLen(t *testing.T) {
-	cache := New(10, 0)
+	cache := New[string, string](10, 0)
 	if cache.Len() != 0 {
 		t.Errorf("expected length 0, got %d", cache.Len())
 	}
 }
 
 func TestSetAndGet(t *testing.T) {
-	cache := New(10, 0)
+	cache := New[string, string](10, 0)
 
 	// Test Set and Get
 	cache.Set("key1", "value1", 0)
@@ -31,7 +31,7 @@ func TestSetAndGet(t *testing.T) {
 }
 
 func TestHas(t *testing.T) {
-	cache := New(10, 0)
+	cache := New[string, string](10, 0)
 
 	cache.Set("key1", "value1", 0)
 	if !cache.Has("key1") {
@@ -44,7 +44,7 @@ func TestHas(t *testing.T) {
 }
 
 func TestEvictionPolicy(t *testing.T) {
-	cache := New(3, 0)
+	cache := New[string, string](3, 0)
 
 	// Fill cache
 	cache.Set("key1", "value1", 0)
@@ -67,7 +67,7 @@ func TestEvictionPolicy(t *testing.T) {
 }
 
 func TestDefaultTTLAndExpiration(t *testing.T) {
-	cache := New(10, 100*time.Millisecond)
+	cache := New[string, string](10, 100*time.Millisecond)
 	cache.SetDefaultTTL(200 * time.Millisecond)
 
 	cache.Set("key1", "value1", 0) // Should use default TTL
@@ -86,7 +86,7 @@ func TestDefaultTTLAndExpiration(t *testing.T) {
 }
 
 func TestCleanupGoroutine(t *testing.T) {
-	cache := New(10, 100*time.Millisecond)
+	cache := New[string, string](10, 100*time.Millisecond)
 	cache.SetDefaultTTL(150 * time.Millisecond)
 
 	cache.Set("key1", "value1", 0)
@@ -102,18 +102,18 @@ func TestCleanupGoroutine(t *testing.T) {
 	cache.Close()
 }
 
-func TestEvictedFuncAndSetEvictedFunc(t *testing.T) {
-	cache := New(2, 0)
+func TestEvictedFuncAndAddListener(t *testing.T) {
+	cache := New[string, string](2, 0)
 
-	var evictedKey any
-	var evictedValue any
+	var evictedKey string
+	var evictedValue string
 	var evictedEvent int
 
-	_ = cache.SetEvictedFunc(func(key, value any, event int) {
+	cache.AddListener(common.Listener[string, string]{Func: func(key, value string, event int) {
 		evictedKey = key
 		evictedValue = value
 		evictedEvent = event
-	})
+	}})
 
 	cache.Set("key1", "value1", 0)
 	cache.Set("key2", "value2", 0)
@@ -125,7 +125,7 @@ func TestEvictedFuncAndSetEvictedFunc(t *testing.T) {
 }
 
 func TestKeysAndClear(t *testing.T) {
-	cache := New(10, 0)
+	cache := New[string, string](10, 0)
 
 	cache.Set("key1", "value1", 0)
 	cache.Set("key2", "value2", 0)
@@ -143,7 +143,7 @@ func TestKeysAndClear(t *testing.T) {
 }
 
 func Test2QPromotion(t *testing.T) {
-	cache := New(4, 0)
+	cache := New[string, string](4, 0)
 
 	// Add items to A1
 	cache.Set("key1", "value1", 0)
@@ -171,3 +171,23 @@ func Test2QPromotion(t *testing.T) {
 		t.Error("expected key3 to be evicted")
 	}
 }
+
+func TestA2HitsDoNotDuplicateEntries(t *testing.T) {
+	cache := New[string, string](4, 0)
+
+	// Every hit on an entry of A2 moves it to the front of A2 instead of adding it again
+	cache.Set("key1", "value1", 0)
+	for i := 0; i < 3; i++ {
+		cache.Get("key1")
+	}
+	cache.Set("key1", "value2", 0)
+	if cache.a1.Len() != 0 || cache.a2.Len() != 1 {
+		t.Fatalf("expected key1 once in A2, got %d entries in A1 and %d in A2", cache.a1.Len(), cache.a2.Len())
+	}
+
+	// Removing an entry of A2 removes it from A2
+	cache.Delete("key1")
+	if cache.a1.Len() != 0 || cache.a2.Len() != 0 {
+		t.Fatalf("expected empty lists, got %d entries in A1 and %d in A2", cache.a1.Len(), cache.a2.Len())
+	}
+}
@@ -0,0 +1,111 @@
+// grule-plus-server serves a partitioned grule-plus engine over HTTP.
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/hungpdn/grule-plus/cache/trace"
+	"github.com/hungpdn/grule-plus/engine"
+	"github.com/hungpdn/grule-plus/internal/logger"
+	"github.com/hungpdn/grule-plus/server"
+)
+
+func main() {
+	if err := run(); err != nil {
+		logger.Errorf("[main] server has error : %v", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	var (
+		addr            = flag.String("addr", ":8080", "address to listen on")
+		cacheType       = flag.String("cache-type", string(engine.LRU), "type of cache: lru, lfu, arc, twoq, random, tinylfu, sieve, s3fifo")
+		size            = flag.Int("size", 1000, "size of the cache, 0 means unlimited")
+		cleanupInterval = flag.Int("cleanup-interval", 0, "cleanup interval in seconds, 0 means no cleanup")
+		ttl             = flag.Int("ttl", 0, "time-to-live in seconds, 0 means no expiration")
+		partition       = flag.Int("partition", 0, "number of partitions, at least the number of CPUs")
+		factName        = flag.String("fact-name", "Fact", "name of the fact to be used in rules")
+		shutdownTimeout = flag.Int("shutdown-timeout", 10, "graceful shutdown timeout in seconds")
+		readTimeout     = flag.Int("read-timeout", 30, "timeout in seconds to read a request, 0 means no timeout")
+		readHeader      = flag.Int("read-header-timeout", 10, "timeout in seconds to read the request headers")
+		writeTimeout    = flag.Int("write-timeout", 60, "timeout in seconds to write a response, 0 means no timeout")
+		coverage        = flag.Bool("coverage", false, "record rule coverage, served on /coverage")
+		shards          = flag.Int("shards", 0, "number of lock-striped segments of each partition cache")
+		maxCost         = flag.Int64("max-cost", 0, "memory budget of each partition in bytes of GRL statements, 0 means no limit")
+		slidingTTL      = flag.Bool("sliding-ttl", false, "each execution restarts the TTL of the rule")
+		logSample       = flag.Int("log-sample-interval", 0, "interval in seconds of the log limits, 0 means no limit")
+		logErrorLimit   = flag.Int("log-error-limit", 10, "error logs written per message, rule and sample interval")
+		logLevel        = flag.String("log-level", "info", "log level: debug, info, warn or error, SIGUSR1 toggles debug")
+		tracePath       = flag.String("trace", "", "record the executed rules to a trace file, replayed by grule-plus simulate")
+		breakerFailures = flag.Int("breaker-failures", 0, "failed executions quarantining a rule, 0 disables the circuit breakers")
+		breakerWindow   = flag.Int("breaker-window", 60, "window in seconds in which the failures of a rule are counted")
+		breakerCooldown = flag.Int("breaker-cooldown", 30, "time in seconds a rule is quarantined before it is probed")
+		factRedaction   = flag.String("fact-redaction", string(engine.RedactTagged), "how facts are written in the logs: tagged, type, hash or none")
+	)
+	flag.Parse()
+
+	if err := engine.SetLogLevel(*logLevel); err != nil {
+		return err
+	}
+
+	var recorder *trace.Writer
+	if *tracePath != "" {
+		f, err := os.Create(*tracePath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if recorder, err = trace.NewWriter(f); err != nil {
+			return err
+		}
+		defer func() {
+			if err := recorder.Flush(); err != nil {
+				logger.Errorf("[main] flush trace has error : %v", err)
+			}
+		}()
+	}
+
+	grule := engine.NewPartitionEngine(engine.Config{
+		Type:            engine.CacheType(*cacheType),
+		Size:            *size,
+		CleanupInterval: *cleanupInterval,
+		TTL:             *ttl,
+		Partition:       *partition,
+		FactName:        *factName,
+		Coverage:        *coverage,
+		MaxCost:         *maxCost,
+		Shards:          *shards,
+		SlidingTTL:      *slidingTTL,
+		Trace:           recorder,
+		FactRedaction:   engine.FactRedaction(*factRedaction),
+		LogSampling: engine.LogSampling{
+			Interval: time.Duration(*logSample) * time.Second,
+			Error:    *logErrorLimit,
+		},
+		CircuitBreaker: engine.CircuitBreaker{
+			Failures: *breakerFailures,
+			Window:   time.Duration(*breakerWindow) * time.Second,
+			Cooldown: time.Duration(*breakerCooldown) * time.Second,
+		},
+	}, nil)
+	defer grule.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go toggleDebugOnSignal(ctx, *logLevel)
+
+	srv := server.New(grule, server.Config{
+		Addr:              *addr,
+		ReadTimeout:       *readTimeout,
+		ReadHeaderTimeout: *readHeader,
+		WriteTimeout:      *writeTimeout,
+		ShutdownTimeout:   *shutdownTimeout,
+	})
+	return srv.ListenAndServe(ctx)
+}
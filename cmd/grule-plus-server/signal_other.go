@@ -0,0 +1,8 @@
+//go:build !unix
+
+package main
+
+import "context"
+
+// toggleDebugOnSignal does nothing, SIGUSR1 only exists on unix systems.
+func toggleDebugOnSignal(ctx context.Context, level string) {}
@@ -0,0 +1,37 @@
+//go:build unix
+
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/hungpdn/grule-plus/engine"
+	"github.com/hungpdn/grule-plus/internal/logger"
+)
+
+// toggleDebugOnSignal switches the log level between debug and the given level on each SIGUSR1,
+// until the context is done.
+func toggleDebugOnSignal(ctx context.Context, level string) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+	defer signal.Stop(signals)
+
+	debug := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			debug = !debug
+			next := level
+			if debug {
+				next = "debug"
+			}
+			_ = engine.SetLogLevel(next)
+			logger.Warnf("[main][toggleDebugOnSignal] log level set to %s", next)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/hungpdn/grule-plus/engine"
+)
+
+func benchCommand(args []string, stdout io.Writer) error {
+	var (
+		flags      ruleFlags
+		iterations int
+		warmup     int
+	)
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	flags.register(fs)
+	fs.IntVar(&iterations, "n", 1000, "number of measured executions")
+	fs.IntVar(&warmup, "warmup", 100, "number of executions before measuring")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: grule-plus bench [flags] <file.grl>")
+		fs.PrintDefaults()
+	}
+	file, err := parseRuleArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if iterations <= 0 {
+		return errors.New("-n must be positive")
+	}
+
+	grule, rule, err := loadEngine(file, flags.factName)
+	if err != nil {
+		return err
+	}
+	defer grule.Close()
+
+	raws, err := readFacts(flags.factFile)
+	if err != nil {
+		return err
+	}
+
+	facts := make([]*engine.JSONFact, 0, len(raws))
+	for _, raw := range raws {
+		fact, err := decodeFact(raw)
+		if err != nil {
+			return err
+		}
+		facts = append(facts, fact)
+	}
+
+	latencies := make([]time.Duration, 0, iterations)
+	for i := 0; i < warmup+iterations; i++ {
+		// the values of a fact are not modified by the rules, only its result
+		fact := engine.NewJSONFact(facts[i%len(facts)].Values)
+
+		start := time.Now()
+		if err := grule.Execute(context.Background(), rule, fact); err != nil {
+			return fmt.Errorf("execute fact %d: %w", i%len(raws), err)
+		}
+		if i >= warmup {
+			latencies = append(latencies, time.Since(start))
+		}
+	}
+
+	printLatencies(stdout, latencies)
+	return nil
+}
+
+// printLatencies prints the distribution of the latencies.
+func printLatencies(w io.Writer, latencies []time.Duration) {
+	slices.Sort(latencies)
+
+	var total time.Duration
+	for _, latency := range latencies {
+		total += latency
+	}
+	mean := total / time.Duration(len(latencies))
+
+	fmt.Fprintf(w, "executions: %d\n", len(latencies))
+	fmt.Fprintf(w, "min:        %v\n", latencies[0])
+	fmt.Fprintf(w, "mean:       %v\n", mean)
+	fmt.Fprintf(w, "p50:        %v\n", percentile(latencies, 50))
+	fmt.Fprintf(w, "p95:        %v\n", percentile(latencies, 95))
+	fmt.Fprintf(w, "p99:        %v\n", percentile(latencies, 99))
+	fmt.Fprintf(w, "max:        %v\n", latencies[len(latencies)-1])
+	fmt.Fprintf(w, "throughput: %.0f facts/s\n", float64(len(latencies))/total.Seconds())
+}
+
+// percentile returns the p-th percentile of the sorted latencies.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	index := (len(sorted)*p+99)/100 - 1
+	if index < 0 {
+		index = 0
+	}
+	return sorted[index]
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/hungpdn/grule-plus/engine"
+)
+
+// diagnostic is an error found in a GRL file.
+type diagnostic struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (d diagnostic) String() string {
+	if d.Line == 0 {
+		return fmt.Sprintf("%s: %s", d.File, d.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", d.File, d.Line, d.Column, d.Message)
+}
+
+func lintCommand(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: grule-plus lint <file.grl|dir>...")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("no GRL file given")
+	}
+
+	files, err := grlFiles(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, file := range files {
+		diagnostics, err := lintFile(file)
+		if err != nil {
+			return err
+		}
+		for _, d := range diagnostics {
+			fmt.Fprintln(stdout, d)
+		}
+		if len(diagnostics) > 0 {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(stdout, "%d of %d file(s) failed\n", failed, len(files))
+		return errSilent
+	}
+	fmt.Fprintf(stdout, "%d file(s) ok\n", len(files))
+	return nil
+}
+
+// lintFile compiles a GRL file and returns its errors.
+func lintFile(file string) ([]diagnostic, error) {
+	statement, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	_, err = engine.CompileRule(string(statement))
+	return diagnose(file, err), nil
+}
+
+// diagnose converts a compilation error into diagnostics.
+func diagnose(file string, err error) []diagnostic {
+	if err == nil {
+		return nil
+	}
+
+	var ce *engine.RuleCompileError
+	if !errors.As(err, &ce) || len(ce.Syntax) == 0 {
+		return []diagnostic{{File: file, Message: err.Error()}}
+	}
+
+	diagnostics := make([]diagnostic, 0, len(ce.Syntax))
+	for _, e := range ce.Syntax {
+		diagnostics = append(diagnostics, diagnostic{File: file, Line: e.Line, Column: e.Column, Message: e.Message})
+	}
+	return diagnostics
+}
+
+// grlFiles expands the directories of the arguments into the GRL files they contain.
+func grlFiles(args []string) ([]string, error) {
+	files := make([]string, 0, len(args))
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+		err = filepath.WalkDir(arg, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && filepath.Ext(path) == ".grl" {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,72 @@
+// grule-plus is a command-line tool to lint, run, match, benchmark and test GRL rules, and to
+// simulate the cache types on traces of rule executions.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hungpdn/grule-plus/internal/logger"
+)
+
+const usage = `Usage: grule-plus <command> [flags] [arguments]
+
+Commands:
+  lint      compile GRL files and report errors with their line and column
+  run       execute a rule file against a JSON fact and print the result
+  match     list the rules of a rule file matching a JSON fact
+  bench     measure the per-fact execution latency of a rule file
+  test      run declarative rule test specs and optionally write a JUnit XML report
+  simulate  replay a trace of rule executions against every cache type and print the hit ratios
+
+Run "grule-plus <command> -h" for the flags of a command.
+`
+
+// command runs a subcommand with its arguments, writing its output to stdout.
+type command func(args []string, stdout io.Writer) error
+
+var commands = map[string]command{
+	"lint":     lintCommand,
+	"run":      runCommand,
+	"match":    matchCommand,
+	"bench":    benchCommand,
+	"test":     testCommand,
+	"simulate": simulateCommand,
+}
+
+// errSilent is returned by a command which has already reported its failure.
+var errSilent = errors.New("silent error")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run dispatches the arguments to a subcommand and returns the process exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "grule-plus: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+
+	// keep the engine logs out of the command output unless they are errors
+	if err := logger.NewLogger(logger.Config{Level: logger.LevelError}, logger.SlogInstance); err != nil {
+		fmt.Fprintf(stderr, "grule-plus: %v\n", err)
+		return 1
+	}
+
+	if err := cmd(args[1:], stdout); err != nil {
+		if !errors.Is(err, errSilent) {
+			fmt.Fprintf(stderr, "grule-plus %s: %v\n", args[0], err)
+		}
+		return 1
+	}
+	return 0
+}
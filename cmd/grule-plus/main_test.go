@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hungpdn/grule-plus/cache/trace"
+)
+
+const discountStatement = `rule DiscountRule "Apply discount" salience 10 {
+	when
+		Fact.Amount > 100
+	then
+		Fact.Discount = 10;
+		Retract("DiscountRule");
+}`
+
+const brokenStatement = `rule Broken "Broken rule" {
+	when
+		Fact.Amount >
+	then
+		Fact.Discount = 10;
+}`
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestLint(t *testing.T) {
+	dir := t.TempDir()
+	ok := writeFile(t, dir, "ok.grl", discountStatement)
+	broken := writeFile(t, dir, "broken.grl", brokenStatement)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"lint", ok}, &stdout, &stderr); code != 0 {
+		t.Fatalf("lint valid file exit code want 0 got %d: %s", code, stderr.String())
+	}
+
+	stdout.Reset()
+	if code := run([]string{"lint", dir}, &stdout, &stderr); code != 1 {
+		t.Fatalf("lint directory exit code want 1 got %d", code)
+	}
+	if !strings.Contains(stdout.String(), broken+":4:") {
+		t.Fatalf("lint output should report the line of the error: %s", stdout.String())
+	}
+	if !strings.Contains(stdout.String(), "1 of 2 file(s) failed") {
+		t.Fatalf("lint output should summarize the failures: %s", stdout.String())
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	rule := writeFile(t, dir, "discount.grl", discountStatement)
+	fact := writeFile(t, dir, "fact.json", `{"Amount": 150}`)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"run", "-fact", fact, rule}, &stdout, &stderr); code != 0 {
+		t.Fatalf("run exit code want 0 got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), `"Discount": 10`) {
+		t.Fatalf("run output should contain the discount: %s", stdout.String())
+	}
+}
+
+func TestMatch(t *testing.T) {
+	dir := t.TempDir()
+	rule := writeFile(t, dir, "discount.grl", discountStatement)
+	facts := writeFile(t, dir, "facts.json", `[{"Amount": 150}, {"Amount": 50}]`)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"match", "-fact", facts, rule}, &stdout, &stderr); code != 0 {
+		t.Fatalf("match exit code want 0 got %d: %s", code, stderr.String())
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], "0 ") || !strings.Contains(lines[1], "DiscountRule") {
+		t.Fatalf("match should only list the first fact: %s", stdout.String())
+	}
+}
+
+func TestBench(t *testing.T) {
+	dir := t.TempDir()
+	rule := writeFile(t, dir, "discount.grl", discountStatement)
+	fact := writeFile(t, dir, "fact.json", `{"Amount": 150}`)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"bench", "-n", "10", "-warmup", "1", "-fact", fact, rule}, &stdout, &stderr); code != 0 {
+		t.Fatalf("bench exit code want 0 got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "executions: 10") || !strings.Contains(stdout.String(), "p99:") {
+		t.Fatalf("bench output should report the latencies: %s", stdout.String())
+	}
+}
+
+func TestUnknownCommand(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"unknown"}, &stdout, &stderr); code != 2 {
+		t.Fatalf("unknown command exit code want 2 got %d", code)
+	}
+	if code := run([]string{"run", "-fact", "missing.json", "missing.grl"}, &stdout, &stderr); code != 1 {
+		t.Fatalf("missing rule file exit code want 1 got %d", code)
+	}
+}
+
+func TestTest(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "discount.grl", discountStatement)
+	writeFile(t, dir, "discount_test.yaml", `rule_file: discount.grl
+cases:
+  - name: large orders get a discount
+    fact: {Amount: 150}
+    expect: {Discount: 10}
+    fired: [DiscountRule]
+`)
+	report := filepath.Join(dir, "report.xml")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"test", "-junit", report, dir}, &stdout, &stderr); code != 0 {
+		t.Fatalf("test exit code want 0 got %d: %s%s", code, stdout.String(), stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "1 suite(s), 1 case(s), 0 failed") {
+		t.Fatalf("test output should summarize the run: %s", stdout.String())
+	}
+	data, err := os.ReadFile(report)
+	if err != nil || !strings.Contains(string(data), `<testcase name="large orders get a discount"`) {
+		t.Fatalf("junit report should contain the case: %s, err %v", data, err)
+	}
+
+	writeFile(t, dir, "failing_test.yaml", `rule_file: discount.grl
+cases:
+  - name: small orders get a discount
+    fact: {Amount: 50}
+    expect: {Discount: 10}
+`)
+	stdout.Reset()
+	if code := run([]string{"test", dir}, &stdout, &stderr); code != 1 {
+		t.Fatalf("failing test exit code want 1 got %d", code)
+	}
+	if !strings.Contains(stdout.String(), "FAIL") {
+		t.Fatalf("test output should report the failure: %s", stdout.String())
+	}
+}
+
+func TestTestCoverage(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "discount.grl", discountStatement)
+	writeFile(t, dir, "discount_test.yaml", `rule_file: discount.grl
+cases:
+  - name: small orders get no discount
+    fact: {Amount: 50, Discount: 0}
+    expect: {Discount: 0}
+`)
+	profile := filepath.Join(dir, "coverage.json")
+	html := filepath.Join(dir, "coverage.html")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"test", "-coverprofile", profile, "-coverhtml", html, dir}, &stdout, &stderr); code != 0 {
+		t.Fatalf("test exit code want 0 got %d: %s%s", code, stdout.String(), stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "DiscountRule") {
+		t.Fatalf("test output should list the never fired rule: %s", stdout.String())
+	}
+	data, err := os.ReadFile(profile)
+	if err != nil || !strings.Contains(string(data), `"DiscountRule"`) {
+		t.Fatalf("coverage profile should contain the rule: %s, err %v", data, err)
+	}
+	if _, err := os.Stat(html); err != nil {
+		t.Fatalf("coverage html should be written: %v", err)
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rules.trace")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create trace: %v", err)
+	}
+	recorder, err := trace.NewWriter(f)
+	if err != nil {
+		t.Fatalf("NewWriter error: %v", err)
+	}
+	for i := 0; i < 200; i++ {
+		_ = recorder.Record(fmt.Sprintf("r%d", i%20))
+	}
+	if err := recorder.Flush(); err != nil {
+		t.Fatalf("Flush error: %v", err)
+	}
+	f.Close()
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"simulate", "-sizes", "5,20", path}, &stdout, &stderr); code != 0 {
+		t.Fatalf("simulate exit code want 0 got %d: %s", code, stderr.String())
+	}
+	out := stdout.String()
+	if !strings.Contains(out, "200 accesses, 20 distinct rules") || !strings.Contains(out, "SIZE 20") {
+		t.Fatalf("simulate output should describe the trace: %s", out)
+	}
+	for _, typ := range []string{"lru", "lfu", "arc", "twoq", "random", "tinylfu", "sieve", "s3fifo"} {
+		if !strings.Contains(out, typ) {
+			t.Fatalf("simulate output should have a row for %s: %s", typ, out)
+		}
+	}
+	// Every rule fits in a cache of 20 entries, so only the first access of each misses
+	if !strings.Contains(out, "90.00%") {
+		t.Fatalf("simulate output should report a 90%% hit ratio at size 20: %s", out)
+	}
+
+	stderr.Reset()
+	if code := run([]string{"simulate", "-sizes", "0", path}, &stdout, &stderr); code != 1 {
+		t.Fatalf("invalid sizes exit code want 1 got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "-sizes must be positive integers") {
+		t.Fatalf("simulate should report the invalid sizes: %s", stderr.String())
+	}
+}
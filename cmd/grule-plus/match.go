@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+func matchCommand(args []string, stdout io.Writer) error {
+	var flags ruleFlags
+	fs := flag.NewFlagSet("match", flag.ContinueOnError)
+	flags.register(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: grule-plus match [flags] <file.grl>")
+		fs.PrintDefaults()
+	}
+	file, err := parseRuleArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	grule, rule, err := loadEngine(file, flags.factName)
+	if err != nil {
+		return err
+	}
+	defer grule.Close()
+
+	raws, err := readFacts(flags.factFile)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "FACT\tRULE\tSALIENCE\tDESCRIPTION")
+	for i, raw := range raws {
+		fact, err := decodeFact(raw)
+		if err != nil {
+			return err
+		}
+		ruleEntries, err := grule.FetchMatching(context.Background(), rule, fact)
+		if err != nil {
+			return fmt.Errorf("match fact %d: %w", i, err)
+		}
+		for _, ruleEntry := range ruleEntries {
+			fmt.Fprintf(tw, "%d\t%s\t%d\t%s\n", i, ruleEntry.RuleName, ruleEntry.Salience, ruleEntry.RuleDescription)
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/hungpdn/grule-plus/engine"
+)
+
+// ruleFlags are the flags shared by the commands evaluating a rule file against facts.
+type ruleFlags struct {
+	factName string
+	factFile string
+}
+
+// register registers the flags on the flag set.
+func (f *ruleFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.factName, "fact-name", "Fact", "name of the fact to be used in rules")
+	fs.StringVar(&f.factFile, "fact", "-", "JSON file holding a fact object or an array of facts, - reads stdin")
+}
+
+// parseRuleArgs parses the arguments of a command taking a single rule file.
+func parseRuleArgs(fs *flag.FlagSet, args []string) (string, error) {
+	if err := fs.Parse(args); err != nil {
+		return "", err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return "", errors.New("exactly one rule file is required")
+	}
+	return fs.Arg(0), nil
+}
+
+// loadEngine adds the rule file to a new engine and returns the engine with the rule name,
+// which is the base name of the file.
+func loadEngine(file, factName string) (engine.IGruleEngine, string, error) {
+	statement, err := os.ReadFile(file)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rule := filepath.Base(file)
+	grule := engine.NewSingleEngine(engine.Config{FactName: factName})
+	if err := grule.AddRule(rule, string(statement), 0); err != nil {
+		grule.Close()
+		return nil, "", fmt.Errorf("compile %s: %w", file, err)
+	}
+	return grule, rule, nil
+}
+
+// readFacts reads a JSON fact object, or an array of fact objects, from the file or stdin.
+func readFacts(file string) ([]json.RawMessage, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var facts []json.RawMessage
+		if err := json.Unmarshal(data, &facts); err != nil {
+			return nil, fmt.Errorf("decode facts: %w", err)
+		}
+		if len(facts) == 0 {
+			return nil, errors.New("no fact given")
+		}
+		return facts, nil
+	}
+	return []json.RawMessage{data}, nil
+}
+
+// decodeFact decodes a raw JSON fact.
+func decodeFact(raw json.RawMessage) (*engine.JSONFact, error) {
+	values := make(map[string]any)
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil, fmt.Errorf("decode fact: %w", err)
+	}
+	return engine.NewJSONFact(values), nil
+}
+
+// writeJSON writes v as indented JSON.
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
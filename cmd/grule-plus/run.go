@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+)
+
+func runCommand(args []string, stdout io.Writer) error {
+	var flags ruleFlags
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	flags.register(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: grule-plus run [flags] <file.grl>")
+		fs.PrintDefaults()
+	}
+	file, err := parseRuleArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	grule, rule, err := loadEngine(file, flags.factName)
+	if err != nil {
+		return err
+	}
+	defer grule.Close()
+
+	raws, err := readFacts(flags.factFile)
+	if err != nil {
+		return err
+	}
+
+	results := make([]map[string]any, 0, len(raws))
+	for i, raw := range raws {
+		fact, err := decodeFact(raw)
+		if err != nil {
+			return err
+		}
+		if err := grule.Execute(context.Background(), rule, fact); err != nil {
+			return fmt.Errorf("execute fact %d: %w", i, err)
+		}
+		results = append(results, fact.Result)
+	}
+
+	if len(results) == 1 {
+		return writeJSON(stdout, results[0])
+	}
+	return writeJSON(stdout, results)
+}
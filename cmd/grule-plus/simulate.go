@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hungpdn/grule-plus/cache/trace"
+	"github.com/hungpdn/grule-plus/engine"
+)
+
+// simulatedTypes are the cache types replayed by the simulate command, in output order.
+var simulatedTypes = []engine.CacheType{
+	engine.LRU, engine.LFU, engine.ARC, engine.TWOQ, engine.RANDOM, engine.TINYLFU, engine.SIEVE, engine.S3FIFO,
+}
+
+func simulateCommand(args []string, stdout io.Writer) error {
+	var sizes string
+	fs := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	fs.StringVar(&sizes, "sizes", "", "comma-separated cache sizes, default 1%, 5%, 10% and 25% of the distinct rules")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: grule-plus simulate [flags] <file.trace>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("exactly one trace file is required")
+	}
+
+	file := fs.Arg(0)
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	keys, err := trace.Read(f)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", file, err)
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("%s has no accesses", file)
+	}
+
+	distinct := trace.Distinct(keys)
+	cacheSizes, err := parseSizes(sizes, distinct)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "%s: %d accesses, %d distinct rules\n\n", file, len(keys), distinct)
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(tw, "TYPE\t")
+	for _, size := range cacheSizes {
+		fmt.Fprintf(tw, "SIZE %d\t", size)
+	}
+	fmt.Fprintln(tw)
+	for _, typ := range simulatedTypes {
+		fmt.Fprintf(tw, "%s\t", typ)
+		for _, size := range cacheSizes {
+			ratio := trace.HitRatio(engine.Config{Type: typ}.GetCacheType(), size, keys)
+			fmt.Fprintf(tw, "%.2f%%\t", 100*ratio)
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+// parseSizes parses the comma-separated cache sizes, or returns sizes relative to the number of
+// distinct rules when none are given.
+func parseSizes(sizes string, distinct int) ([]int, error) {
+	if sizes == "" {
+		return []int{max(1, distinct/100), max(1, distinct/20), max(1, distinct/10), max(1, distinct/4)}, nil
+	}
+
+	parsed := make([]int, 0)
+	for _, field := range strings.Split(sizes, ",") {
+		size, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || size <= 0 {
+			return nil, errors.New("-sizes must be positive integers")
+		}
+		parsed = append(parsed, size)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hungpdn/grule-plus/engine"
+	"github.com/hungpdn/grule-plus/ruletest"
+)
+
+// specSuffixes are the suffixes of the spec files found in directories.
+var specSuffixes = []string{"_test.yaml", "_test.yml", "_test.json"}
+
+func testCommand(args []string, stdout io.Writer) error {
+	var junit, coverProfile, coverHTML string
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.StringVar(&junit, "junit", "", "write a JUnit XML report to the file")
+	fs.StringVar(&coverProfile, "coverprofile", "", "write a JSON rule coverage report to the file")
+	fs.StringVar(&coverHTML, "coverhtml", "", "write an HTML rule coverage report to the file")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: grule-plus test [flags] <spec|dir>...")
+		fmt.Fprintf(fs.Output(), "Directories are searched for files ending with %s.\n", strings.Join(specSuffixes, ", "))
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("no spec given")
+	}
+
+	paths, err := specFiles(fs.Args())
+	if err != nil {
+		return err
+	}
+	specs := make([]*ruletest.Spec, 0, len(paths))
+	for _, path := range paths {
+		spec, err := ruletest.LoadSpec(path)
+		if err != nil {
+			return err
+		}
+		specs = append(specs, spec)
+	}
+
+	results := ruletest.Run(context.Background(), specs)
+	failed := printResults(stdout, results)
+
+	if junit != "" {
+		if err := createFile(junit, func(w io.Writer) error { return ruletest.WriteJUnit(w, results) }); err != nil {
+			return err
+		}
+	}
+
+	coverage := engine.CoverageReport{Rules: make([]engine.RuleCoverage, 0)}
+	for _, result := range results {
+		coverage.Merge(engine.CoverageReport{Rules: result.Coverage})
+	}
+	if coverProfile != "" {
+		if err := createFile(coverProfile, coverage.WriteJSON); err != nil {
+			return err
+		}
+	}
+	if coverHTML != "" {
+		if err := createFile(coverHTML, coverage.WriteHTML); err != nil {
+			return err
+		}
+	}
+	printCoverage(stdout, coverage)
+
+	if failed > 0 {
+		return errSilent
+	}
+	return nil
+}
+
+// printResults prints the results of the suites and returns the number of failed suites.
+func printResults(w io.Writer, results []ruletest.SuiteResult) int {
+	failed, cases, failedCases := 0, 0, 0
+	for _, result := range results {
+		if !result.Passed() {
+			failed++
+		}
+		if result.Err != nil {
+			fmt.Fprintf(w, "ERROR %s: %v\n", result.Name, result.Err)
+			continue
+		}
+		for _, c := range result.Cases {
+			cases++
+			switch {
+			case c.Err != nil:
+				failedCases++
+				fmt.Fprintf(w, "ERROR %s / %s: %v\n", result.Name, c.Name, c.Err)
+			case len(c.Failures) > 0:
+				failedCases++
+				fmt.Fprintf(w, "FAIL  %s / %s\n", result.Name, c.Name)
+				for _, failure := range c.Failures {
+					fmt.Fprintf(w, "        %s\n", failure)
+				}
+			default:
+				fmt.Fprintf(w, "ok    %s / %s (%v)\n", result.Name, c.Name, c.Duration)
+			}
+		}
+	}
+	fmt.Fprintf(w, "%d suite(s), %d case(s), %d failed\n", len(results), cases, failedCases)
+	return failed
+}
+
+// printCoverage prints the rule entries fired by the suites and the ones never fired.
+func printCoverage(w io.Writer, coverage engine.CoverageReport) {
+	entries, fired := 0, 0
+	for _, rc := range coverage.Rules {
+		entries += len(rc.Entries)
+		fired += rc.Fired
+		for _, name := range rc.NeverHit {
+			fmt.Fprintf(w, "never fired: %s %s\n", rc.Rule, name)
+		}
+	}
+	if entries > 0 {
+		fmt.Fprintf(w, "coverage: %d of %d rule entries fired (%.1f%%)\n", fired, entries, float64(fired)*100/float64(entries))
+	}
+}
+
+// createFile creates the file and writes it with the write function.
+func createFile(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// specFiles expands the directories of the arguments into the spec files they contain.
+func specFiles(args []string) ([]string, error) {
+	files := make([]string, 0, len(args))
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+		err = filepath.WalkDir(arg, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			for _, suffix := range specSuffixes {
+				if strings.HasSuffix(path, suffix) {
+					files = append(files, path)
+					break
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,203 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CircuitBreaker quarantines a rule which keeps failing, so it is not executed again and again.
+// After Failures failed executions of a rule within Window, the breaker of the rule opens and Execute
+// fails at once with ErrRuleQuarantined. After Cooldown, the breaker is half-open: one execution probes
+// the rule while the others are still quarantined. A successful probe closes the breaker, a failed one
+// opens it again. A missing rule, a fact which cannot be registered and a canceled context do not
+// count as failures.
+type CircuitBreaker struct {
+	Failures int           // failed executions of a rule opening its breaker, 0 disables the breakers
+	Window   time.Duration // period in which the failures are counted, 0 counts consecutive failures
+	Cooldown time.Duration // time a rule is quarantined before it is probed, 0 means the Window
+	Hook     BreakerHook   `json:"-"` // notified when a breaker changes state, for instance to raise an alert
+}
+
+// BreakerHook is notified when the circuit breaker of a rule changes state. It is called
+// synchronously by Execute and must not block.
+type BreakerHook interface {
+	// BreakerStateChanged is called with the rule and the states of its breaker.
+	BreakerStateChanged(rule string, from, to BreakerState)
+}
+
+// BreakerHookFunc is an adapter to use an ordinary function as a BreakerHook.
+type BreakerHookFunc func(rule string, from, to BreakerState)
+
+// BreakerStateChanged calls f(rule, from, to).
+func (f BreakerHookFunc) BreakerStateChanged(rule string, from, to BreakerState) {
+	f(rule, from, to)
+}
+
+// BreakerState is the state of the circuit breaker of a rule.
+type BreakerState string
+
+// Define the breaker states.
+const (
+	BreakerClosed   BreakerState = "closed"    // the rule is executed
+	BreakerOpen     BreakerState = "open"      // the rule is quarantined
+	BreakerHalfOpen BreakerState = "half-open" // an execution is probing the rule
+)
+
+// ruleBreaker is the circuit breaker of a rule
+type ruleBreaker struct {
+	state    BreakerState
+	failures []time.Time // failures counted in the window, oldest first
+	openedAt time.Time   // when the breaker last opened
+}
+
+// breakers holds the circuit breakers of the rules which failed recently, the other rules are closed.
+// A nil *breakers executes every rule.
+type breakers struct {
+	cfg      CircuitBreaker
+	onChange func(rule string, from, to BreakerState)
+	mu       sync.Mutex
+	rules    map[string]*ruleBreaker
+}
+
+// newBreakers returns the circuit breakers of the rules, nil when they are disabled.
+// onChange is called with the state changes, before CircuitBreaker.Hook.
+func newBreakers(cfg CircuitBreaker, onChange func(rule string, from, to BreakerState)) *breakers {
+	if cfg.Failures <= 0 {
+		return nil
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = cfg.Window
+	}
+	return &breakers{cfg: cfg, onChange: onChange, rules: make(map[string]*ruleBreaker)}
+}
+
+// allow returns ErrRuleQuarantined when the breaker of the rule is open, or half-open with a probe
+// running. The first execution after the cooldown of an open breaker becomes the probe.
+func (b *breakers) allow(rule string) error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	rb, ok := b.rules[rule]
+	if !ok || rb.state == BreakerClosed {
+		b.mu.Unlock()
+		return nil
+	}
+	if rb.state == BreakerOpen && time.Since(rb.openedAt) >= b.cfg.Cooldown {
+		rb.state = BreakerHalfOpen
+		b.mu.Unlock()
+		b.notify(rule, BreakerOpen, BreakerHalfOpen)
+		return nil
+	}
+	b.mu.Unlock()
+	return fmt.Errorf("%w: %s", ErrRuleQuarantined, rule)
+}
+
+// done records the result of an execution of the rule allowed by allow.
+func (b *breakers) done(rule string, err error) {
+	if b == nil {
+		return
+	}
+	failed := breakerFailure(err)
+	now := time.Now()
+
+	b.mu.Lock()
+	rb, ok := b.rules[rule]
+	if !ok {
+		if !failed {
+			b.mu.Unlock()
+			return
+		}
+		rb = &ruleBreaker{state: BreakerClosed}
+		b.rules[rule] = rb
+	}
+
+	from := rb.state
+	switch rb.state {
+	case BreakerClosed:
+		rb.failures = b.window(rb.failures, now)
+		if failed {
+			rb.failures = append(rb.failures, now)
+		} else if b.cfg.Window <= 0 {
+			rb.failures = nil
+		}
+		if len(rb.failures) >= b.cfg.Failures {
+			rb.state, rb.openedAt, rb.failures = BreakerOpen, now, nil
+		} else if len(rb.failures) == 0 {
+			delete(b.rules, rule)
+		}
+	case BreakerHalfOpen:
+		switch {
+		case failed:
+			rb.state, rb.openedAt = BreakerOpen, now
+		case err == nil:
+			delete(b.rules, rule)
+			rb.state = BreakerClosed
+		default:
+			// the probe did not run the rule, the next execution probes it
+			rb.state = BreakerOpen
+		}
+	}
+	to := rb.state
+	b.mu.Unlock()
+
+	if from != to {
+		b.notify(rule, from, to)
+	}
+}
+
+// window drops the failures older than the window
+func (b *breakers) window(failures []time.Time, now time.Time) []time.Time {
+	if b.cfg.Window <= 0 {
+		return failures
+	}
+	i := 0
+	for i < len(failures) && now.Sub(failures[i]) > b.cfg.Window {
+		i++
+	}
+	return failures[i:]
+}
+
+// notify calls the hooks of the state changes
+func (b *breakers) notify(rule string, from, to BreakerState) {
+	if b.onChange != nil {
+		b.onChange(rule, from, to)
+	}
+	if b.cfg.Hook != nil {
+		b.cfg.Hook.BreakerStateChanged(rule, from, to)
+	}
+}
+
+// debug returns the state of the breakers which are not closed or have failures
+func (b *breakers) debug() map[string]any {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	rules := make(map[string]any, len(b.rules))
+	for rule, rb := range b.rules {
+		state := map[string]any{
+			"state":    rb.state,
+			"failures": len(b.window(rb.failures, now)),
+		}
+		if rb.state != BreakerClosed {
+			state["opened_at"] = rb.openedAt
+		}
+		rules[rule] = state
+	}
+	return rules
+}
+
+// breakerFailure reports whether the error of an execution counts as a failure of the rule.
+// The errors of the caller, a missing rule, a fact which cannot be registered or a canceled context,
+// do not.
+func breakerFailure(err error) bool {
+	return err != nil &&
+		!errors.Is(err, ErrRuleNotFound) &&
+		!errors.Is(err, ErrFactRegistration) &&
+		!errors.Is(err, context.Canceled)
+}
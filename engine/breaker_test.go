@@ -0,0 +1,130 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// breakerChanges records the state changes of the breakers
+type breakerChanges struct {
+	mu      sync.Mutex
+	changes []BreakerState
+}
+
+func (c *breakerChanges) BreakerStateChanged(rule string, from, to BreakerState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.changes = append(c.changes, to)
+}
+
+func (c *breakerChanges) states() []BreakerState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]BreakerState(nil), c.changes...)
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	hook := &breakerChanges{}
+	pe := NewPartitionEngine(Config{Partition: 2, CircuitBreaker: CircuitBreaker{
+		Failures: 2,
+		Window:   time.Minute,
+		Cooldown: 50 * time.Millisecond,
+		Hook:     hook,
+	}}, nil)
+	if err := pe.AddRule("loop", loopStatement, 0); err != nil {
+		t.Fatalf("AddRule error: %v", err)
+	}
+	failing, passing := &errorsFact{Amount: 150}, &errorsFact{Amount: 1}
+
+	// A missing rule, an unregistered fact and a canceled context are not failures of the rule
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = pe.Execute(canceled, "loop", failing)
+	_ = pe.Execute(context.Background(), "missing", failing)
+	for i := 0; i < 2; i++ {
+		unregistered := NewJSONFact(map[string]any{"Done": make(chan int)})
+		if err := pe.Execute(context.Background(), "loop", unregistered); !errors.Is(err, ErrFactRegistration) {
+			t.Fatalf("want ErrFactRegistration got %v", err)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if err := pe.Execute(context.Background(), "loop", failing); !errors.Is(err, ErrMaxCycleExceeded) {
+			t.Fatalf("execution %d want ErrMaxCycleExceeded got %v", i, err)
+		}
+	}
+
+	err := pe.Execute(context.Background(), "loop", passing)
+	if !errors.Is(err, ErrRuleQuarantined) || !IsRetryable(err) {
+		t.Fatalf("open breaker want ErrRuleQuarantined got %v", err)
+	}
+	breakers := pe.Debug()["engines"].(map[int]map[string]any)[pe.hash("loop")]["circuit_breakers"].(map[string]any)
+	if state := breakers["loop"].(map[string]any)["state"]; state != BreakerOpen {
+		t.Fatalf("Debug want open breaker got %v", breakers)
+	}
+
+	// A failed probe opens the breaker again, a successful one closes it
+	time.Sleep(60 * time.Millisecond)
+	if err := pe.Execute(context.Background(), "loop", failing); !errors.Is(err, ErrMaxCycleExceeded) {
+		t.Fatalf("probe want ErrMaxCycleExceeded got %v", err)
+	}
+	if err := pe.Execute(context.Background(), "loop", passing); !errors.Is(err, ErrRuleQuarantined) {
+		t.Fatalf("failed probe want ErrRuleQuarantined got %v", err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if err := pe.Execute(context.Background(), "loop", passing); err != nil {
+		t.Fatalf("probe error: %v", err)
+	}
+	if err := pe.Execute(context.Background(), "loop", failing); !errors.Is(err, ErrMaxCycleExceeded) {
+		t.Fatalf("closed breaker want ErrMaxCycleExceeded got %v", err)
+	}
+
+	want := []BreakerState{BreakerOpen, BreakerHalfOpen, BreakerOpen, BreakerHalfOpen, BreakerClosed}
+	got := hook.states()
+	if len(got) != len(want) {
+		t.Fatalf("want state changes %v got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("want state changes %v got %v", want, got)
+		}
+	}
+}
+
+func TestCircuitBreakerWindow(t *testing.T) {
+	b := newBreakers(CircuitBreaker{Failures: 2, Window: 20 * time.Millisecond}, nil)
+	failure := errors.New("failed")
+
+	b.done("r1", failure)
+	time.Sleep(30 * time.Millisecond)
+	b.done("r1", failure)
+	if err := b.allow("r1"); err != nil {
+		t.Fatalf("failures outside the window should not open the breaker: %v", err)
+	}
+	b.done("r1", failure)
+	if err := b.allow("r1"); !errors.Is(err, ErrRuleQuarantined) {
+		t.Fatalf("want ErrRuleQuarantined got %v", err)
+	}
+
+	// Without a window, a success resets the consecutive failures
+	b = newBreakers(CircuitBreaker{Failures: 2, Cooldown: time.Minute}, nil)
+	b.done("r1", failure)
+	b.done("r1", nil)
+	b.done("r1", failure)
+	if err := b.allow("r1"); err != nil {
+		t.Fatalf("failures which are not consecutive should not open the breaker: %v", err)
+	}
+	if len(b.debug()) != 1 {
+		t.Fatalf("want the breaker of r1 in debug got %v", b.debug())
+	}
+	b.done("r1", nil)
+	if len(b.debug()) != 0 {
+		t.Fatalf("closed breakers without failures should be dropped, got %v", b.debug())
+	}
+
+	if newBreakers(CircuitBreaker{}, nil) != nil || (*breakers)(nil).allow("r1") != nil {
+		t.Fatalf("breakers should be disabled without Failures")
+	}
+}
@@ -0,0 +1,176 @@
+package engine
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// CoverageReport holds the coverage of the rules recorded by engines in coverage mode.
+type CoverageReport struct {
+	Rules []RuleCoverage `json:"rules"`
+}
+
+// RuleCoverage holds the coverage of the rule entries of a rule.
+type RuleCoverage struct {
+	Rule       string          `json:"rule"`
+	Source     string          `json:"source"`
+	Entries    []EntryCoverage `json:"entries"`
+	Fired      int             `json:"fired"`     // number of entries fired at least once
+	NeverHit   []string        `json:"never_hit"` // names of the entries never fired
+	Executions uint64          `json:"executions"`
+}
+
+// EntryCoverage holds the counters of a rule entry.
+type EntryCoverage struct {
+	Name      string `json:"name"`
+	StartLine int    `json:"start_line"` // first line of the entry in the source, 0 if unknown
+	EndLine   int    `json:"end_line"`   // last line of the entry in the source, 0 if unknown
+	Evaluated uint64 `json:"evaluated"`  // times the when scope was evaluated
+	Matched   uint64 `json:"matched"`    // times the when scope matched
+	Fired     uint64 `json:"fired"`      // times the then scope was executed
+}
+
+// Merge appends the rules of the other report.
+func (r *CoverageReport) Merge(other CoverageReport) {
+	r.Rules = append(r.Rules, other.Rules...)
+	sort.Slice(r.Rules, func(i, j int) bool { return r.Rules[i].Rule < r.Rules[j].Rule })
+}
+
+// coverage records the coverage of the rules of an engine. It implements RuleListener.
+type coverage struct {
+	mu    sync.Mutex
+	rules map[string]*ruleCoverage
+}
+
+// ruleCoverage holds the recorded counters of a rule.
+type ruleCoverage struct {
+	source     string
+	entries    map[string]*EntryCoverage
+	executions uint64
+}
+
+func newCoverage() *coverage {
+	return &coverage{rules: make(map[string]*ruleCoverage)}
+}
+
+// register resets the coverage of the rule with the entries of its knowledge library.
+func (c *coverage) register(rule, statement string, library *ast.KnowledgeLibrary) {
+	rc := &ruleCoverage{
+		source:  statement,
+		entries: make(map[string]*EntryCoverage),
+	}
+	if kb := library.GetKnowledgeBase(LibraryName, LibraryVersion); kb != nil {
+		for name := range kb.RuleEntries {
+			rc.entries[name] = &EntryCoverage{Name: name}
+		}
+	}
+	locateEntries(statement, rc.entries)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rules[rule] = rc
+}
+
+// RuleEvaluated counts the evaluations and matches of the entry.
+func (c *coverage) RuleEvaluated(rule string, entry *ast.RuleEntry, matched bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ec := c.entry(rule, entry.RuleName); ec != nil {
+		ec.Evaluated++
+		if matched {
+			ec.Matched++
+		}
+	}
+}
+
+// RuleExecuted counts the executions of the entry.
+func (c *coverage) RuleExecuted(rule string, entry *ast.RuleEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ec := c.entry(rule, entry.RuleName); ec != nil {
+		ec.Fired++
+	}
+}
+
+// executed counts an execution of the rule.
+func (c *coverage) executed(rule string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if rc, ok := c.rules[rule]; ok {
+		rc.executions++
+	}
+}
+
+// Note: must use with Mutex
+func (c *coverage) entry(rule, name string) *EntryCoverage {
+	rc, ok := c.rules[rule]
+	if !ok {
+		return nil
+	}
+	return rc.entries[name]
+}
+
+// report returns a snapshot of the recorded coverage.
+func (c *coverage) report() CoverageReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	report := CoverageReport{Rules: make([]RuleCoverage, 0, len(c.rules))}
+	for rule, rc := range c.rules {
+		r := RuleCoverage{
+			Rule:       rule,
+			Source:     rc.source,
+			Entries:    make([]EntryCoverage, 0, len(rc.entries)),
+			NeverHit:   make([]string, 0),
+			Executions: rc.executions,
+		}
+		for _, ec := range rc.entries {
+			r.Entries = append(r.Entries, *ec)
+		}
+		sort.Slice(r.Entries, func(i, j int) bool {
+			if r.Entries[i].StartLine != r.Entries[j].StartLine {
+				return r.Entries[i].StartLine < r.Entries[j].StartLine
+			}
+			return r.Entries[i].Name < r.Entries[j].Name
+		})
+		for _, ec := range r.Entries {
+			if ec.Fired > 0 {
+				r.Fired++
+			} else {
+				r.NeverHit = append(r.NeverHit, ec.Name)
+			}
+		}
+		report.Rules = append(report.Rules, r)
+	}
+	sort.Slice(report.Rules, func(i, j int) bool { return report.Rules[i].Rule < report.Rules[j].Rule })
+
+	return report
+}
+
+// ruleDeclRegexp matches the declaration of a rule entry in a GRL statement.
+var ruleDeclRegexp = regexp.MustCompile(`(?m)^[ \t]*rule[ \t]+([A-Za-z_][A-Za-z0-9_]*)`)
+
+// locateEntries sets the line range of each entry in the statement. An entry ends
+// on the line before the next declaration, or on the last line of the statement.
+func locateEntries(statement string, entries map[string]*EntryCoverage) {
+	lines := strings.Count(statement, "\n") + 1
+	matches := ruleDeclRegexp.FindAllStringSubmatchIndex(statement, -1)
+	for i, m := range matches {
+		ec, ok := entries[statement[m[2]:m[3]]]
+		if !ok {
+			continue
+		}
+		ec.StartLine = strings.Count(statement[:m[0]], "\n") + 1
+		ec.EndLine = lines
+		if i+1 < len(matches) {
+			ec.EndLine = strings.Count(statement[:matches[i+1][0]], "\n")
+		}
+	}
+}
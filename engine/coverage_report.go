@@ -0,0 +1,93 @@
+package engine
+
+import (
+	"encoding/json"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// WriteJSON writes the coverage report as JSON.
+func (r CoverageReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// sourceLine is a line of a rule source in the HTML report.
+type sourceLine struct {
+	Number int
+	Text   string
+	Class  string // fired, never or empty outside of any entry
+}
+
+// htmlRule is a rule in the HTML report.
+type htmlRule struct {
+	RuleCoverage
+	Lines []sourceLine
+}
+
+// WriteHTML writes the coverage report as an HTML page, highlighting
+// the sources of the fired and never fired rule entries.
+func (r CoverageReport) WriteHTML(w io.Writer) error {
+	rules := make([]htmlRule, 0, len(r.Rules))
+	for _, rule := range r.Rules {
+		rules = append(rules, htmlRule{RuleCoverage: rule, Lines: sourceLines(rule)})
+	}
+	return coverageTemplate.Execute(w, rules)
+}
+
+// sourceLines splits the source of the rule into lines classified by the coverage of their entry.
+func sourceLines(rule RuleCoverage) []sourceLine {
+	texts := strings.Split(rule.Source, "\n")
+	lines := make([]sourceLine, len(texts))
+	for i, text := range texts {
+		lines[i] = sourceLine{Number: i + 1, Text: text}
+	}
+	for _, entry := range rule.Entries {
+		if entry.StartLine == 0 {
+			continue
+		}
+		class := "never"
+		if entry.Fired > 0 {
+			class = "fired"
+		}
+		for n := entry.StartLine; n <= entry.EndLine && n <= len(lines); n++ {
+			lines[n-1].Class = class
+		}
+	}
+	return lines
+}
+
+var coverageTemplate = template.Must(template.New("coverage").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>grule-plus rule coverage</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 0.2em 0.6em; text-align: left; }
+td.count { text-align: right; }
+tr.never td { background: #fdd; }
+pre { border: 1px solid #ccc; padding: 0.5em 0; }
+pre span { display: block; padding: 0 0.5em; }
+pre span.fired { background: #dfd; }
+pre span.never { background: #fdd; }
+pre span i { color: #888; font-style: normal; display: inline-block; width: 3em; }
+</style>
+</head>
+<body>
+<h1>Rule coverage</h1>
+{{range .}}
+<h2>{{.Rule}}</h2>
+<p>{{.Fired}} of {{len .Entries}} rule entries fired in {{.Executions}} executions.</p>
+<table>
+<tr><th>Entry</th><th>Lines</th><th>Evaluated</th><th>Matched</th><th>Fired</th></tr>
+{{range .Entries}}<tr{{if eq .Fired 0}} class="never"{{end}}><td>{{.Name}}</td><td>{{.StartLine}}-{{.EndLine}}</td><td class="count">{{.Evaluated}}</td><td class="count">{{.Matched}}</td><td class="count">{{.Fired}}</td></tr>
+{{end}}</table>
+<pre>{{range .Lines}}<span{{if .Class}} class="{{.Class}}"{{end}}><i>{{.Number}}</i>{{.Text}}</span>{{end}}</pre>
+{{end}}
+</body>
+</html>
+`))
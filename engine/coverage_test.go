@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+const coverageStatement = `rule DiscountRule "Apply discount" salience 10 {
+	when
+		DiscountFact.Amount > 100
+	then
+		DiscountFact.Discount = 10;
+		Retract("DiscountRule");
+}
+
+rule NeverRule "Never fires" {
+	when
+		DiscountFact.Amount < 0
+	then
+		DiscountFact.Discount = 0;
+		Retract("NeverRule");
+}`
+
+type coverageFact struct {
+	Amount   int
+	Discount int
+}
+
+func TestCoverage(t *testing.T) {
+	se := NewSingleEngine(Config{FactName: "DiscountFact", Coverage: true})
+	if err := se.AddRule("r1", coverageStatement, 0); err != nil {
+		t.Fatalf("AddRule error: %v", err)
+	}
+	for _, amount := range []int{150, 50} {
+		if err := se.Execute(context.Background(), "r1", &coverageFact{Amount: amount}); err != nil {
+			t.Fatalf("Execute error: %v", err)
+		}
+	}
+
+	report := se.Coverage()
+	if len(report.Rules) != 1 {
+		t.Fatalf("want 1 rule got %d", len(report.Rules))
+	}
+	rc := report.Rules[0]
+	if rc.Rule != "r1" || rc.Executions != 2 || rc.Fired != 1 {
+		t.Fatalf("unexpected rule coverage %+v", rc)
+	}
+	if len(rc.NeverHit) != 1 || rc.NeverHit[0] != "NeverRule" {
+		t.Fatalf("NeverHit want [NeverRule] got %v", rc.NeverHit)
+	}
+
+	discount, never := rc.Entries[0], rc.Entries[1]
+	if discount.Name != "DiscountRule" || discount.StartLine != 1 || discount.EndLine != 8 {
+		t.Fatalf("unexpected DiscountRule location %+v", discount)
+	}
+	if discount.Evaluated != 2 || discount.Matched != 1 || discount.Fired != 1 {
+		t.Fatalf("unexpected DiscountRule counters %+v", discount)
+	}
+	if never.Name != "NeverRule" || never.StartLine != 9 || never.Evaluated == 0 || never.Matched != 0 || never.Fired != 0 {
+		t.Fatalf("unexpected NeverRule coverage %+v", never)
+	}
+}
+
+func TestCoverageDisabled(t *testing.T) {
+	se := NewSingleEngine(Config{FactName: "DiscountFact"})
+	if err := se.AddRule("r1", coverageStatement, 0); err != nil {
+		t.Fatalf("AddRule error: %v", err)
+	}
+	if report := se.Coverage(); len(report.Rules) != 0 {
+		t.Fatalf("coverage should be empty when disabled, got %+v", report)
+	}
+}
+
+func TestCoverageReportWriters(t *testing.T) {
+	se := NewSingleEngine(Config{FactName: "DiscountFact", Coverage: true})
+	if err := se.AddRule("r1", coverageStatement, 0); err != nil {
+		t.Fatalf("AddRule error: %v", err)
+	}
+	if err := se.Execute(context.Background(), "r1", &coverageFact{Amount: 150}); err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+	report := se.Coverage()
+
+	var buf bytes.Buffer
+	if err := report.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON error: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"never_hit": [`) {
+		t.Fatalf("json report should list the never fired entries: %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := report.WriteHTML(&buf); err != nil {
+		t.Fatalf("WriteHTML error: %v", err)
+	}
+	html := buf.String()
+	if !strings.Contains(html, `<span class="never"><i>9</i>rule NeverRule`) {
+		t.Fatalf("html report should highlight the never fired entry: %s", html)
+	}
+	if !strings.Contains(html, `<span class="fired"><i>1</i>rule DiscountRule`) {
+		t.Fatalf("html report should highlight the fired entry: %s", html)
+	}
+}
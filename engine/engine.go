@@ -3,7 +3,8 @@ package engine
 import (
 	"context"
 
-	"github.com/hungpdn/grule-plus/internal/cache"
+	"github.com/hungpdn/grule-plus/cache"
+	"github.com/hungpdn/grule-plus/cache/trace"
 	"github.com/hyperjumptech/grule-rule-engine/ast"
 )
 
@@ -19,6 +20,14 @@ type IGruleEngine interface {
 	BuildRule(rule, statement string, duration int64) error
 	// ContainsRule checks if a rule exists in the engine.
 	ContainsRule(rule string) bool
+	// RemoveRule removes a rule from the engine.
+	RemoveRule(rule string)
+	// ListRules returns the sorted names of all rules in the engine.
+	ListRules() []string
+	// Coverage returns the rule coverage recorded in coverage mode.
+	Coverage() CoverageReport
+	// OnRuleEvicted registers a hook notified when a rule leaves the cache of the engine.
+	OnRuleEvicted(hook RuleEvictedHook)
 	// Debug provides internal state information for debugging purposes.
 	Debug() map[string]any
 	// Close cleans up resources used by the engine.
@@ -27,23 +36,61 @@ type IGruleEngine interface {
 
 // Config holds the configuration for the Grule engine.
 type Config struct {
-	Type            CacheType // type of cache: lru, lfu, arc, random
-	Size            int       // size of the cache, 0 means unlimited
-	CleanupInterval int       // cleanup interval in seconds, 0 means no cleanup
-	TTL             int       // time-to-live in seconds, 0 means no expiration
-	Partition       int       // number of partitions for the engine
-	FactName        string    // name of the fact to be used in rules, default is "Fact"
+	Type            CacheType      // type of cache: lru, lfu, arc, twoq, random, tinylfu, sieve, s3fifo
+	Size            int            // size of the cache, 0 means unlimited
+	CleanupInterval int            // cleanup interval in seconds, 0 means no cleanup
+	TTL             int            // time-to-live in seconds, 0 means no expiration
+	Partition       int            // number of partitions for the engine
+	FactName        string         // name of the fact to be used in rules, default is "Fact"
+	Coverage        bool           // record how often each rule entry is evaluated, matched and fired
+	MaxCost         int64          // memory budget of each partition in bytes of GRL statements, 0 means no limit
+	Shards          int            // number of lock-striped segments of each partition cache, 0 or 1 means a single lock
+	ReadBuffer      int            // with Shards, number of rule hits buffered per segment before the cache policy records them
+	ReadSample      int            // with Shards, the cache policy records one of every ReadSample rule hits
+	SlidingTTL      bool           // each execution restarts the TTL of the rule, so rules in use do not expire
+	RefreshAhead    float64        // fraction of the TTL after which an executed rule is reloaded from Loader, 0 means no refresh
+	Loader          RuleLoader     `json:"-"` // loads the statements of the rules refreshed ahead of their expiration
+	Trace           *trace.Writer  `json:"-"` // records the rules executed, to replay them with grule-plus simulate
+	Logger          Logger         `json:"-"` // receives the logs of the engine, nil means the package logger
+	FactRedaction   FactRedaction  // how facts are written in the logs: tagged (default), type, hash or none
+	LogSampling     LogSampling    // limits the logs written for each message and rule in every interval
+	CircuitBreaker  CircuitBreaker // quarantines the rules failing too often, disabled by default
+}
+
+// RuleEvictedHook is notified when a rule leaves the cache of the engine, with the cache event:
+// common.ExpirationEvent, common.EvictionEvent, common.DeleteEvent (RemoveRule) or common.ClearEvent (Close).
+type RuleEvictedHook struct {
+	Func   func(rule string, event int) // called with the name of the rule and the cache event
+	Events []int                        // cache events delivered to Func, all events when empty
+	Async  bool                         // call Func from a new goroutine, a synchronous Func must not call the engine
+}
+
+// RuleLoader loads the GRL statement of a rule, to refresh the rule before it expires.
+type RuleLoader interface {
+	// LoadRule returns the current statement of the rule.
+	LoadRule(ctx context.Context, rule string) (statement string, err error)
+}
+
+// RuleLoaderFunc is an adapter to use an ordinary function as a RuleLoader.
+type RuleLoaderFunc func(ctx context.Context, rule string) (statement string, err error)
+
+// LoadRule calls f(ctx, rule).
+func (f RuleLoaderFunc) LoadRule(ctx context.Context, rule string) (string, error) {
+	return f(ctx, rule)
 }
 
 // CacheType represents the type of cache to be used.
 type CacheType string
 
 const (
-	LRU    CacheType = "lru"
-	LFU    CacheType = "lfu"
-	ARC    CacheType = "arc"
-	TWOQ   CacheType = "twoq"
-	RANDOM CacheType = "random"
+	LRU     CacheType = "lru"
+	LFU     CacheType = "lfu"
+	ARC     CacheType = "arc"
+	TWOQ    CacheType = "twoq"
+	RANDOM  CacheType = "random"
+	TINYLFU CacheType = "tinylfu"
+	SIEVE   CacheType = "sieve"
+	S3FIFO  CacheType = "s3fifo"
 )
 
 // GetCacheType returns the corresponding cache type constant.
@@ -59,6 +106,12 @@ func (c Config) GetCacheType() int {
 		return cache.TWOQ
 	case RANDOM:
 		return cache.RANDOM
+	case TINYLFU:
+		return cache.TINYLFU
+	case SIEVE:
+		return cache.SIEVE
+	case S3FIFO:
+		return cache.S3FIFO
 	default:
 		return cache.LRU
 	}
@@ -0,0 +1,26 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/hungpdn/grule-plus/cache"
+)
+
+func TestGetCacheType(t *testing.T) {
+	tests := map[CacheType]int{
+		LRU:     cache.LRU,
+		LFU:     cache.LFU,
+		ARC:     cache.ARC,
+		TWOQ:    cache.TWOQ,
+		RANDOM:  cache.RANDOM,
+		TINYLFU: cache.TINYLFU,
+		SIEVE:   cache.SIEVE,
+		S3FIFO:  cache.S3FIFO,
+		"":      cache.LRU,
+	}
+	for typ, want := range tests {
+		if got := (Config{Type: typ}).GetCacheType(); got != want {
+			t.Fatalf("GetCacheType(%q) want %d got %d", typ, want, got)
+		}
+	}
+}
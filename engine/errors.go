@@ -0,0 +1,162 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/hyperjumptech/grule-rule-engine/pkg"
+)
+
+// Errors returned by the engines, to be checked with errors.Is. The errors of a rule or a fact are
+// permanent, retrying fails the same way until the rule or the fact changes. IsRetryable reports the
+// errors which may not happen again.
+var (
+	// ErrRuleNotFound is returned by Execute and FetchMatching for a rule which is not loaded.
+	ErrRuleNotFound = errors.New("rule not found")
+	// ErrRuleCompile is returned by AddRule and BuildRule for a statement which does not compile,
+	// as a *RuleCompileError.
+	ErrRuleCompile = errors.New("rule compile error")
+	// ErrFactRegistration is returned by Execute and FetchMatching for a fact which cannot be added
+	// to the data context of the rule.
+	ErrFactRegistration = errors.New("fact registration error")
+	// ErrExecutionTimeout is returned by Execute when the deadline of its context is exceeded.
+	// It also matches context.DeadlineExceeded.
+	ErrExecutionTimeout = errors.New("execution timeout")
+	// ErrMaxCycleExceeded is returned by Execute when the rules are still selected after the maximum
+	// number of cycles, usually rules whose actions do not change the fact they match.
+	ErrMaxCycleExceeded = errors.New("max cycle exceeded")
+	// ErrPanic is returned when a rule panics while it is compiled, executed or matched,
+	// as a *PanicError.
+	ErrPanic = errors.New("rule panic")
+	// ErrRuleQuarantined is returned by Execute for a rule whose circuit breaker is open,
+	// see CircuitBreaker.
+	ErrRuleQuarantined = errors.New("rule quarantined")
+	// ErrRateLimited is returned by Execute for a rule over its rate limit, see RateLimiter.
+	ErrRateLimited = errors.New("rate limited")
+)
+
+// IsRetryable reports whether the operation failing with err may succeed if it is tried again:
+// the execution timed out, its context was canceled, the rule is quarantined until its breaker
+// is probed or it is over its rate limit. The other errors are permanent.
+func IsRetryable(err error) bool {
+	return errors.Is(err, ErrExecutionTimeout) ||
+		errors.Is(err, ErrRuleQuarantined) ||
+		errors.Is(err, ErrRateLimited) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, context.Canceled)
+}
+
+// SyntaxError is an error at a position of a GRL statement.
+type SyntaxError struct {
+	Line    int    // line of the error, from 1
+	Column  int    // column of the error, from 0
+	Message string // message of the parser
+}
+
+// String returns the position and the message of the error.
+func (e SyntaxError) String() string {
+	return fmt.Sprintf("%d:%d %s", e.Line, e.Column, e.Message)
+}
+
+// RuleCompileError is the error of a statement which does not compile. It matches ErrRuleCompile.
+type RuleCompileError struct {
+	Rule   string        // name of the rule, empty for CompileRule
+	Line   int           // line of the first syntax error, 0 when the statement has none
+	Column int           // column of the first syntax error
+	Syntax []SyntaxError // syntax errors of the statement, in order
+	Err    error         // error of grule
+}
+
+// Error returns the rule and its first syntax error, or the error of grule.
+func (e *RuleCompileError) Error() string {
+	msg := "compile rule"
+	if e.Rule != "" {
+		msg += " " + e.Rule
+	}
+	switch len(e.Syntax) {
+	case 0:
+		return fmt.Sprintf("%s: %v", msg, e.Err)
+	case 1:
+		return fmt.Sprintf("%s: %v", msg, e.Syntax[0])
+	default:
+		return fmt.Sprintf("%s: %v (and %d more errors)", msg, e.Syntax[0], len(e.Syntax)-1)
+	}
+}
+
+// Is reports whether target is ErrRuleCompile.
+func (e *RuleCompileError) Is(target error) bool {
+	return target == ErrRuleCompile
+}
+
+// Unwrap returns the error of grule.
+func (e *RuleCompileError) Unwrap() error {
+	return e.Err
+}
+
+// PanicError is a panic recovered while a rule was compiled, executed or matched, for instance in a
+// method of the fact called by the rule or in a RuleListener. It matches ErrPanic.
+type PanicError struct {
+	Rule      string // name of the rule
+	Partition int    // partition of the rule in a partition engine, 0 in a single engine
+	Value     any    // value passed to panic
+	Stack     []byte // stack trace of the panic
+}
+
+// Error returns the rule, its partition and the value of the panic.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic in rule %s of partition %d: %v", e.Rule, e.Partition, e.Value)
+}
+
+// Is reports whether target is ErrPanic.
+func (e *PanicError) Is(target error) bool {
+	return target == ErrPanic
+}
+
+// Unwrap returns the value of the panic when it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// syntaxErrorRegexp matches the syntax errors reported by grule.
+var syntaxErrorRegexp = regexp.MustCompile(`^grl error on (\d+):(\d+) (.*)$`)
+
+// newRuleCompileError converts a build error of grule into a RuleCompileError
+func newRuleCompileError(err error) *RuleCompileError {
+	ce := &RuleCompileError{Err: err}
+
+	var reporter *pkg.GruleErrorReporter
+	if !errors.As(err, &reporter) {
+		return ce
+	}
+	for _, e := range reporter.Errors {
+		s := SyntaxError{Message: e.Error()}
+		if m := syntaxErrorRegexp.FindStringSubmatch(e.Error()); m != nil {
+			s.Line, _ = strconv.Atoi(m[1])
+			s.Column, _ = strconv.Atoi(m[2])
+			s.Message = m[3]
+		}
+		ce.Syntax = append(ce.Syntax, s)
+	}
+	if len(ce.Syntax) > 0 {
+		ce.Line, ce.Column = ce.Syntax[0].Line, ce.Syntax[0].Column
+	}
+	return ce
+}
+
+// executionError converts an error of the grule engine into the errors of the package.
+// Grule returns the error of the context, and a plain error after the maximum number of cycles,
+// which the engine detects by counting the cycles.
+func executionError(err error, maxCycleExceeded bool) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("%w: %w", ErrExecutionTimeout, err)
+	case maxCycleExceeded:
+		return fmt.Errorf("%w: %w", ErrMaxCycleExceeded, err)
+	default:
+		return err
+	}
+}
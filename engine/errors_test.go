@@ -0,0 +1,203 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+const loopStatement = `rule Loop "Never retracted" salience 10 {
+				when
+					Fact.Amount > 100
+				then
+					Fact.Amount = Fact.Amount; }
+				`
+
+type errorsFact struct {
+	Amount int
+}
+
+func TestRuleCompileError(t *testing.T) {
+	_, err := CompileRule("rule Broken \"broken\" {\n  when\n    Fact.Amount >\n  then\n    Retract(\"Broken\"); }")
+	var ce *RuleCompileError
+	if !errors.As(err, &ce) || !errors.Is(err, ErrRuleCompile) {
+		t.Fatalf("want *RuleCompileError matching ErrRuleCompile got %v", err)
+	}
+	if ce.Line != 4 || ce.Column == 0 || len(ce.Syntax) == 0 || ce.Syntax[0].Message == "" {
+		t.Fatalf("want the position of the first syntax error got %+v", ce)
+	}
+
+	for _, eng := range []IGruleEngine{NewSingleEngine(Config{}), NewPartitionEngine(Config{Partition: 2}, nil)} {
+		err := eng.AddRule("broken", "rule Broken {", 0)
+		if !errors.As(err, &ce) || ce.Rule != "broken" || ce.Line != 1 {
+			t.Fatalf("AddRule want *RuleCompileError of rule broken got %#v", err)
+		}
+		if err.Error() == "" || IsRetryable(err) {
+			t.Fatalf("compile error should be permanent: %v", err)
+		}
+	}
+}
+
+func TestExecuteErrors(t *testing.T) {
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, eng := range []IGruleEngine{NewSingleEngine(Config{}), NewPartitionEngine(Config{Partition: 2}, nil)} {
+		if err := eng.AddRule("loop", loopStatement, 0); err != nil {
+			t.Fatalf("AddRule error: %v", err)
+		}
+
+		tests := []struct {
+			name      string
+			ctx       context.Context
+			rule      string
+			fact      any
+			want      error
+			retryable bool
+		}{
+			{"missing rule", context.Background(), "missing", &errorsFact{}, ErrRuleNotFound, false},
+			{"unregistered fact", context.Background(), "loop", NewJSONFact(map[string]any{"Done": make(chan int)}), ErrFactRegistration, false},
+			{"max cycle", context.Background(), "loop", &errorsFact{Amount: 150}, ErrMaxCycleExceeded, false},
+			{"timeout", expired, "loop", &errorsFact{Amount: 150}, ErrExecutionTimeout, true},
+			{"canceled", canceled, "loop", &errorsFact{Amount: 150}, context.Canceled, true},
+		}
+		for _, tc := range tests {
+			err := eng.Execute(tc.ctx, tc.rule, tc.fact)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("%s: want %v got %v", tc.name, tc.want, err)
+			}
+			if IsRetryable(err) != tc.retryable {
+				t.Fatalf("%s: want retryable %v got %v", tc.name, tc.retryable, err)
+			}
+		}
+
+		_, err := eng.FetchMatching(context.Background(), "missing", &errorsFact{})
+		if !errors.Is(err, ErrRuleNotFound) {
+			t.Fatalf("FetchMatching want ErrRuleNotFound got %v", err)
+		}
+	}
+
+	err := NewSingleEngine(Config{}).Execute(expired, "missing", &errorsFact{})
+	if errors.Is(err, ErrExecutionTimeout) {
+		t.Fatalf("a missing rule should not time out: %v", err)
+	}
+	if !errors.Is(executionError(context.DeadlineExceeded, false), context.DeadlineExceeded) {
+		t.Fatalf("timeout should match context.DeadlineExceeded")
+	}
+}
+
+// cycleFact is incremented once per cycle by cycleStatement
+type cycleFact struct {
+	Count int
+}
+
+// Fail panics with the message, which grule returns as an error of the rule
+func (f *cycleFact) Fail(msg string) {
+	panic(msg)
+}
+
+const cycleStatement = `rule Count "Count to 3" {
+				when
+					Fact.Count < 3
+				then
+					Fact.Count = Fact.Count + 1; }
+				rule Fail "Fail with a message" {
+				when
+					Fact.Count == 10
+				then
+					Fact.Fail("adjust GruleEngine.MaxCycle"); }
+				`
+
+func TestMaxCycleExceeded(t *testing.T) {
+	se := NewSingleEngine(Config{})
+	if err := se.AddRule("count", cycleStatement, 0); err != nil {
+		t.Fatalf("AddRule error: %v", err)
+	}
+
+	// A rule entry selected in exactly MaxCycle cycles completes, one more cycle exceeds the maximum
+	se.engine.MaxCycle = 3
+	fact := &cycleFact{}
+	if err := se.Execute(context.Background(), "count", fact); err != nil || fact.Count != 3 {
+		t.Fatalf("want 3 cycles got %d, err %v", fact.Count, err)
+	}
+	se.engine.MaxCycle = 2
+	if err := se.Execute(context.Background(), "count", &cycleFact{}); !errors.Is(err, ErrMaxCycleExceeded) {
+		t.Fatalf("want ErrMaxCycleExceeded got %v", err)
+	}
+
+	// The other errors are not max cycle errors, whatever their message
+	se.engine.MaxCycle = 10
+	err := se.Execute(context.Background(), "count", &cycleFact{Count: 10})
+	if err == nil || errors.Is(err, ErrMaxCycleExceeded) || !strings.Contains(err.Error(), "GruleEngine.MaxCycle") {
+		t.Fatalf("want the error of the rule got %v", err)
+	}
+}
+
+// panicListener panics when a rule entry is evaluated
+type panicListener struct{}
+
+func (panicListener) RuleEvaluated(rule string, entry *ast.RuleEntry, matched bool) {
+	panic(errors.New("listener failed"))
+}
+func (panicListener) RuleExecuted(rule string, entry *ast.RuleEntry) {}
+
+// panicMarshaler panics when a JSON fact containing it is added to the data context
+type panicMarshaler struct{}
+
+func (panicMarshaler) MarshalJSON() ([]byte, error) {
+	panic("marshal failed")
+}
+
+func TestPanicIsolation(t *testing.T) {
+	l := &recordingLogger{}
+	pe := NewPartitionEngine(Config{Partition: 2, Logger: l}, nil)
+	if err := pe.AddRule("loop", loopStatement, 0); err != nil {
+		t.Fatalf("AddRule error: %v", err)
+	}
+	partition := pe.hash("loop")
+
+	ctx := WithRuleListener(context.Background(), panicListener{})
+	err := pe.Execute(ctx, "loop", &errorsFact{Amount: 150})
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) || !errors.Is(err, ErrPanic) {
+		t.Fatalf("Execute want *PanicError got %v", err)
+	}
+	if panicErr.Rule != "loop" || panicErr.Partition != partition || len(panicErr.Stack) == 0 {
+		t.Fatalf("want the rule, partition %d and stack got %+v", partition, panicErr)
+	}
+	if err.Error() != fmt.Sprintf("panic in rule loop of partition %d: listener failed", partition) ||
+		errors.Unwrap(err).Error() != "listener failed" || IsRetryable(err) {
+		t.Fatalf("unexpected panic error %v", err)
+	}
+
+	fact := NewJSONFact(map[string]any{"Amount": panicMarshaler{}})
+	if _, err := pe.FetchMatching(context.Background(), "loop", fact); !errors.Is(err, ErrPanic) {
+		t.Fatalf("FetchMatching want ErrPanic got %v", err)
+	}
+	if err := pe.Execute(context.Background(), "loop", fact); !errors.Is(err, ErrPanic) {
+		t.Fatalf("Execute want ErrPanic got %v", err)
+	}
+
+	// The engine still works after the panics
+	if err := pe.Execute(context.Background(), "loop", &errorsFact{Amount: 1}); err != nil {
+		t.Fatalf("Execute after a panic error: %v", err)
+	}
+	if got := pe.Debug()["panics"]; got != int64(3) {
+		t.Fatalf("want 3 panics in the stats got %v", got)
+	}
+	if got := pe.engines[partition].Debug()["panics"]; got != int64(3) {
+		t.Fatalf("want 3 panics in the partition stats got %v", got)
+	}
+	// The first message is the debug log of AddRule
+	if len(l.messages) != 4 || !strings.HasPrefix(l.messages[1], "ERROR [singleEngine][Execute] panic in rule loop") ||
+		!strings.Contains(l.messages[1], "goroutine") {
+		t.Fatalf("want the panics logged with their stack got %v", l.messages)
+	}
+}
@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// JSONFact is a fact decoded from a JSON object, such as the body of a request. The rules access its
+// fields like the fields of a struct, through the JSON data access layer of grule. Other maps are
+// added to the data context as they are, their values are accessed with Fact["key"].
+type JSONFact struct {
+	Values map[string]any // object of the fact, it is not modified by the engine
+	Result map[string]any // object updated by the rules, set by Execute
+}
+
+// NewJSONFact returns the JSON fact of an object.
+func NewJSONFact(values map[string]any) *JSONFact {
+	return &JSONFact{Values: values}
+}
+
+// addFact adds the fact to the data context, through the JSON data access layer for a JSONFact.
+func addFact(dataContext ast.IDataContext, name string, fact any) error {
+	if f, ok := fact.(*JSONFact); ok {
+		data, err := json.Marshal(f.Values)
+		if err != nil {
+			return err
+		}
+		return dataContext.AddJSON(name, data)
+	}
+	return dataContext.Add(name, fact)
+}
+
+// syncFact sets the Result of a JSONFact to a copy of the object updated by the rules.
+func syncFact(dataContext ast.IDataContext, name string, fact any) {
+	f, ok := fact.(*JSONFact)
+	if !ok {
+		return
+	}
+	node := dataContext.Get(name)
+	if node == nil {
+		return
+	}
+	value := node.Value()
+	if value.Kind() != reflect.Map {
+		return
+	}
+	result := make(map[string]any, value.Len())
+	iter := value.MapRange()
+	for iter.Next() {
+		result[iter.Key().String()] = iter.Value().Interface()
+	}
+	f.Result = result
+}
@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"context"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// RuleListener observes the rule entries evaluated and executed while a rule runs.
+type RuleListener interface {
+	// RuleEvaluated is called after the when scope of a rule entry is evaluated.
+	RuleEvaluated(rule string, entry *ast.RuleEntry, matched bool)
+	// RuleExecuted is called before the then scope of a rule entry is executed.
+	RuleExecuted(rule string, entry *ast.RuleEntry)
+}
+
+type listenerCtxKey struct{}
+
+// listenerCtx is the value stored in the context to observe an execution.
+type listenerCtx struct {
+	rule      string
+	listeners []RuleListener
+}
+
+// WithRuleListener returns a context notifying the listener of the rule entries
+// evaluated and executed by Execute calls made with it.
+func WithRuleListener(ctx context.Context, listener RuleListener) context.Context {
+	lc := listenerCtx{listeners: []RuleListener{listener}}
+	if parent, ok := ctx.Value(listenerCtxKey{}).(listenerCtx); ok {
+		lc.listeners = append(append(lc.listeners[:0:0], parent.listeners...), listener)
+	}
+	return context.WithValue(ctx, listenerCtxKey{}, lc)
+}
+
+// withListeners returns a context notifying its rule listeners, and the given ones,
+// about the executions of the rule. It returns the context unchanged when there is none.
+func withListeners(ctx context.Context, rule string, listeners ...RuleListener) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	lc, _ := ctx.Value(listenerCtxKey{}).(listenerCtx)
+	if len(lc.listeners) == 0 && len(listeners) == 0 {
+		return ctx
+	}
+	lc.rule = rule
+	lc.listeners = append(append(lc.listeners[:0:0], lc.listeners...), listeners...)
+	return context.WithValue(ctx, listenerCtxKey{}, lc)
+}
+
+type cyclesCtxKey struct{}
+
+// executionCycles counts the cycles of an execution.
+type executionCycles struct {
+	selected uint64 // last cycle in which a rule entry was selected for execution
+}
+
+// withCycles returns a context counting the cycles of the execution in cycles.
+func withCycles(ctx context.Context, cycles *executionCycles) context.Context {
+	return context.WithValue(ctx, cyclesCtxKey{}, cycles)
+}
+
+// contextListener implements the grule engine listener by dispatching
+// the events to the rule listeners stored in the execution context.
+type contextListener struct{}
+
+// EvaluateRuleEntry counts the cycle of a selected rule entry and notifies the rule listeners
+// that a when scope was evaluated.
+func (contextListener) EvaluateRuleEntry(ctx context.Context, cycle uint64, entry *ast.RuleEntry, candidate bool) {
+	if cycles, ok := ctx.Value(cyclesCtxKey{}).(*executionCycles); ok && candidate {
+		cycles.selected = cycle
+	}
+	if lc, ok := ctx.Value(listenerCtxKey{}).(listenerCtx); ok {
+		for _, listener := range lc.listeners {
+			listener.RuleEvaluated(lc.rule, entry, candidate)
+		}
+	}
+}
+
+// ExecuteRuleEntry notifies the rule listeners that a then scope is executed.
+func (contextListener) ExecuteRuleEntry(ctx context.Context, cycle uint64, entry *ast.RuleEntry) {
+	if lc, ok := ctx.Value(listenerCtxKey{}).(listenerCtx); ok {
+		for _, listener := range lc.listeners {
+			listener.RuleExecuted(lc.rule, entry)
+		}
+	}
+}
+
+// BeginCycle does nothing.
+func (contextListener) BeginCycle(ctx context.Context, cycle uint64) {}
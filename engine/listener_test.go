@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+type recordingListener struct {
+	evaluated map[string]int
+	executed  []string
+	rule      string
+}
+
+func (l *recordingListener) RuleEvaluated(rule string, entry *ast.RuleEntry, matched bool) {
+	l.rule = rule
+	if matched {
+		l.evaluated[entry.RuleName]++
+	}
+}
+
+func (l *recordingListener) RuleExecuted(rule string, entry *ast.RuleEntry) {
+	l.executed = append(l.executed, entry.RuleName)
+}
+
+func TestWithRuleListener(t *testing.T) {
+	se := NewSingleEngine(Config{FactName: "DiscountFact"})
+	statement := `rule DiscountRule "Apply discount" salience 10 {
+				when
+					DiscountFact.Amount > 100
+				then
+					DiscountFact.Discount = 10;
+					Retract("DiscountRule"); }
+				rule NeverRule "Never fires" {
+				when
+					DiscountFact.Amount < 0
+				then
+					DiscountFact.Discount = 0;
+					Retract("NeverRule"); }
+				`
+	if err := se.AddRule("r1", statement, 0); err != nil {
+		t.Fatalf("AddRule error: %v", err)
+	}
+
+	listener := &recordingListener{evaluated: make(map[string]int)}
+	fact := &struct {
+		Amount   int
+		Discount int
+	}{Amount: 150}
+	if err := se.Execute(WithRuleListener(context.Background(), listener), "r1", fact); err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+
+	if listener.rule != "r1" {
+		t.Fatalf("listener rule want r1 got %s", listener.rule)
+	}
+	if len(listener.executed) != 1 || listener.executed[0] != "DiscountRule" {
+		t.Fatalf("executed want [DiscountRule] got %v", listener.executed)
+	}
+	if listener.evaluated["DiscountRule"] != 1 || listener.evaluated["NeverRule"] != 0 {
+		t.Fatalf("unexpected evaluations %v", listener.evaluated)
+	}
+
+	// executions without a listener are not observed
+	if err := se.Execute(context.Background(), "r1", fact); err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+	if len(listener.executed) != 1 {
+		t.Fatalf("listener should not observe other executions, got %v", listener.executed)
+	}
+}
@@ -0,0 +1,190 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/hungpdn/grule-plus/internal/logger"
+)
+
+// Logger receives the logs of an engine, see Config.Logger. *zap.SugaredLogger implements it.
+type Logger interface {
+	// Debugf logs a message at Debug level.
+	Debugf(format string, args ...any)
+	// Infof logs a message at Info level.
+	Infof(format string, args ...any)
+	// Warnf logs a message at Warn level.
+	Warnf(format string, args ...any)
+	// Errorf logs a message at Error level.
+	Errorf(format string, args ...any)
+}
+
+// AttrsLogger is a Logger which can add attributes to its logs. The logs of Execute and FetchMatching
+// get the attributes of their context, see RegisterContextExtractor, when the Logger is an AttrsLogger.
+type AttrsLogger interface {
+	Logger
+	// WithAttrs returns a logger adding the attributes to its logs.
+	WithAttrs(attrs map[string]any) Logger
+}
+
+// NopLogger discards the logs of an engine.
+var NopLogger Logger = nopLogger{}
+
+type nopLogger struct{}
+
+func (nopLogger) Debugf(format string, args ...any) {}
+func (nopLogger) Infof(format string, args ...any)  {}
+func (nopLogger) Warnf(format string, args ...any)  {}
+func (nopLogger) Errorf(format string, args ...any) {}
+
+// NewSlogLogger returns a Logger writing the logs of an engine to the slog logger.
+func NewSlogLogger(l *slog.Logger) AttrsLogger {
+	return slogLogger{l}
+}
+
+// slogLogger adapts a slog.Logger to AttrsLogger.
+type slogLogger struct {
+	log *slog.Logger
+}
+
+// write formats and logs the message unless the level is disabled.
+func (l slogLogger) write(level slog.Level, format string, args ...any) {
+	if l.log.Enabled(context.Background(), level) {
+		l.log.Log(context.Background(), level, fmt.Sprintf(format, args...))
+	}
+}
+
+func (l slogLogger) Debugf(format string, args ...any) { l.write(slog.LevelDebug, format, args...) }
+func (l slogLogger) Infof(format string, args ...any)  { l.write(slog.LevelInfo, format, args...) }
+func (l slogLogger) Warnf(format string, args ...any)  { l.write(slog.LevelWarn, format, args...) }
+func (l slogLogger) Errorf(format string, args ...any) { l.write(slog.LevelError, format, args...) }
+
+// WithAttrs returns a logger adding the attributes to its logs.
+func (l slogLogger) WithAttrs(attrs map[string]any) Logger {
+	args := make([]any, 0, 2*len(attrs))
+	for k, v := range attrs {
+		args = append(args, k, v)
+	}
+	return slogLogger{l.log.With(args...)}
+}
+
+// LogSampling limits the logs of an engine written for each message and rule in every interval, so a
+// failing rule executed at a high rate does not flood the logs. At the end of an interval, the number
+// of suppressed logs of each message and rule is logged.
+type LogSampling struct {
+	Interval time.Duration // length of the intervals, 0 means no limit
+	Debug    int           // debug logs written per message, rule and interval, 0 means no limit
+	Info     int           // info logs written per message, rule and interval, 0 means no limit
+	Warn     int           // warn logs written per message, rule and interval, 0 means no limit
+	Error    int           // error logs written per message, rule and interval, 0 means no limit
+}
+
+// newLogSampler returns the sampler of the engine logs, nil without sampling
+func newLogSampler(cfg LogSampling) *logger.Sampler {
+	return logger.NewSampler(logger.Sampling{
+		Interval: cfg.Interval,
+		Limits: map[logger.Level]int{
+			logger.LevelDebug: cfg.Debug,
+			logger.LevelInfo:  cfg.Info,
+			logger.LevelWarn:  cfg.Warn,
+			logger.LevelError: cfg.Error,
+		},
+	})
+}
+
+// ContextExtractor returns the attributes of the context of Execute and FetchMatching added to the
+// engine logs, such as a tenant or trace ID, nil when the context has none.
+type ContextExtractor func(ctx context.Context) map[string]any
+
+// RegisterContextExtractor registers an extractor of context attributes for the logs of every engine
+// and of the servers, replacing the extractor already registered under the name. The correlation ID
+// of the context is extracted by the "correlation_id" extractor.
+func RegisterContextExtractor(name string, extractor ContextExtractor) {
+	logger.RegisterContextExtractor(name, logger.ContextExtractor(extractor))
+}
+
+// UnregisterContextExtractor removes the extractor registered under the name.
+func UnregisterContextExtractor(name string) {
+	logger.UnregisterContextExtractor(name)
+}
+
+// ContextValueExtractor returns an extractor adding the value of the context key to the logs as the
+// attribute, when the context has a value for the key.
+func ContextValueExtractor(attr string, key any) ContextExtractor {
+	return func(ctx context.Context) map[string]any {
+		if v := ctx.Value(key); v != nil {
+			return map[string]any{attr: v}
+		}
+		return nil
+	}
+}
+
+// Log components whose level can be set with SetComponentLogLevel.
+const (
+	LogEngine    = string(logger.ComponentEngine)    // executions and rule loading
+	LogCache     = string(logger.ComponentCache)     // rules leaving the cache
+	LogPartition = string(logger.ComponentPartition) // routing of the rules to the partitions
+)
+
+// SetLogLevel sets the level of the package logger: debug, info, warn or error. It applies at once
+// to every engine without Config.Logger, and to the components without their own level.
+func SetLogLevel(level string) error {
+	l, err := logger.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	logger.Levels.SetLevel(l)
+	return nil
+}
+
+// SetComponentLogLevel sets the level of the logs of a component, LogEngine, LogCache or LogPartition,
+// written by the package logger. An empty level resets the component to the level of SetLogLevel.
+func SetComponentLogLevel(component, level string) error {
+	if !slices.Contains(logger.Components, logger.Component(component)) {
+		return fmt.Errorf("unknown log component %q", component)
+	}
+	if level == "" {
+		logger.Levels.ResetComponentLevel(logger.Component(component))
+		return nil
+	}
+	l, err := logger.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	logger.Levels.SetComponentLevel(logger.Component(component), l)
+	return nil
+}
+
+// LogLevels returns the level of the package logger and the levels set per component.
+func LogLevels() (level string, components map[string]string) {
+	components = make(map[string]string)
+	for component, l := range logger.Levels.Overrides() {
+		components[string(component)] = l.String()
+	}
+	return logger.Levels.Level().String(), components
+}
+
+// componentLog returns Config.Logger, or the package logger of the component.
+func componentLog(cfg Config, component logger.Component) Logger {
+	if cfg.Logger != nil {
+		return cfg.Logger
+	}
+	return logger.ForComponent(component)
+}
+
+// log returns the logger of the engine for the logs of the rule, with the attributes of the context.
+// It is the package logger unless Config.Logger is set, sampled with Config.LogSampling.
+func (s *singleEngine) log(ctx context.Context, rule string) Logger {
+	var l Logger = s.cfg.Logger
+	if l == nil {
+		l = logger.ForComponent(logger.ComponentEngine).WithAttrs(logger.ContextAttrs(ctx))
+	} else if al, ok := l.(AttrsLogger); ok {
+		if attrs := logger.ContextAttrs(ctx); len(attrs) > 0 {
+			l = al.WithAttrs(attrs)
+		}
+	}
+	return s.sampler.Printer(l, rule)
+}
@@ -0,0 +1,142 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hungpdn/grule-plus/internal/logger"
+)
+
+// recordingLogger records the level and message of the logs
+type recordingLogger struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (l *recordingLogger) Debugf(format string, args ...any) { l.add("DEBUG", format, args...) }
+func (l *recordingLogger) Infof(format string, args ...any)  { l.add("INFO", format, args...) }
+func (l *recordingLogger) Warnf(format string, args ...any)  { l.add("WARN", format, args...) }
+func (l *recordingLogger) Errorf(format string, args ...any) { l.add("ERROR", format, args...) }
+
+func (l *recordingLogger) add(level, format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, level+" "+fmt.Sprintf(format, args...))
+}
+
+func TestConfigLogger(t *testing.T) {
+	l := &recordingLogger{}
+	se := NewSingleEngine(Config{Logger: l})
+
+	if err := se.Execute(context.Background(), "missing", &struct{}{}); err == nil {
+		t.Fatalf("Execute of a missing rule should fail")
+	}
+	if _, err := se.FetchMatching(context.Background(), "missing", &struct{}{}); err == nil {
+		t.Fatalf("FetchMatching of a missing rule should fail")
+	}
+	if len(l.messages) != 2 || !strings.HasPrefix(l.messages[0], "ERROR [singleEngine][Execute] knowledge library empty") ||
+		!strings.HasPrefix(l.messages[1], "ERROR [singleEngine][FetchMatching] knowledge library empty") {
+		t.Fatalf("engine logs should go to the configured logger, got %v", l.messages)
+	}
+
+	// Each partition logs to the configured logger
+	l = &recordingLogger{}
+	pe := NewPartitionEngine(Config{Partition: 2, Logger: l}, nil)
+	defer pe.Close()
+	_ = pe.Execute(context.Background(), "missing", &struct{}{})
+	if len(l.messages) != 1 {
+		t.Fatalf("partition engine logs should go to the configured logger, got %v", l.messages)
+	}
+}
+
+func TestSlogLogger(t *testing.T) {
+	var out bytes.Buffer
+	l := NewSlogLogger(slog.New(slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: slog.LevelInfo})))
+	se := NewSingleEngine(Config{Logger: l})
+
+	ctx := context.WithValue(context.Background(), logger.CorrelationIdCtxKey, "id-1")
+	_ = se.Execute(ctx, "missing", &struct{}{})
+	l.Debugf("disabled %d", 1)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("want one log line got %q", out.String())
+	}
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &fields); err != nil {
+		t.Fatalf("decode %q: %v", lines[0], err)
+	}
+	if fields["level"] != "ERROR" || fields["correlation_id"] != "id-1" ||
+		!strings.HasPrefix(fields["msg"].(string), "[singleEngine][Execute]") {
+		t.Fatalf("log should have the level, message and correlation ID of the context: %v", fields)
+	}
+}
+
+func TestLogSampling(t *testing.T) {
+	l := &recordingLogger{}
+	se := NewSingleEngine(Config{Logger: l, LogSampling: LogSampling{Interval: 50 * time.Millisecond, Error: 3}})
+	for i := 0; i < 100; i++ {
+		_ = se.Execute(context.Background(), "missing", &struct{}{})
+		_ = se.Execute(context.Background(), "other", &struct{}{})
+	}
+
+	l.mu.Lock()
+	written := len(l.messages)
+	l.mu.Unlock()
+	if written != 6 {
+		t.Fatalf("want 3 logs per rule got %d", written)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	summaries := l.messages[written:]
+	if len(summaries) != 2 || !strings.Contains(summaries[0], "97 logs of rule") || !strings.Contains(summaries[1], "97 logs of rule") {
+		t.Fatalf("want the suppressed logs of each rule got %v", summaries)
+	}
+}
+
+type requestIDKey struct{}
+
+func TestRegisterContextExtractor(t *testing.T) {
+	RegisterContextExtractor("request", ContextValueExtractor("request_id", requestIDKey{}))
+	defer UnregisterContextExtractor("request")
+
+	var out bytes.Buffer
+	se := NewSingleEngine(Config{Logger: NewSlogLogger(slog.New(slog.NewJSONHandler(&out, nil)))})
+	ctx := context.WithValue(context.Background(), logger.CorrelationIdCtxKey, "id-1")
+	_ = se.Execute(context.WithValue(ctx, requestIDKey{}, "req-1"), "missing", &struct{}{})
+	_ = se.Execute(ctx, "missing", &struct{}{})
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("want two log lines got %q", out.String())
+	}
+	var first, second map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("decode %q: %v", lines[0], err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &second); err != nil {
+		t.Fatalf("decode %q: %v", lines[1], err)
+	}
+	if first["request_id"] != "req-1" || first["correlation_id"] != "id-1" {
+		t.Fatalf("log should have the request and correlation IDs of the context: %v", first)
+	}
+	if _, ok := second["request_id"]; ok || second["correlation_id"] != "id-1" {
+		t.Fatalf("log should only have the correlation ID of the context: %v", second)
+	}
+}
+
+func TestNopLogger(t *testing.T) {
+	se := NewSingleEngine(Config{Logger: NopLogger})
+	if err := se.Execute(context.Background(), "missing", &struct{}{}); err == nil {
+		t.Fatalf("Execute of a missing rule should fail")
+	}
+}
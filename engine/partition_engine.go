@@ -3,7 +3,9 @@ package engine
 import (
 	"context"
 	"runtime"
+	"sort"
 
+	"github.com/hungpdn/grule-plus/internal/logger"
 	"github.com/hungpdn/grule-plus/internal/utils"
 	"github.com/hyperjumptech/grule-rule-engine/ast"
 )
@@ -15,6 +17,7 @@ type partitionEngine struct {
 	partition int
 	engines   map[int]*singleEngine
 	hash      HashFunc
+	limits    *rateLimits
 }
 
 func NewPartitionEngine(cfg Config, hashFunc HashFunc) *partitionEngine {
@@ -24,6 +27,7 @@ func NewPartitionEngine(cfg Config, hashFunc HashFunc) *partitionEngine {
 		partition: partition,
 		engines:   make(map[int]*singleEngine),
 		hash:      hashFunc,
+		limits:    newRateLimits(),
 	}
 
 	if hashFunc == nil {
@@ -41,14 +45,31 @@ func NewPartitionEngine(cfg Config, hashFunc HashFunc) *partitionEngine {
 			CleanupInterval: cfg.CleanupInterval,
 			TTL:             cfg.TTL,
 			FactName:        cfg.FactName,
+			Coverage:        cfg.Coverage,
+			MaxCost:         cfg.MaxCost,
+			Shards:          cfg.Shards,
+			ReadBuffer:      cfg.ReadBuffer,
+			ReadSample:      cfg.ReadSample,
+			SlidingTTL:      cfg.SlidingTTL,
+			RefreshAhead:    cfg.RefreshAhead,
+			Loader:          cfg.Loader,
+			Trace:           cfg.Trace,
+			Logger:          cfg.Logger,
+			FactRedaction:   cfg.FactRedaction,
+			LogSampling:     cfg.LogSampling,
+			CircuitBreaker:  cfg.CircuitBreaker,
 		}
 		partitionEngine.engines[i+1] = NewSingleEngine(cfgE)
+		partitionEngine.engines[i+1].partition = i + 1
 	}
 
 	return partitionEngine
 }
 
 func (s *partitionEngine) Execute(ctx context.Context, rule string, fact any) error {
+	if err := s.limits.allow(rule); err != nil {
+		return err
+	}
 	return s.engines[s.hash(rule)].Execute(ctx, rule, fact)
 }
 
@@ -57,32 +78,92 @@ func (s *partitionEngine) FetchMatching(ctx context.Context, rule string, fact a
 }
 
 func (s *partitionEngine) AddRule(rule, statement string, duration int64) error {
-	return s.engines[s.hash(rule)].AddRule(rule, statement, duration)
+	partition := s.hash(rule)
+	componentLog(s.cfg, logger.ComponentPartition).Debugf("[partitionEngine][AddRule] rule %v in partition %d", rule, partition)
+	return s.engines[partition].AddRule(rule, statement, duration)
 }
 
 func (s *partitionEngine) BuildRule(rule, statement string, duration int64) error {
-	return s.engines[s.hash(rule)].BuildRule(rule, statement, duration)
+	partition := s.hash(rule)
+	componentLog(s.cfg, logger.ComponentPartition).Debugf("[partitionEngine][BuildRule] rule %v in partition %d", rule, partition)
+	return s.engines[partition].BuildRule(rule, statement, duration)
 }
 
 func (s *partitionEngine) ContainsRule(rule string) bool {
 	return s.engines[s.hash(rule)].ContainsRule(rule)
 }
 
+func (s *partitionEngine) RemoveRule(rule string) {
+	s.engines[s.hash(rule)].RemoveRule(rule)
+}
+
+func (s *partitionEngine) ListRules() []string {
+	rules := make([]string, 0)
+	for _, v := range s.engines {
+		if v != nil {
+			rules = append(rules, v.ListRules()...)
+		}
+	}
+	sort.Strings(rules)
+	return rules
+}
+
+func (s *partitionEngine) Coverage() CoverageReport {
+	report := CoverageReport{Rules: make([]RuleCoverage, 0)}
+	for _, v := range s.engines {
+		if v != nil {
+			report.Merge(v.Coverage())
+		}
+	}
+	return report
+}
+
+func (s *partitionEngine) OnRuleEvicted(hook RuleEvictedHook) {
+	for _, v := range s.engines {
+		if v != nil {
+			v.OnRuleEvicted(hook)
+		}
+	}
+}
+
 func (s *partitionEngine) Debug() map[string]any {
 	engines := make(map[int]map[string]any)
+	var panics int64
 	for k, v := range s.engines {
 		if v != nil {
 			engines[k] = v.Debug()
 			delete(engines[k], "stats")
+			panics += v.panics.Load()
 		}
 	}
 	return map[string]any{
 		"partition_config": s.cfg,
 		"engines":          engines,
+		"panics":           panics,
+		"rate_limits":      s.limits.stats(),
 		"stats":            utils.GetStats(),
 	}
 }
 
+// SetRateLimit adds the rate limit of the executions of a rule or name prefix, or replaces it.
+func (s *partitionEngine) SetRateLimit(limit RateLimit) error {
+	if err := s.limits.set(limit); err != nil {
+		return err
+	}
+	componentLog(s.cfg, logger.ComponentPartition).Infof("[partitionEngine][SetRateLimit] rule %v limited to %v executions per second", limit.Rule, limit.Rate)
+	return nil
+}
+
+// RemoveRateLimit removes the rate limit of a rule or name prefix, it reports whether it existed.
+func (s *partitionEngine) RemoveRateLimit(rule string) bool {
+	return s.limits.remove(rule)
+}
+
+// RateLimits returns the rate limits sorted by rule, with their statistics.
+func (s *partitionEngine) RateLimits() []RateLimitStats {
+	return s.limits.stats()
+}
+
 func (s *partitionEngine) Close() {
 	for _, v := range s.engines {
 		if v != nil {
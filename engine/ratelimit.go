@@ -0,0 +1,195 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit limits the executions of a rule, or of the rules whose names start with a prefix, such as
+// the rules of a tenant, with a token bucket: Rate tokens per second are added up to Burst tokens, and
+// each execution takes one token or fails with ErrRateLimited.
+type RateLimit struct {
+	Rule  string  // name of the rule, or prefix of the names when it ends with "*", "*" alone limits every rule
+	Rate  float64 // executions per second
+	Burst int     // executions allowed at once, 0 means the rate rounded up
+}
+
+// prefix returns the prefix of the names limited by the rate limit, and whether it limits a prefix
+func (l RateLimit) prefix() (string, bool) {
+	return strings.CutSuffix(l.Rule, "*")
+}
+
+// RateLimitStats is a rate limit with the executions it allowed and rejected.
+type RateLimitStats struct {
+	RateLimit
+	Tokens  float64 // tokens left in the bucket
+	Allowed int64   // executions allowed since the limit was set
+	Limited int64   // executions rejected with ErrRateLimited since the limit was set
+}
+
+// RateLimiter is implemented by the engines limiting the executions of the rules, the partition engine.
+// A rule limited by its name is not limited by a prefix, and a rule matching several prefixes is limited
+// by the longest one.
+type RateLimiter interface {
+	// SetRateLimit adds the rate limit, or replaces the limit of the same rule or prefix.
+	SetRateLimit(limit RateLimit) error
+	// RemoveRateLimit removes the rate limit of the rule or prefix, it reports whether it existed.
+	RemoveRateLimit(rule string) bool
+	// RateLimits returns the rate limits sorted by rule, with their statistics.
+	RateLimits() []RateLimitStats
+}
+
+// tokenBucket is the bucket of a rate limit
+type tokenBucket struct {
+	name    string // rule or prefix of the limit, it does not change when the limit is replaced
+	mu      sync.Mutex
+	limit   RateLimit
+	tokens  float64
+	last    time.Time // when the tokens were last added
+	allowed int64
+	limited int64
+}
+
+// refill adds the tokens of the time elapsed since the last refill.
+// Note: must use with Mutex
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens = math.Min(float64(b.limit.Burst), b.tokens+now.Sub(b.last).Seconds()*b.limit.Rate)
+	b.last = now
+}
+
+// take takes a token from the bucket, it reports whether there was one.
+func (b *tokenBucket) take() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+	if b.tokens < 1 {
+		b.limited++
+		return false
+	}
+	b.tokens--
+	b.allowed++
+	return true
+}
+
+// stats returns the limit of the bucket and its statistics
+func (b *tokenBucket) stats() RateLimitStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+	return RateLimitStats{RateLimit: b.limit, Tokens: b.tokens, Allowed: b.allowed, Limited: b.limited}
+}
+
+// rateLimits holds the buckets of the rate limits of an engine. It is safe for concurrent use.
+type rateLimits struct {
+	mu       sync.RWMutex
+	rules    map[string]*tokenBucket // buckets of the limits of a rule name
+	prefixes map[string]*tokenBucket // buckets of the limits of a name prefix
+}
+
+// newRateLimits returns rate limits without any limit
+func newRateLimits() *rateLimits {
+	return &rateLimits{
+		rules:    make(map[string]*tokenBucket),
+		prefixes: make(map[string]*tokenBucket),
+	}
+}
+
+// set adds or replaces the limit. A replaced limit keeps its tokens, up to the new burst, and statistics.
+func (r *rateLimits) set(limit RateLimit) error {
+	if limit.Rule == "" {
+		return errors.New("rate limit rule is required")
+	}
+	if limit.Rate <= 0 || math.IsInf(limit.Rate, 0) || math.IsNaN(limit.Rate) {
+		return fmt.Errorf("rate limit of %s must be a positive rate, got %v", limit.Rule, limit.Rate)
+	}
+	if limit.Burst <= 0 {
+		limit.Burst = int(math.Ceil(limit.Rate))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	buckets, key := r.buckets(limit)
+	b, ok := buckets[key]
+	if !ok {
+		buckets[key] = &tokenBucket{name: limit.Rule, limit: limit, tokens: float64(limit.Burst), last: time.Now()}
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(time.Now())
+	b.limit = limit
+	b.tokens = math.Min(b.tokens, float64(limit.Burst))
+	return nil
+}
+
+// remove removes the limit of the rule or prefix, it reports whether it existed
+func (r *rateLimits) remove(rule string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	buckets, key := r.buckets(RateLimit{Rule: rule})
+	_, ok := buckets[key]
+	delete(buckets, key)
+	return ok
+}
+
+// buckets returns the buckets of the limits of the kind of the limit, and the key of the limit.
+// Note: must use with Mutex
+func (r *rateLimits) buckets(limit RateLimit) (map[string]*tokenBucket, string) {
+	if prefix, ok := limit.prefix(); ok {
+		return r.prefixes, prefix
+	}
+	return r.rules, limit.Rule
+}
+
+// allow takes a token from the bucket limiting the rule, it returns ErrRateLimited without tokens.
+func (r *rateLimits) allow(rule string) error {
+	b := r.bucket(rule)
+	if b == nil || b.take() {
+		return nil
+	}
+	return fmt.Errorf("%w: rule %s over the limit of %s", ErrRateLimited, rule, b.name)
+}
+
+// bucket returns the bucket limiting the rule: the limit of its name, or of its longest prefix
+func (r *rateLimits) bucket(rule string) *tokenBucket {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if b, ok := r.rules[rule]; ok {
+		return b
+	}
+	var bucket *tokenBucket
+	longest := -1
+	for prefix, b := range r.prefixes {
+		if len(prefix) > longest && strings.HasPrefix(rule, prefix) {
+			bucket, longest = b, len(prefix)
+		}
+	}
+	return bucket
+}
+
+// stats returns the limits sorted by rule with their statistics
+func (r *rateLimits) stats() []RateLimitStats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := make([]RateLimitStats, 0, len(r.rules)+len(r.prefixes))
+	for _, buckets := range []map[string]*tokenBucket{r.rules, r.prefixes} {
+		for _, b := range buckets {
+			stats = append(stats, b.stats())
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Rule < stats[j].Rule
+	})
+	return stats
+}
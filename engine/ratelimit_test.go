@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	pe := NewPartitionEngine(Config{Partition: 2}, nil)
+	var limiter RateLimiter = pe
+	for _, rule := range []string{"teamA/r1", "teamA/r2", "teamA/vip/r3", "teamB/r4"} {
+		if err := pe.AddRule(rule, loopStatement, 0); err != nil {
+			t.Fatalf("AddRule error: %v", err)
+		}
+	}
+
+	for _, limit := range []RateLimit{
+		{Rule: "teamA/*", Rate: 0.01, Burst: 2},
+		{Rule: "teamA/vip/*", Rate: 0.01, Burst: 1},
+		{Rule: "teamB/r4", Rate: 0.01},
+	} {
+		if err := limiter.SetRateLimit(limit); err != nil {
+			t.Fatalf("SetRateLimit(%+v) error: %v", limit, err)
+		}
+	}
+
+	fact := &errorsFact{Amount: 1}
+	execute := func(rule string) error {
+		return pe.Execute(context.Background(), rule, fact)
+	}
+	// The rules of a prefix share its bucket, the longest prefix wins
+	if execute("teamA/r1") != nil || execute("teamA/r2") != nil || execute("teamA/vip/r3") != nil || execute("teamB/r4") != nil {
+		t.Fatalf("executions under the limits should succeed")
+	}
+	err := execute("teamA/r1")
+	if !errors.Is(err, ErrRateLimited) || !IsRetryable(err) || err.Error() != "rate limited: rule teamA/r1 over the limit of teamA/*" {
+		t.Fatalf("want ErrRateLimited got %v", err)
+	}
+	for _, rule := range []string{"teamA/vip/r3", "teamB/r4"} {
+		if err := execute(rule); !errors.Is(err, ErrRateLimited) {
+			t.Fatalf("%s want ErrRateLimited got %v", rule, err)
+		}
+	}
+	// A rate limit only applies to Execute
+	if _, err := pe.FetchMatching(context.Background(), "teamA/r1", fact); err != nil {
+		t.Fatalf("FetchMatching error: %v", err)
+	}
+
+	stats := limiter.RateLimits()
+	if len(stats) != 3 || stats[0].Rule != "teamA/*" || stats[0].Allowed != 2 || stats[0].Limited != 1 || stats[2].Burst != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	if got := pe.Debug()["rate_limits"].([]RateLimitStats); len(got) != 3 {
+		t.Fatalf("Debug want 3 rate limits got %+v", got)
+	}
+
+	// Limits can be changed and removed at runtime
+	if err := limiter.SetRateLimit(RateLimit{Rule: "teamB/r4", Rate: 1000}); err != nil {
+		t.Fatalf("SetRateLimit error: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if err := execute("teamB/r4"); err != nil {
+		t.Fatalf("raised limit should allow the execution: %v", err)
+	}
+	if !limiter.RemoveRateLimit("teamA/*") || limiter.RemoveRateLimit("teamA/*") {
+		t.Fatalf("RemoveRateLimit should remove the limit once")
+	}
+	if err := execute("teamA/r1"); err != nil {
+		t.Fatalf("removed limit should allow the execution: %v", err)
+	}
+	if err := execute("teamA/vip/r3"); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("other prefixes should still be limited, got %v", err)
+	}
+
+	for _, limit := range []RateLimit{{Rate: 1}, {Rule: "r1"}, {Rule: "r1", Rate: -1}} {
+		if err := limiter.SetRateLimit(limit); err == nil {
+			t.Fatalf("SetRateLimit(%+v) should fail", limit)
+		}
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	r := newRateLimits()
+	if err := r.set(RateLimit{Rule: "*", Rate: 100, Burst: 1}); err != nil {
+		t.Fatalf("set error: %v", err)
+	}
+	if r.allow("r1") != nil || r.allow("r2") == nil {
+		t.Fatalf("the bucket should allow its burst only")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if err := r.allow("r1"); err != nil {
+		t.Fatalf("the bucket should be refilled at the rate: %v", err)
+	}
+	if stats := r.stats(); len(stats) != 1 || stats[0].Tokens > 1 || stats[0].Allowed != 2 || stats[0].Limited != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
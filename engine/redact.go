@@ -0,0 +1,167 @@
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// FactRedaction is how the engine writes facts in its logs.
+type FactRedaction string
+
+const (
+	RedactTagged FactRedaction = "tagged" // the fact with the struct fields tagged `grule:"redact"` masked, a hash of a JSONFact, the default
+	RedactType   FactRedaction = "type"   // only the type of the fact
+	RedactHash   FactRedaction = "hash"   // the type and a hash of the fact, equal facts have equal hashes
+	RedactNone   FactRedaction = "none"   // the whole fact, formatted with %v
+)
+
+// redacted replaces the value of the redacted fields
+const redacted = "[REDACTED]"
+
+// maxRedactDepth bounds the nested values written by RedactTagged, to stop on cyclic facts
+const maxRedactDepth = 10
+
+// GetFactRedaction returns the configured fact redaction or the default RedactTagged if not set.
+func (c Config) GetFactRedaction() FactRedaction {
+	if c.FactRedaction == "" {
+		return RedactTagged
+	}
+	return c.FactRedaction
+}
+
+// Redact formats the fact for a log or a trace, hiding what the redaction does not allow to write.
+func (r FactRedaction) Redact(fact any) string {
+	switch r {
+	case RedactNone:
+		return fmt.Sprintf("%v", fact)
+	case RedactType:
+		return fmt.Sprintf("%T", fact)
+	case RedactHash:
+		data, err := json.Marshal(fact)
+		if err != nil {
+			data = []byte(fmt.Sprintf("%#v", fact))
+		}
+		sum := sha256.Sum256(data)
+		return fmt.Sprintf("%T(sha256:%s)", fact, hex.EncodeToString(sum[:8]))
+	default:
+		if _, ok := fact.(*JSONFact); ok {
+			// A JSON fact has no tags to mask its fields, only its hash is written
+			return RedactHash.Redact(fact)
+		}
+		var b strings.Builder
+		writeRedacted(&b, reflect.ValueOf(fact), 0)
+		return b.String()
+	}
+}
+
+// writeRedacted writes the value like %+v, masking the struct fields tagged `grule:"redact"`.
+func writeRedacted(b *strings.Builder, v reflect.Value, depth int) {
+	if !v.IsValid() {
+		b.WriteString("<nil>")
+		return
+	}
+	if depth > maxRedactDepth {
+		b.WriteString("...")
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			b.WriteString("<nil>")
+			return
+		}
+		b.WriteByte('&')
+		writeRedacted(b, v.Elem(), depth+1)
+	case reflect.Interface:
+		writeRedacted(b, v.Elem(), depth)
+	case reflect.Struct:
+		if stringer(v) && !hasRedactedFields(v.Type()) {
+			fmt.Fprint(b, v)
+			return
+		}
+		b.WriteByte('{')
+		for i := 0; i < v.NumField(); i++ {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			field := v.Type().Field(i)
+			b.WriteString(field.Name + ":")
+			if isRedacted(field) {
+				b.WriteString(redacted)
+			} else {
+				writeRedacted(b, v.Field(i), depth+1)
+			}
+		}
+		b.WriteByte('}')
+	case reflect.Map:
+		keys := v.MapKeys()
+		names := make([]string, len(keys))
+		for i, key := range keys {
+			names[i] = fmt.Sprint(key)
+		}
+		order := make([]int, len(keys))
+		for i := range order {
+			order[i] = i
+		}
+		sort.Slice(order, func(i, j int) bool { return names[order[i]] < names[order[j]] })
+
+		b.WriteString("map[")
+		for i, k := range order {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteString(names[k] + ":")
+			writeRedacted(b, v.MapIndex(keys[k]), depth+1)
+		}
+		b.WriteByte(']')
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			b.WriteString("[]")
+			return
+		}
+		b.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			writeRedacted(b, v.Index(i), depth+1)
+		}
+		b.WriteByte(']')
+	default:
+		fmt.Fprint(b, v)
+	}
+}
+
+// stringer reports whether the value formats itself, like time.Time
+func stringer(v reflect.Value) bool {
+	if !v.CanInterface() {
+		return false
+	}
+	switch v.Interface().(type) {
+	case fmt.Stringer, error:
+		return true
+	}
+	return false
+}
+
+// isRedacted reports whether the struct field is tagged `grule:"redact"`
+func isRedacted(field reflect.StructField) bool {
+	return slices.Contains(strings.Split(field.Tag.Get("grule"), ","), "redact")
+}
+
+// hasRedactedFields reports whether the struct type has fields tagged `grule:"redact"`
+func hasRedactedFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if isRedacted(t.Field(i)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+type redactAddress struct {
+	City   string
+	Street string `grule:"redact"`
+}
+
+type redactFact struct {
+	Amount   int
+	Email    string `json:"email" grule:"redact"`
+	Card     string `grule:"mask,redact"`
+	Address  *redactAddress
+	Tags     []string
+	Extra    map[string]any
+	Created  time.Time
+	internal string
+}
+
+func newRedactFact() *redactFact {
+	return &redactFact{
+		Amount:   150,
+		Email:    "jane@example.com",
+		Card:     "4111111111111111",
+		Address:  &redactAddress{City: "Hanoi", Street: "1 Secret St"},
+		Tags:     []string{"vip"},
+		Extra:    map[string]any{"b": 2, "a": &redactAddress{City: "Hue", Street: "2 Secret St"}},
+		Created:  time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+		internal: "x",
+	}
+}
+
+func TestRedactTagged(t *testing.T) {
+	got := RedactTagged.Redact(newRedactFact())
+	want := "&{Amount:150 Email:[REDACTED] Card:[REDACTED] Address:&{City:Hanoi Street:[REDACTED]} Tags:[vip] " +
+		"Extra:map[a:&{City:Hue Street:[REDACTED]} b:2] Created:2025-01-02 00:00:00 +0000 UTC internal:x}"
+	if got != want {
+		t.Fatalf("want %s\ngot  %s", want, got)
+	}
+
+	if got := RedactTagged.Redact(nil); got != "<nil>" {
+		t.Fatalf("nil fact want <nil> got %s", got)
+	}
+	if got := RedactTagged.Redact(map[string]any{"Amount": 1}); got != "map[Amount:1]" {
+		t.Fatalf("map fact want map[Amount:1] got %s", got)
+	}
+	jsonFact := NewJSONFact(map[string]any{"email": "jane@example.com"})
+	if got := RedactTagged.Redact(jsonFact); got != RedactHash.Redact(jsonFact) {
+		t.Fatalf("JSON fact should be hashed, got %s", got)
+	}
+
+	// Cyclic facts stop at the maximum depth
+	type node struct{ Next *node }
+	n := &node{}
+	n.Next = n
+	if got := RedactTagged.Redact(n); !strings.HasSuffix(got, "...}}}}}") {
+		t.Fatalf("cyclic fact should be cut, got %s", got)
+	}
+}
+
+func TestRedactTypeHashNone(t *testing.T) {
+	fact := newRedactFact()
+	if got := RedactType.Redact(fact); got != "*engine.redactFact" {
+		t.Fatalf("type redaction want *engine.redactFact got %s", got)
+	}
+
+	hash := RedactHash.Redact(fact)
+	if !strings.HasPrefix(hash, "*engine.redactFact(sha256:") || strings.Contains(hash, "jane") {
+		t.Fatalf("hash redaction should only write the type and the hash, got %s", hash)
+	}
+	if RedactHash.Redact(newRedactFact()) != hash {
+		t.Fatalf("equal facts should have equal hashes")
+	}
+	fact.Amount++
+	if RedactHash.Redact(fact) == hash {
+		t.Fatalf("different facts should have different hashes")
+	}
+
+	if got := RedactNone.Redact(fact); !strings.Contains(got, "jane@example.com") {
+		t.Fatalf("no redaction should write the whole fact, got %s", got)
+	}
+	if (Config{}).GetFactRedaction() != RedactTagged {
+		t.Fatalf("default redaction should be %s", RedactTagged)
+	}
+}
+
+func TestExecuteRedactsFact(t *testing.T) {
+	statement := `rule Loop "Never retracted" salience 10 {
+				when
+					Fact.Amount > 100
+				then
+					Fact.Tags = Fact.Tags; }
+				`
+	for _, tc := range []struct {
+		redaction FactRedaction
+		want      string
+	}{
+		{"", "Email:[REDACTED]"},
+		{RedactType, "fact *engine.redactFact has error"},
+		{RedactHash, "fact *engine.redactFact(sha256:"},
+	} {
+		l := &recordingLogger{}
+		se := NewSingleEngine(Config{Logger: l, FactRedaction: tc.redaction})
+		if err := se.AddRule("r1", statement, 0); err != nil {
+			t.Fatalf("AddRule error: %v", err)
+		}
+
+		if err := se.Execute(context.Background(), "r1", newRedactFact()); err == nil {
+			t.Fatalf("Execute should fail after the maximum number of cycles")
+		}
+		// A JSON fact with a channel cannot be added to the data context
+		fact := NewJSONFact(map[string]any{"Address": redactAddress{Street: "1 Secret St"}, "Done": make(chan int)})
+		if _, err := se.FetchMatching(context.Background(), "r1", fact); err == nil {
+			t.Fatalf("FetchMatching should fail to add the fact")
+		}
+		for _, msg := range l.messages {
+			if strings.Contains(msg, "jane@example.com") || strings.Contains(msg, "Secret") {
+				t.Fatalf("%q redaction leaked the fact: %s", tc.redaction, msg)
+			}
+		}
+		if len(l.messages) != 2 || !strings.Contains(l.messages[0], tc.want) {
+			t.Fatalf("%q redaction want %q in %v", tc.redaction, tc.want, l.messages)
+		}
+	}
+}
@@ -3,12 +3,16 @@ package engine
 import (
 	"context"
 	"errors"
+	"fmt"
 	"runtime"
+	"runtime/debug"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/hungpdn/grule-plus/internal/cache"
-	"github.com/hungpdn/grule-plus/internal/cache/common"
+	"github.com/hungpdn/grule-plus/cache"
+	"github.com/hungpdn/grule-plus/cache/common"
 	"github.com/hungpdn/grule-plus/internal/logger"
 	"github.com/hungpdn/grule-plus/internal/utils"
 	"github.com/hyperjumptech/grule-rule-engine/ast"
@@ -28,44 +32,116 @@ type singleEngine struct {
 	engine             *engine.GruleEngine
 	knowledgeLibraries map[string]*ast.KnowledgeLibrary
 	localCache         cache.ICache
-	mu                 sync.RWMutex // protect knowledgeLibraries
+	coverage           *coverage                     // nil unless in coverage mode
+	ttls               map[string]ruleTTL            // when each rule was loaded and for how long, to refresh it ahead
+	mu                 sync.RWMutex                  // protect knowledgeLibraries and ttls
+	refreshing         map[string]context.CancelFunc // rules being reloaded from the loader, with the cancel func of their load
+	refreshMu          sync.Mutex                    // protect refreshing
+	sampler            *logger.Sampler               // limits the logs of each message and rule, nil without sampling
+	partition          int                           // partition of the engine in a partition engine, 0 otherwise
+	panics             atomic.Int64                  // panics recovered in the rules
+	breakers           *breakers                     // circuit breakers of the rules, nil when disabled
+}
+
+// ruleTTL is the time a rule was loaded, its time-to-live and its cost in the cache
+type ruleTTL struct {
+	loaded time.Time
+	ttl    time.Duration
+	cost   int64
 }
 
 func NewSingleEngine(cfg Config) *singleEngine {
 
 	singleEngine := &singleEngine{
 		cfg:                cfg,
-		engine:             engine.NewGruleEngine(),
+		engine:             newGruleEngine(),
 		knowledgeLibraries: make(map[string]*ast.KnowledgeLibrary),
 		factName:           cfg.GetFactName(),
+		ttls:               make(map[string]ruleTTL),
+		refreshing:         make(map[string]context.CancelFunc),
+		sampler:            newLogSampler(cfg.LogSampling),
 	}
+	if cfg.Coverage {
+		singleEngine.coverage = newCoverage()
+	}
+	singleEngine.breakers = newBreakers(cfg.CircuitBreaker, singleEngine.breakerChanged)
 
-	localCache := cache.New(cache.Config{
+	localCache := cache.New(cache.Config[any, any]{
 		Type:            cfg.GetCacheType(),
 		Size:            cfg.Size,
 		CleanupInterval: time.Duration(cfg.CleanupInterval) * time.Second,
 		DefaultTTL:      time.Duration(cfg.TTL) * time.Second,
+		MaxCost:         cfg.MaxCost,
+		Shards:          cfg.Shards,
+		ReadBuffer:      cfg.ReadBuffer,
+		ReadSample:      cfg.ReadSample,
+		SlidingTTL:      cfg.SlidingTTL,
 	})
-	_ = localCache.SetEvictedFunc(func(key, value any, event int) {
-		go func() {
-			switch event {
-			case common.ExpirationEvent, common.EvictionEvent:
-				singleEngine.RemoveRule(key.(string))
-			default:
-				// do nothing
-			}
-		}()
-	})
-
 	singleEngine.localCache = localCache
+	singleEngine.OnRuleEvicted(RuleEvictedHook{
+		Func:   singleEngine.evictRule,
+		Events: []int{common.ExpirationEvent, common.EvictionEvent},
+		Async:  true,
+	})
 	return singleEngine
 }
 
+// OnRuleEvicted registers a hook notified when a rule leaves the cache
+func (s *singleEngine) OnRuleEvicted(hook RuleEvictedHook) {
+	s.localCache.AddListener(common.Listener[any, any]{
+		Func: func(key, value any, event int) {
+			hook.Func(key.(string), event)
+		},
+		Events: hook.Events,
+		Async:  hook.Async,
+	})
+}
+
+// RemoveRule removes the rule from the knowledge libraries and the cache
 func (s *singleEngine) RemoveRule(rule string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	delete(s.knowledgeLibraries, rule)
+	delete(s.ttls, rule)
+	s.localCache.Delete(rule)
+	s.cancelRefresh(rule)
+}
+
+// evictRule removes a rule evicted or expired from the cache from the knowledge libraries,
+// unless it was added again since
+func (s *singleEngine) evictRule(rule string, event int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.localCache.Has(rule) {
+		return
+	}
+	delete(s.knowledgeLibraries, rule)
+	delete(s.ttls, rule)
+	componentLog(s.cfg, logger.ComponentCache).Debugf("[singleEngine][evictRule] rule %v left the cache, event %v", rule, event)
+}
+
+// ListRules returns the sorted names of the rules in the knowledge libraries
+func (s *singleEngine) ListRules() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rules := make([]string, 0, len(s.knowledgeLibraries))
+	for rule := range s.knowledgeLibraries {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+
+	return rules
+}
+
+// Coverage returns the rule coverage recorded in coverage mode
+func (s *singleEngine) Coverage() CoverageReport {
+	if s.coverage == nil {
+		return CoverageReport{Rules: make([]RuleCoverage, 0)}
+	}
+	return s.coverage.report()
 }
 
 func (s *singleEngine) Debug() map[string]any {
@@ -78,18 +154,24 @@ func (s *singleEngine) Debug() map[string]any {
 		rulesInLibraries = append(rulesInLibraries, rule)
 	}
 
-	return map[string]any{
+	debug := map[string]any{
 		"local_cache": map[string]any{
 			"config": s.cfg,
 			"rules":  rulesInLocalCache,
 			"len":    len(rulesInLocalCache),
+			"cost":   s.localCache.Cost(),
 		},
 		"libraries": map[string]any{
 			"rules": rulesInLibraries,
 			"len":   len(s.knowledgeLibraries),
 		},
-		"stats": utils.GetStats(),
+		"panics": s.panics.Load(),
+		"stats":  utils.GetStats(),
 	}
+	if s.breakers != nil {
+		debug["circuit_breakers"] = s.breakers.debug()
+	}
+	return debug
 }
 
 func (s *singleEngine) Close() {
@@ -97,7 +179,13 @@ func (s *singleEngine) Close() {
 	defer s.mu.Unlock()
 
 	s.knowledgeLibraries = make(map[string]*ast.KnowledgeLibrary)
+	s.ttls = make(map[string]ruleTTL)
 	s.localCache.Clear()
+	s.refreshMu.Lock()
+	for _, cancel := range s.refreshing {
+		cancel()
+	}
+	s.refreshMu.Unlock()
 	runtime.GC()
 }
 
@@ -111,17 +199,52 @@ func (s *singleEngine) ContainsRule(rule string) bool {
 	return ok
 }
 
-// Note: must use with Mutex
-func (s *singleEngine) addRule(rule, statement string) error {
+// newGruleEngine creates a grule engine notifying the rule listeners of the execution context.
+func newGruleEngine() *engine.GruleEngine {
+	gruleEngine := engine.NewGruleEngine()
+	gruleEngine.Listeners = []engine.GruleEngineListener{contextListener{}}
+	return gruleEngine
+}
 
+// CompileRule builds the GRL statement into a new knowledge library,
+// the same way the engine does when a rule is added. It fails with a *RuleCompileError.
+func CompileRule(statement string) (*ast.KnowledgeLibrary, error) {
 	library := ast.NewKnowledgeLibrary()
 	rb := builder.NewRuleBuilder(library)
 	err := rb.BuildRuleFromResource(LibraryName, LibraryVersion, pkg.NewBytesResource([]byte(statement)))
 	if err != nil {
+		return nil, newRuleCompileError(err)
+	}
+	return library, nil
+}
+
+// ruleCost returns the cost of a rule counted against the memory budget, the size of its statement
+func (s *singleEngine) ruleCost(rule, statement string) (int64, error) {
+	cost := int64(len(statement))
+	// A rule is admitted against the budget of its cache segment, a larger rule would evict the segment
+	if budget := cache.SegmentMaxCost(s.cfg.MaxCost, s.cfg.Shards); budget > 0 && cost > budget {
+		return 0, fmt.Errorf("rule %s costs %d bytes, over the memory budget of %d bytes", rule, cost, budget)
+	}
+	return cost, nil
+}
+
+// Note: must use with Mutex
+func (s *singleEngine) addRule(rule, statement string) (err error) {
+	defer s.recoverPanic(context.Background(), "addRule", rule, &err)
+
+	library, err := CompileRule(statement)
+	if err != nil {
+		var ce *RuleCompileError
+		if errors.As(err, &ce) {
+			ce.Rule = rule
+		}
 		return err
 	}
 
 	s.knowledgeLibraries[rule] = library
+	if s.coverage != nil {
+		s.coverage.register(rule, statement, library)
+	}
 
 	return nil
 }
@@ -131,12 +254,23 @@ func (s *singleEngine) AddRule(rule, statement string, duration int64) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	err := s.addRule(rule, statement)
+	return s.replaceRule(rule, statement, time.Duration(duration))
+}
+
+// replaceRule compiles the statement and replaces the rule with it
+// Note: must use with Mutex
+func (s *singleEngine) replaceRule(rule, statement string, duration time.Duration) error {
+	cost, err := s.ruleCost(rule, statement)
+	if err != nil {
+		return err
+	}
+
+	err = s.addRule(rule, statement)
 	if err != nil {
 		return err
 	}
 
-	s.localCache.Set(rule, nil, time.Duration(duration))
+	s.setRule(rule, cost, duration)
 
 	return nil
 }
@@ -146,83 +280,219 @@ func (s *singleEngine) BuildRule(rule, statement string, duration int64) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, ok := s.knowledgeLibraries[rule]; !ok {
-		err := s.addRule(rule, statement)
-		if err != nil {
-			return err
-		}
+	// The library of an existing rule is not rebuilt, so the rule keeps its cost
+	if _, ok := s.knowledgeLibraries[rule]; ok {
+		s.setRule(rule, s.ttls[rule].cost, time.Duration(duration))
+		return nil
 	}
-	s.localCache.Set(rule, nil, time.Duration(duration))
+
+	cost, err := s.ruleCost(rule, statement)
+	if err != nil {
+		return err
+	}
+	if err := s.addRule(rule, statement); err != nil {
+		return err
+	}
+	s.setRule(rule, cost, time.Duration(duration))
 
 	return nil
 }
 
+// Note: must use with Mutex
+func (s *singleEngine) setRule(rule string, cost int64, duration time.Duration) {
+	s.localCache.SetWithCost(rule, nil, cost, duration)
+
+	ttl := duration
+	if ttl <= 0 {
+		ttl = time.Duration(s.cfg.TTL) * time.Second
+	}
+	s.ttls[rule] = ruleTTL{loaded: time.Now(), ttl: ttl, cost: cost}
+}
+
+// touch records an execution of the rule: with a sliding TTL the cache restarts the TTL of the rule,
+// and a rule executed near its expiration is reloaded in the background.
+// Note: must use with Mutex
+func (s *singleEngine) touch(rule string) {
+	if s.cfg.SlidingTTL {
+		s.localCache.Get(rule)
+	}
+	if s.cfg.RefreshAhead <= 0 || s.cfg.Loader == nil {
+		return
+	}
+
+	t, ok := s.ttls[rule]
+	if !ok || t.ttl <= 0 || time.Since(t.loaded) < time.Duration(float64(t.ttl)*s.cfg.RefreshAhead) {
+		return
+	}
+
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+	if _, ok := s.refreshing[rule]; ok {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.refreshing[rule] = cancel
+	go s.refresh(ctx, rule, t)
+}
+
+// cancelRefresh cancels the reload of the rule in the background, if any
+func (s *singleEngine) cancelRefresh(rule string) {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+	if cancel, ok := s.refreshing[rule]; ok {
+		cancel()
+	}
+}
+
+// record appends the access of the rule to the trace, a write error is returned by Trace.Flush
+func (s *singleEngine) record(rule string) {
+	if s.cfg.Trace != nil {
+		_ = s.cfg.Trace.Record(rule)
+	}
+}
+
+// recoverPanic converts a panic of the rule into a *PanicError returned in err, logs it and counts it.
+// It must be deferred.
+func (s *singleEngine) recoverPanic(ctx context.Context, op, rule string, err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	s.panics.Add(1)
+	pe := &PanicError{Rule: rule, Partition: s.partition, Value: r, Stack: debug.Stack()}
+	s.log(ctx, rule).Errorf("[singleEngine][%s] %v\n%s", op, pe, pe.Stack)
+	*err = pe
+}
+
+// breakerChanged logs the state changes of the circuit breaker of the rule
+func (s *singleEngine) breakerChanged(rule string, from, to BreakerState) {
+	if to == BreakerOpen {
+		s.log(context.Background(), rule).Warnf("[singleEngine][CircuitBreaker] rule %v quarantined, breaker %v -> %v", rule, from, to)
+		return
+	}
+	s.log(context.Background(), rule).Infof("[singleEngine][CircuitBreaker] rule %v breaker %v -> %v", rule, from, to)
+}
+
+// redact formats the fact for the logs with the configured redaction
+func (s *singleEngine) redact(fact any) string {
+	return s.cfg.GetFactRedaction().Redact(fact)
+}
+
+// refresh reloads the statement of the rule from the loader and replaces the rule loaded at t with it,
+// with the same TTL. The rule is not added again when it was removed or replaced during the load.
+func (s *singleEngine) refresh(ctx context.Context, rule string, t ruleTTL) {
+	defer func() {
+		s.refreshMu.Lock()
+		defer s.refreshMu.Unlock()
+		s.refreshing[rule]()
+		delete(s.refreshing, rule)
+	}()
+	defer s.recoverPanic(ctx, "refresh", rule, new(error))
+
+	statement, err := s.cfg.Loader.LoadRule(ctx, rule)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.log(ctx, rule).Errorf("[singleEngine][refresh] load rule %v has error : %v", rule, err)
+		}
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if current, ok := s.ttls[rule]; ctx.Err() != nil || !ok || !current.loaded.Equal(t.loaded) {
+		return
+	}
+	if err := s.replaceRule(rule, statement, t.ttl); err != nil {
+		s.log(ctx, rule).Errorf("[singleEngine][refresh] add rule %v has error : %v", rule, err)
+	}
+}
+
 // Note: must rules exists
-func (s *singleEngine) Execute(ctx context.Context, rule string, fact any) error {
+func (s *singleEngine) Execute(ctx context.Context, rule string, fact any) (err error) {
+	if err := s.breakers.allow(rule); err != nil {
+		return err
+	}
+	defer func() { s.breakers.done(rule, err) }()
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+	defer s.recoverPanic(ctx, "Execute", rule, &err)
+	s.record(rule)
 
 	dataContext := ast.NewDataContext()
-	if err := dataContext.Add(s.factName, fact); err != nil {
-		logger.WithContext(ctx).Errorf("[singleEngine][Execute] add fact %v has error : %v", fact, err)
-		return err
+	if err := addFact(dataContext, s.factName, fact); err != nil {
+		s.log(ctx, rule).Errorf("[singleEngine][Execute] add fact %v has error : %v", s.redact(fact), err)
+		return fmt.Errorf("%w: %w", ErrFactRegistration, err)
 	}
 
 	knowledgeLibrary, ok := s.knowledgeLibraries[rule]
 	if knowledgeLibrary == nil {
-		logger.WithContext(ctx).Errorf("[singleEngine][Execute] knowledge library empty, %v cache hit %v", rule, ok)
-		return errors.New("knowledge library empty")
+		s.log(ctx, rule).Errorf("[singleEngine][Execute] knowledge library empty, %v cache hit %v", rule, ok)
+		return fmt.Errorf("%w: %s", ErrRuleNotFound, rule)
 	}
+	s.touch(rule)
 
 	kb, err := knowledgeLibrary.NewKnowledgeBaseInstance(LibraryName, LibraryVersion)
 	if kb == nil {
-		logger.WithContext(ctx).Errorf("[singleEngine][Execute] knowledge base instance empty")
+		s.log(ctx, rule).Errorf("[singleEngine][Execute] knowledge base instance empty")
 		return errors.New("knowledge base instance empty")
 	}
 	if err != nil {
-		logger.WithContext(ctx).Errorf("[singleEngine][Execute] knowledge base instance error %v", err)
+		s.log(ctx, rule).Errorf("[singleEngine][Execute] knowledge base instance error %v", err)
 		return err
 	}
 
-	err = s.engine.Execute(dataContext, kb)
+	if s.coverage != nil {
+		s.coverage.executed(rule)
+		ctx = withListeners(ctx, rule, s.coverage)
+	} else {
+		ctx = withListeners(ctx, rule)
+	}
+	cycles := &executionCycles{}
+	err = s.engine.ExecuteWithContext(withCycles(ctx, cycles), dataContext, kb)
 	if err != nil {
-		logger.WithContext(ctx).Errorf("[singleEngine][Execute] execute data context fact %v has error : %v", fact, err)
-		return err
+		s.log(ctx, rule).Errorf("[singleEngine][Execute] execute data context fact %v has error : %v", s.redact(fact), err)
+		// Grule stops when a rule entry is still selected after its maximum number of cycles
+		return executionError(err, cycles.selected > s.engine.MaxCycle)
 	}
+	syncFact(dataContext, s.factName, fact)
 
 	return nil
 }
 
 // Note: must rules exists
-func (s *singleEngine) FetchMatching(ctx context.Context, rule string, fact any) ([]*ast.RuleEntry, error) {
+func (s *singleEngine) FetchMatching(ctx context.Context, rule string, fact any) (ruleEntries []*ast.RuleEntry, err error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+	defer s.recoverPanic(ctx, "FetchMatching", rule, &err)
+	s.record(rule)
 
 	dataContext := ast.NewDataContext()
-	if err := dataContext.Add(s.factName, fact); err != nil {
-		logger.WithContext(ctx).Errorf("[singleEngine][FetchMatching] add fact %v has error : %v", fact, err)
-		return nil, err
+	if err := addFact(dataContext, s.factName, fact); err != nil {
+		s.log(ctx, rule).Errorf("[singleEngine][FetchMatching] add fact %v has error : %v", s.redact(fact), err)
+		return nil, fmt.Errorf("%w: %w", ErrFactRegistration, err)
 	}
 
 	knowledgeLibrary, ok := s.knowledgeLibraries[rule]
 	if knowledgeLibrary == nil {
-		logger.WithContext(ctx).Errorf("[singleEngine][FetchMatching] knowledge library empty, %v cache hit %v", rule, ok)
-		return nil, errors.New("knowledge library empty")
+		s.log(ctx, rule).Errorf("[singleEngine][FetchMatching] knowledge library empty, %v cache hit %v", rule, ok)
+		return nil, fmt.Errorf("%w: %s", ErrRuleNotFound, rule)
 	}
+	s.touch(rule)
 
 	kb, err := knowledgeLibrary.NewKnowledgeBaseInstance(LibraryName, LibraryVersion)
 	if kb == nil {
-		logger.WithContext(ctx).Errorf("[singleEngine][FetchMatching] knowledge base instance empty")
+		s.log(ctx, rule).Errorf("[singleEngine][FetchMatching] knowledge base instance empty")
 		return nil, errors.New("knowledge base instance empty")
 	}
 	if err != nil {
-		logger.WithContext(ctx).Errorf("[singleEngine][FetchMatching] knowledge base instance error %v", err)
+		s.log(ctx, rule).Errorf("[singleEngine][FetchMatching] knowledge base instance error %v", err)
 		return nil, err
 	}
 
-	ruleEntries, err := s.engine.FetchMatchingRules(dataContext, kb)
+	ruleEntries, err = s.engine.FetchMatchingRules(dataContext, kb)
 	if err != nil {
-		logger.WithContext(ctx).Errorf("[singleEngine][FetchMatching] execute data context fact %v has error : %v", fact, err)
+		s.log(ctx, rule).Errorf("[singleEngine][FetchMatching] execute data context fact %v has error : %v", s.redact(fact), err)
 		return nil, err
 	}
 
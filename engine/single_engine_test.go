@@ -1,8 +1,16 @@
 package engine
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/hungpdn/grule-plus/cache/common"
+	"github.com/hungpdn/grule-plus/cache/trace"
 )
 
 func TestNewSingleEngine(t *testing.T) {
@@ -19,10 +27,31 @@ func TestNewSingleEngine(t *testing.T) {
 func TestRemoveRule(t *testing.T) {
 	se := NewSingleEngine(Config{})
 	se.knowledgeLibraries["r1"] = nil
+	se.localCache.Set("r1", nil, 0)
 	se.RemoveRule("r1")
 	if _, ok := se.knowledgeLibraries["r1"]; ok {
 		t.Fatalf("RemoveRule did not remove rule")
 	}
+	if se.localCache.Has("r1") {
+		t.Fatalf("RemoveRule did not remove rule from localCache")
+	}
+}
+
+func TestEvictRule(t *testing.T) {
+	se := NewSingleEngine(Config{})
+	se.knowledgeLibraries["r1"] = nil
+	se.knowledgeLibraries["r2"] = nil
+	se.localCache.Set("r2", nil, 0)
+
+	// r2 was added again after its eviction, so it stays in the libraries
+	se.evictRule("r1", common.EvictionEvent)
+	se.evictRule("r2", common.EvictionEvent)
+	if _, ok := se.knowledgeLibraries["r1"]; ok {
+		t.Fatalf("evictRule did not remove rule r1")
+	}
+	if _, ok := se.knowledgeLibraries["r2"]; !ok {
+		t.Fatalf("evictRule removed rule r2 still in localCache")
+	}
 }
 
 func TestDebug(t *testing.T) {
@@ -108,3 +137,420 @@ func TestExecuteAndFetchMatching(t *testing.T) {
 		t.Fatalf("Execute should error for missing rule")
 	}
 }
+
+func TestListRules(t *testing.T) {
+	se := NewSingleEngine(Config{})
+	se.knowledgeLibraries["r2"] = nil
+	se.knowledgeLibraries["r1"] = nil
+	rules := se.ListRules()
+	if len(rules) != 2 || rules[0] != "r1" || rules[1] != "r2" {
+		t.Fatalf("ListRules want [r1 r2] got %v", rules)
+	}
+}
+
+func TestExecuteJSONFact(t *testing.T) {
+	se := NewSingleEngine(Config{FactName: "DiscountFact"})
+	statement := `rule DiscountRule "Apply discount" salience 10 {
+				when
+					DiscountFact.Amount > 100
+				then
+					DiscountFact.Discount = 10;
+					Retract("DiscountRule"); }
+				`
+	if err := se.AddRule("r1", statement, 0); err != nil {
+		t.Fatalf("AddRule error: %v", err)
+	}
+	values := map[string]any{"Amount": 150, "Discount": 0}
+	fact := NewJSONFact(values)
+	if err := se.Execute(context.Background(), "r1", fact); err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+	if fact.Result["Discount"] != int64(10) {
+		t.Fatalf("Discount want 10 got %v (%T)", fact.Result["Discount"], fact.Result["Discount"])
+	}
+	// The values of the caller are not modified
+	if values["Amount"] != 150 || values["Discount"] != 0 {
+		t.Fatalf("Execute should not modify the values, got %v", values)
+	}
+	ruleEntries, err := se.FetchMatching(context.Background(), "r1", NewJSONFact(map[string]any{"Amount": 150}))
+	if err != nil || len(ruleEntries) != 1 {
+		t.Fatalf("FetchMatching want 1 entry got %d, err %v", len(ruleEntries), err)
+	}
+
+	// Other maps are added as they are, a rule which does not match leaves them untouched
+	m := map[string]any{"Amount": 50, "Discount": 0}
+	if err := se.Execute(context.Background(), "r1", m); err != nil {
+		t.Fatalf("Execute map error: %v", err)
+	}
+	if m["Amount"] != 50 || m["Discount"] != 0 {
+		t.Fatalf("Execute should not convert the map values, got %v", m)
+	}
+}
+
+func TestMaxCost(t *testing.T) {
+	statement := `rule DiscountRule "Apply discount" salience 10 {
+				when
+					DiscountFact.Amount > 100
+				then
+					DiscountFact.Discount = 10; }
+				`
+	cost := int64(len(statement))
+	se := NewSingleEngine(Config{MaxCost: 3 * cost})
+
+	for _, rule := range []string{"r1", "r2", "r3", "r4"} {
+		if err := se.AddRule(rule, statement, 0); err != nil {
+			t.Fatalf("AddRule %s error: %v", rule, err)
+		}
+	}
+	if se.localCache.Len() != 3 || se.localCache.Cost() != 3*cost || se.localCache.Has("r1") {
+		t.Fatalf("want r1 evicted to fit the budget, len %d cost %d", se.localCache.Len(), se.localCache.Cost())
+	}
+
+	// The eviction callback removes the rule from the libraries asynchronously
+	deadline := time.Now().Add(time.Second)
+	for se.ContainsRule("r1") && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if se.ContainsRule("r1") {
+		t.Fatalf("evicted rule r1 should be removed from the libraries")
+	}
+
+	if err := se.AddRule("big", strings.Repeat(" ", int(3*cost))+statement, 0); err == nil {
+		t.Fatalf("AddRule over the memory budget should error")
+	}
+	if se.ContainsRule("big") {
+		t.Fatalf("rule over the memory budget should not be added")
+	}
+
+	// BuildRule does not rebuild an existing rule, which keeps its cost
+	if err := se.BuildRule("r2", strings.Repeat(" ", int(cost))+statement, 0); err != nil {
+		t.Fatalf("BuildRule error: %v", err)
+	}
+	if se.localCache.Cost() != 3*cost {
+		t.Fatalf("BuildRule of an existing rule should keep its cost, cost %d want %d", se.localCache.Cost(), 3*cost)
+	}
+}
+
+func TestMaxCostShards(t *testing.T) {
+	statement := `rule DiscountRule "Apply discount" salience 10 {
+				when
+					DiscountFact.Amount > 100
+				then
+					DiscountFact.Discount = 10; }
+				`
+	cost := int64(len(statement))
+	se := NewSingleEngine(Config{MaxCost: 8 * cost, Shards: 4})
+
+	// Two rules fit in any segment, wherever they are hashed
+	for _, rule := range []string{"r1", "r2"} {
+		if err := se.AddRule(rule, statement, 0); err != nil {
+			t.Fatalf("AddRule %s error: %v", rule, err)
+		}
+	}
+
+	// A rule within the budget of the engine but over the budget of a segment is rejected
+	// instead of evicting the other rules of its segment
+	big := strings.Repeat(" ", int(3*cost)) + statement
+	if err := se.AddRule("big", big, 0); err == nil || !strings.Contains(err.Error(), fmt.Sprintf("budget of %d bytes", 2*cost)) {
+		t.Fatalf("AddRule over the segment budget want error got %v", err)
+	}
+	if err := se.BuildRule("big", big, 0); err == nil {
+		t.Fatalf("BuildRule over the segment budget should error")
+	}
+	if se.ContainsRule("big") || se.localCache.Len() != 2 || se.localCache.Cost() != 2*cost {
+		t.Fatalf("want the 2 rules kept, len %d cost %d", se.localCache.Len(), se.localCache.Cost())
+	}
+}
+
+func TestSlidingTTL(t *testing.T) {
+	se := NewSingleEngine(Config{FactName: "DiscountFact", SlidingTTL: true})
+	statement := `rule DiscountRule "Apply discount" salience 10 {
+				when
+					DiscountFact.Amount > 100
+				then
+					DiscountFact.Discount = 10;
+					Retract("DiscountRule"); }
+				`
+	if err := se.AddRule("r1", statement, int64(60*time.Millisecond)); err != nil {
+		t.Fatalf("AddRule error: %v", err)
+	}
+
+	// Each execution restarts the TTL of the rule
+	for i := 0; i < 6; i++ {
+		time.Sleep(20 * time.Millisecond)
+		if err := se.Execute(context.Background(), "r1", NewJSONFact(map[string]any{"Amount": 150})); err != nil {
+			t.Fatalf("Execute error: %v", err)
+		}
+	}
+	if !se.localCache.Has("r1") {
+		t.Fatalf("rule executed within its TTL should not expire")
+	}
+
+	time.Sleep(80 * time.Millisecond)
+	if se.localCache.Has("r1") {
+		t.Fatalf("rule should expire 60ms after its last execution")
+	}
+}
+
+func TestReadBufferAndSample(t *testing.T) {
+	statement := `rule DiscountRule "Apply discount" salience 10 {
+				when
+					DiscountFact.Amount > 100
+				then
+					DiscountFact.Discount = 10;
+					Retract("DiscountRule"); }
+				`
+	// The hits restart the sliding TTL only when the buffer of the segment is full, or when they are sampled
+	for _, tc := range []struct {
+		readBuffer int
+		readSample int
+		want       bool
+	}{{2, 0, true}, {3, 0, false}, {0, 1 << 30, false}} {
+		cfg := Config{FactName: "DiscountFact", SlidingTTL: true, Shards: 2, ReadBuffer: tc.readBuffer, ReadSample: tc.readSample}
+		se := NewSingleEngine(cfg)
+		cfg.Partition = 2
+		pe := NewPartitionEngine(cfg, nil)
+		for _, eng := range []IGruleEngine{se, pe} {
+			if err := eng.AddRule("r1", statement, int64(100*time.Millisecond)); err != nil {
+				t.Fatalf("AddRule error: %v", err)
+			}
+		}
+
+		for i := 0; i < 2; i++ {
+			time.Sleep(20 * time.Millisecond)
+			for _, eng := range []IGruleEngine{se, pe} {
+				if err := eng.Execute(context.Background(), "r1", &errorsFact{}); err != nil {
+					t.Fatalf("Execute error: %v", err)
+				}
+			}
+		}
+		time.Sleep(80 * time.Millisecond)
+		if got := se.localCache.Has("r1"); got != tc.want {
+			t.Fatalf("%+v: single engine want r1 cached %v got %v", tc, tc.want, got)
+		}
+		if got := pe.engines[pe.hash("r1")].localCache.Has("r1"); got != tc.want {
+			t.Fatalf("%+v: partition engine want r1 cached %v got %v", tc, tc.want, got)
+		}
+	}
+}
+
+func TestRefreshAhead(t *testing.T) {
+	statement := `rule DiscountRule "Apply discount" salience 10 {
+				when
+					DiscountFact.Amount > 100
+				then
+					DiscountFact.Discount = %d;
+					Retract("DiscountRule"); }
+				`
+	var mu sync.Mutex
+	loads := 0
+	loader := RuleLoaderFunc(func(ctx context.Context, rule string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		loads++
+		return fmt.Sprintf(statement, 20), nil
+	})
+	se := NewSingleEngine(Config{FactName: "DiscountFact", RefreshAhead: 0.5, Loader: loader})
+	if err := se.AddRule("r1", fmt.Sprintf(statement, 10), int64(200*time.Millisecond)); err != nil {
+		t.Fatalf("AddRule error: %v", err)
+	}
+	discount := func() any {
+		fact := NewJSONFact(map[string]any{"Amount": 150, "Discount": 0})
+		if err := se.Execute(context.Background(), "r1", fact); err != nil {
+			t.Fatalf("Execute error: %v", err)
+		}
+		return fact.Result["Discount"]
+	}
+
+	// Executions early in the TTL do not reload the rule
+	if d := discount(); d != int64(10) {
+		t.Fatalf("Discount want 10 got %v", d)
+	}
+	time.Sleep(120 * time.Millisecond)
+	discount()
+
+	deadline := time.Now().Add(time.Second)
+	for discount() != int64(20) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if loads != 1 {
+		t.Fatalf("rule executed near its expiration should be loaded once, loads %d", loads)
+	}
+	if !se.localCache.Has("r1") {
+		t.Fatalf("refreshed rule should restart its TTL")
+	}
+}
+
+func TestRefreshAheadRemovedRule(t *testing.T) {
+	statement := `rule DiscountRule "Apply discount" salience 10 {
+				when
+					DiscountFact.Amount > 100
+				then
+					DiscountFact.Discount = 10;
+					Retract("DiscountRule"); }
+				`
+	loading, release := make(chan struct{}), make(chan struct{})
+	canceled := make(chan bool, 1)
+	// The loader ignores the cancellation and returns the statement anyway
+	loader := RuleLoaderFunc(func(ctx context.Context, rule string) (string, error) {
+		close(loading)
+		<-release
+		canceled <- ctx.Err() != nil
+		return statement, nil
+	})
+	se := NewSingleEngine(Config{FactName: "DiscountFact", RefreshAhead: 0.1, Loader: loader})
+	if err := se.AddRule("r1", statement, int64(time.Second)); err != nil {
+		t.Fatalf("AddRule error: %v", err)
+	}
+	time.Sleep(120 * time.Millisecond)
+	if err := se.Execute(context.Background(), "r1", &errorsFact{}); err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+
+	// The rule is removed while it is reloaded
+	<-loading
+	se.RemoveRule("r1")
+	close(release)
+	if !<-canceled {
+		t.Fatalf("RemoveRule should cancel the reload of the rule")
+	}
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		se.refreshMu.Lock()
+		n := len(se.refreshing)
+		se.refreshMu.Unlock()
+		if n == 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if se.ContainsRule("r1") || se.localCache.Has("r1") {
+		t.Fatalf("reload should not add the removed rule again")
+	}
+}
+
+func TestRefreshAheadClose(t *testing.T) {
+	statement := `rule DiscountRule "Apply discount" salience 10 {
+				when
+					DiscountFact.Amount > 100
+				then
+					DiscountFact.Discount = 10;
+					Retract("DiscountRule"); }
+				`
+	loader := RuleLoaderFunc(func(ctx context.Context, rule string) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	se := NewSingleEngine(Config{FactName: "DiscountFact", RefreshAhead: 0.1, Loader: loader})
+	rules := []string{"r1", "r2", "r3"}
+	for _, rule := range rules {
+		if err := se.AddRule(rule, statement, int64(time.Second)); err != nil {
+			t.Fatalf("AddRule error: %v", err)
+		}
+	}
+	time.Sleep(120 * time.Millisecond)
+	for _, rule := range rules {
+		if err := se.Execute(context.Background(), rule, &errorsFact{}); err != nil {
+			t.Fatalf("Execute error: %v", err)
+		}
+	}
+
+	// Close cancels the running reloads, which remove themselves from the engine concurrently
+	se.Close()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		se.refreshMu.Lock()
+		n := len(se.refreshing)
+		se.refreshMu.Unlock()
+		if n == 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	for _, rule := range rules {
+		if se.ContainsRule(rule) {
+			t.Fatalf("reload should not add the rule %s again after Close", rule)
+		}
+	}
+}
+
+func TestOnRuleEvicted(t *testing.T) {
+	statement := `rule DiscountRule "Apply discount" salience 10 {
+				when
+					DiscountFact.Amount > 100
+				then
+					DiscountFact.Discount = 10; }
+				`
+	se := NewSingleEngine(Config{Size: 1})
+
+	var evicted, deleted []string
+	se.OnRuleEvicted(RuleEvictedHook{
+		Func:   func(rule string, event int) { evicted = append(evicted, rule) },
+		Events: []int{common.EvictionEvent},
+	})
+	se.OnRuleEvicted(RuleEvictedHook{
+		Func:   func(rule string, event int) { deleted = append(deleted, rule) },
+		Events: []int{common.DeleteEvent},
+	})
+	done := make(chan string, 2)
+	se.OnRuleEvicted(RuleEvictedHook{
+		Func:  func(rule string, event int) { done <- rule },
+		Async: true,
+	})
+
+	for _, rule := range []string{"r1", "r2"} {
+		if err := se.AddRule(rule, statement, 0); err != nil {
+			t.Fatalf("AddRule %s error: %v", rule, err)
+		}
+	}
+	se.RemoveRule("r2")
+	if len(evicted) != 1 || evicted[0] != "r1" || len(deleted) != 1 || deleted[0] != "r2" {
+		t.Fatalf("want r1 evicted and r2 deleted, got %v %v", evicted, deleted)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("asynchronous hook not called for every event")
+		}
+	}
+}
+
+func TestTrace(t *testing.T) {
+	statement := `rule DiscountRule "Apply discount" salience 10 {
+				when
+					DiscountFact.Amount > 100
+				then
+					DiscountFact.Discount = 10;
+					Retract("DiscountRule"); }
+				`
+	var buf bytes.Buffer
+	recorder, err := trace.NewWriter(&buf)
+	if err != nil {
+		t.Fatalf("NewWriter error: %v", err)
+	}
+	se := NewSingleEngine(Config{FactName: "DiscountFact", Trace: recorder})
+	if err := se.AddRule("r1", statement, 0); err != nil {
+		t.Fatalf("AddRule error: %v", err)
+	}
+
+	// Executions of missing rules are recorded too, as they would miss the cache
+	fact := NewJSONFact(map[string]any{"Amount": 150, "Discount": 0})
+	_ = se.Execute(context.Background(), "r1", fact)
+	_ = se.Execute(context.Background(), "missing", fact)
+	_, _ = se.FetchMatching(context.Background(), "r1", fact)
+	if err := recorder.Flush(); err != nil {
+		t.Fatalf("Flush error: %v", err)
+	}
+
+	keys, err := trace.Read(&buf)
+	if err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	if strings.Join(keys, ",") != "r1,missing,r1" {
+		t.Fatalf("want [r1 missing r1] got %v", keys)
+	}
+}
@@ -0,0 +1,49 @@
+package grpcserver
+
+import (
+	"errors"
+	"fmt"
+
+	gruleplusv1 "github.com/hungpdn/grule-plus/api/gruleplus/v1"
+	"github.com/hungpdn/grule-plus/engine"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+var (
+	errFactRequired = errors.New("fact is required")
+)
+
+// decodeFact converts a protobuf fact into a value the engine can evaluate.
+// Struct facts become JSON facts, Any facts are unpacked into their registered message type.
+func decodeFact(fact *gruleplusv1.Fact) (any, error) {
+	switch kind := fact.GetKind().(type) {
+	case *gruleplusv1.Fact_Struct:
+		return engine.NewJSONFact(kind.Struct.AsMap()), nil
+	case *gruleplusv1.Fact_Any:
+		return kind.Any.UnmarshalNew()
+	default:
+		return nil, errFactRequired
+	}
+}
+
+// encodeFact converts an evaluated fact back into its protobuf form.
+func encodeFact(fact any) (*gruleplusv1.Fact, error) {
+	switch f := fact.(type) {
+	case *engine.JSONFact:
+		st, err := structpb.NewStruct(f.Result)
+		if err != nil {
+			return nil, err
+		}
+		return &gruleplusv1.Fact{Kind: &gruleplusv1.Fact_Struct{Struct: st}}, nil
+	case proto.Message:
+		a, err := anypb.New(f)
+		if err != nil {
+			return nil, err
+		}
+		return &gruleplusv1.Fact{Kind: &gruleplusv1.Fact_Any{Any: a}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported fact type %T", fact)
+	}
+}
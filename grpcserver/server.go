@@ -0,0 +1,170 @@
+// grpcserver implements the gruleplus.v1 RuleEngineService over an IGruleEngine.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	gruleplusv1 "github.com/hungpdn/grule-plus/api/gruleplus/v1"
+	"github.com/hungpdn/grule-plus/engine"
+	"github.com/hungpdn/grule-plus/internal/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// CorrelationIdMetadataKey is the gRPC metadata key carrying the correlation ID.
+const CorrelationIdMetadataKey = "x-correlation-id"
+
+// Server implements gruleplusv1.RuleEngineServiceServer.
+type Server struct {
+	gruleplusv1.UnimplementedRuleEngineServiceServer
+	engine engine.IGruleEngine
+}
+
+// New creates a new Server for the given engine.
+func New(eng engine.IGruleEngine) *Server {
+	return &Server{engine: eng}
+}
+
+// Register registers the service on the given gRPC server.
+func (s *Server) Register(registrar grpc.ServiceRegistrar) {
+	gruleplusv1.RegisterRuleEngineServiceServer(registrar, s)
+}
+
+// AddRule adds or builds a rule.
+func (s *Server) AddRule(ctx context.Context, req *gruleplusv1.AddRuleRequest) (*gruleplusv1.AddRuleResponse, error) {
+	ctx = withCorrelationID(ctx)
+	if req.GetName() == "" || req.GetStatement() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name and statement are required")
+	}
+
+	var err error
+	if req.GetBuildOnly() {
+		err = s.engine.BuildRule(req.GetName(), req.GetStatement(), req.GetDuration())
+	} else {
+		err = s.engine.AddRule(req.GetName(), req.GetStatement(), req.GetDuration())
+	}
+	if err != nil {
+		logger.WithContext(ctx).Errorf("[grpcserver][AddRule] add rule %v has error : %v", req.GetName(), err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return &gruleplusv1.AddRuleResponse{Name: req.GetName()}, nil
+}
+
+// RemoveRule removes a rule.
+func (s *Server) RemoveRule(ctx context.Context, req *gruleplusv1.RemoveRuleRequest) (*gruleplusv1.RemoveRuleResponse, error) {
+	if !s.engine.ContainsRule(req.GetName()) {
+		return nil, status.Errorf(codes.NotFound, "rule %s not found", req.GetName())
+	}
+	s.engine.RemoveRule(req.GetName())
+	return &gruleplusv1.RemoveRuleResponse{}, nil
+}
+
+// ListRules lists the rules of the engine.
+func (s *Server) ListRules(ctx context.Context, req *gruleplusv1.ListRulesRequest) (*gruleplusv1.ListRulesResponse, error) {
+	return &gruleplusv1.ListRulesResponse{Rules: s.engine.ListRules()}, nil
+}
+
+// Execute runs a rule against a fact and returns the updated fact.
+func (s *Server) Execute(ctx context.Context, req *gruleplusv1.ExecuteRequest) (*gruleplusv1.ExecuteResponse, error) {
+	return s.execute(withCorrelationID(ctx), req)
+}
+
+// FetchMatching returns the rule entries matching a fact.
+func (s *Server) FetchMatching(ctx context.Context, req *gruleplusv1.FetchMatchingRequest) (*gruleplusv1.FetchMatchingResponse, error) {
+	ctx = withCorrelationID(ctx)
+	if !s.engine.ContainsRule(req.GetRule()) {
+		return nil, status.Errorf(codes.NotFound, "rule %s not found", req.GetRule())
+	}
+	fact, err := decodeFact(req.GetFact())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ruleEntries, err := s.engine.FetchMatching(ctx, req.GetRule(), fact)
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	entries := make([]*gruleplusv1.RuleEntry, 0, len(ruleEntries))
+	for _, ruleEntry := range ruleEntries {
+		entries = append(entries, &gruleplusv1.RuleEntry{
+			Name:        ruleEntry.RuleName,
+			Description: ruleEntry.RuleDescription,
+			Salience:    int32(ruleEntry.Salience),
+		})
+	}
+	return &gruleplusv1.FetchMatchingResponse{Rule: req.GetRule(), Entries: entries}, nil
+}
+
+// ExecuteBatch executes each streamed request. A failed request does not end the stream,
+// its error is reported in the response instead.
+func (s *Server) ExecuteBatch(stream grpc.BidiStreamingServer[gruleplusv1.ExecuteRequest, gruleplusv1.ExecuteResponse]) error {
+	ctx := withCorrelationID(stream.Context())
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		resp, err := s.execute(ctx, req)
+		if err != nil {
+			resp = &gruleplusv1.ExecuteResponse{Rule: req.GetRule(), Error: status.Convert(err).Message()}
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// execute runs a single execute request.
+func (s *Server) execute(ctx context.Context, req *gruleplusv1.ExecuteRequest) (*gruleplusv1.ExecuteResponse, error) {
+	if !s.engine.ContainsRule(req.GetRule()) {
+		return nil, status.Errorf(codes.NotFound, "rule %s not found", req.GetRule())
+	}
+	fact, err := decodeFact(req.GetFact())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.engine.Execute(ctx, req.GetRule(), fact); err != nil {
+		return nil, status.Error(executeCode(err), err.Error())
+	}
+
+	result, err := encodeFact(fact)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &gruleplusv1.ExecuteResponse{Rule: req.GetRule(), Fact: result}, nil
+}
+
+// executeCode returns the status code of an execution error: ResourceExhausted for a rule over its
+// rate limit, Unavailable for a quarantined rule, which may be executed again later, and
+// FailedPrecondition otherwise.
+func executeCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, engine.ErrRateLimited):
+		return codes.ResourceExhausted
+	case errors.Is(err, engine.ErrRuleQuarantined):
+		return codes.Unavailable
+	default:
+		return codes.FailedPrecondition
+	}
+}
+
+// withCorrelationID reuses the correlation ID of the incoming metadata or generates a new one.
+func withCorrelationID(ctx context.Context) context.Context {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(CorrelationIdMetadataKey); len(values) > 0 && logger.ValidCorrelationID(values[0]) {
+			ctx = context.WithValue(ctx, logger.CorrelationIdCtxKey, values[0])
+		}
+	}
+	return logger.SetCorrelationIdToCtx(ctx)
+}
@@ -0,0 +1,201 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	gruleplusv1 "github.com/hungpdn/grule-plus/api/gruleplus/v1"
+	"github.com/hungpdn/grule-plus/engine"
+	discountpb "github.com/hungpdn/grule-plus/examples/protobuf"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const discountStatement = `rule DiscountRule "Apply discount" salience 10 {
+	when
+		Fact.Amount > 100
+	then
+		Fact.Discount = 20;
+		Retract("DiscountRule");
+}`
+
+func newTestClient(t *testing.T) gruleplusv1.RuleEngineServiceClient {
+	t.Helper()
+	grule := engine.NewPartitionEngine(engine.Config{Type: engine.LRU, Size: 100, Partition: 1}, nil)
+
+	listener := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	New(grule).Register(srv)
+	go func() {
+		_ = srv.Serve(listener)
+	}()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial bufnet: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+		srv.Stop()
+		grule.Close()
+	})
+
+	client := gruleplusv1.NewRuleEngineServiceClient(conn)
+	if _, err := client.AddRule(context.Background(), &gruleplusv1.AddRuleRequest{Name: "DiscountRule", Statement: discountStatement}); err != nil {
+		t.Fatalf("AddRule error: %v", err)
+	}
+	return client
+}
+
+func structFact(t *testing.T, m map[string]any) *gruleplusv1.Fact {
+	t.Helper()
+	st, err := structpb.NewStruct(m)
+	if err != nil {
+		t.Fatalf("new struct: %v", err)
+	}
+	return &gruleplusv1.Fact{Kind: &gruleplusv1.Fact_Struct{Struct: st}}
+}
+
+func TestAddListAndRemoveRule(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	_, err := client.AddRule(ctx, &gruleplusv1.AddRuleRequest{Name: "Broken", Statement: "rule Broken {"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("invalid statement code want %v got %v", codes.InvalidArgument, status.Code(err))
+	}
+
+	list, err := client.ListRules(ctx, &gruleplusv1.ListRulesRequest{})
+	if err != nil || len(list.GetRules()) != 1 || list.GetRules()[0] != "DiscountRule" {
+		t.Fatalf("unexpected rules %v, err %v", list.GetRules(), err)
+	}
+
+	if _, err := client.RemoveRule(ctx, &gruleplusv1.RemoveRuleRequest{Name: "DiscountRule"}); err != nil {
+		t.Fatalf("RemoveRule error: %v", err)
+	}
+	_, err = client.RemoveRule(ctx, &gruleplusv1.RemoveRuleRequest{Name: "DiscountRule"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("remove missing rule code want %v got %v", codes.NotFound, status.Code(err))
+	}
+}
+
+func TestExecuteStruct(t *testing.T) {
+	client := newTestClient(t)
+
+	resp, err := client.Execute(context.Background(), &gruleplusv1.ExecuteRequest{
+		Rule: "DiscountRule",
+		Fact: structFact(t, map[string]any{"Amount": 150, "Discount": 0}),
+	})
+	if err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+	if got := resp.GetFact().GetStruct().GetFields()["Discount"].GetNumberValue(); got != 20 {
+		t.Fatalf("Discount want 20 got %v", got)
+	}
+}
+
+func TestExecuteAny(t *testing.T) {
+	client := newTestClient(t)
+
+	fact, err := anypb.New(&discountpb.DiscountFact{Amount: 150})
+	if err != nil {
+		t.Fatalf("new any: %v", err)
+	}
+	resp, err := client.Execute(context.Background(), &gruleplusv1.ExecuteRequest{
+		Rule: "DiscountRule",
+		Fact: &gruleplusv1.Fact{Kind: &gruleplusv1.Fact_Any{Any: fact}},
+	})
+	if err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+
+	var result discountpb.DiscountFact
+	if err := resp.GetFact().GetAny().UnmarshalTo(&result); err != nil {
+		t.Fatalf("unmarshal result: %v", err)
+	}
+	if result.Discount != 20 {
+		t.Fatalf("Discount want 20 got %v", result.Discount)
+	}
+}
+
+func TestExecuteErrors(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	_, err := client.Execute(ctx, &gruleplusv1.ExecuteRequest{Rule: "Missing", Fact: structFact(t, map[string]any{})})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("missing rule code want %v got %v", codes.NotFound, status.Code(err))
+	}
+
+	_, err = client.Execute(ctx, &gruleplusv1.ExecuteRequest{Rule: "DiscountRule"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("missing fact code want %v got %v", codes.InvalidArgument, status.Code(err))
+	}
+}
+
+func TestFetchMatching(t *testing.T) {
+	client := newTestClient(t)
+
+	resp, err := client.FetchMatching(context.Background(), &gruleplusv1.FetchMatchingRequest{
+		Rule: "DiscountRule",
+		Fact: structFact(t, map[string]any{"Amount": 150}),
+	})
+	if err != nil {
+		t.Fatalf("FetchMatching error: %v", err)
+	}
+	if len(resp.GetEntries()) != 1 || resp.GetEntries()[0].GetName() != "DiscountRule" || resp.GetEntries()[0].GetSalience() != 10 {
+		t.Fatalf("unexpected entries %v", resp.GetEntries())
+	}
+}
+
+func TestExecuteBatch(t *testing.T) {
+	client := newTestClient(t)
+
+	stream, err := client.ExecuteBatch(context.Background())
+	if err != nil {
+		t.Fatalf("ExecuteBatch error: %v", err)
+	}
+	requests := []*gruleplusv1.ExecuteRequest{
+		{Rule: "DiscountRule", Fact: structFact(t, map[string]any{"Amount": 150})},
+		{Rule: "Missing", Fact: structFact(t, map[string]any{"Amount": 150})},
+		{Rule: "DiscountRule", Fact: structFact(t, map[string]any{"Amount": 50})},
+	}
+	for _, req := range requests {
+		if err := stream.Send(req); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatalf("close send: %v", err)
+	}
+
+	responses := make([]*gruleplusv1.ExecuteResponse, 0, len(requests))
+	for range requests {
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("recv: %v", err)
+		}
+		responses = append(responses, resp)
+	}
+
+	if got := responses[0].GetFact().GetStruct().GetFields()["Discount"].GetNumberValue(); got != 20 {
+		t.Fatalf("first Discount want 20 got %v", got)
+	}
+	if responses[1].GetError() == "" {
+		t.Fatalf("second response should report an error")
+	}
+	if _, ok := responses[2].GetFact().GetStruct().GetFields()["Discount"]; ok {
+		t.Fatalf("third fact should not get a discount")
+	}
+}
@@ -3,6 +3,8 @@ package logger
 import (
 	"context"
 	"crypto/rand"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/oklog/ulid/v2"
@@ -32,6 +34,25 @@ func SetCorrelationIdToCtx(ctx context.Context) context.Context {
 	return context.WithValue(ctx, CorrelationIdCtxKey, NewCorrelationID())
 }
 
+// MaxCorrelationIDLength is the maximum length of a correlation ID received from a client.
+const MaxCorrelationIDLength = 64
+
+// ValidCorrelationID reports whether a correlation ID received from a client can be written to the
+// logs: at most MaxCorrelationIDLength letters, digits, '-', '_' or '.', such as a ULID or a UUID.
+func ValidCorrelationID(id string) bool {
+	if id == "" || len(id) > MaxCorrelationIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		switch c := id[i]; {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 // GetStringFromCtx retrieves a string value from the context using the specified key.
 func GetStringFromCtx(ctx context.Context, key ContextKey) string {
 	if ctx != nil {
@@ -48,3 +69,69 @@ func NewCorrelationID() string {
 	entropy := ulid.Monotonic(rand.Reader, 0)
 	return ulid.MustNew(ulid.Timestamp(t), entropy).String()
 }
+
+// ContextExtractor returns the attributes of a context added to the logs, nil when it has none.
+type ContextExtractor func(ctx context.Context) map[string]any
+
+// namedExtractor is a registered context extractor and its name
+type namedExtractor struct {
+	name    string
+	extract ContextExtractor
+}
+
+// extractors are the registered context extractors, in registration order
+var (
+	extractors   = []namedExtractor{{CorrelationIdCtxKey.String(), correlationIdExtractor}}
+	extractorsMu sync.RWMutex
+)
+
+// correlationIdExtractor extracts the correlation ID of the context.
+func correlationIdExtractor(ctx context.Context) map[string]any {
+	if correlationId, ok := ctx.Value(CorrelationIdCtxKey).(string); ok {
+		return map[string]any{CorrelationIdCtxKey.String(): correlationId}
+	}
+	return nil
+}
+
+// RegisterContextExtractor registers an extractor of context attributes under the name, replacing the
+// extractor already registered under it. The correlation ID extractor is registered as "correlation_id".
+func RegisterContextExtractor(name string, extractor ContextExtractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+
+	registered := slices.Clone(extractors)
+	if i := slices.IndexFunc(registered, func(e namedExtractor) bool { return e.name == name }); i >= 0 {
+		registered[i].extract = extractor
+	} else {
+		registered = append(registered, namedExtractor{name, extractor})
+	}
+	extractors = registered
+}
+
+// UnregisterContextExtractor removes the extractor registered under the name.
+func UnregisterContextExtractor(name string) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+
+	extractors = slices.DeleteFunc(slices.Clone(extractors), func(e namedExtractor) bool { return e.name == name })
+}
+
+// ContextAttrs returns the attributes of the context added to the logs by the registered extractors.
+// The attributes of an extractor override the ones of the extractors registered before it.
+func ContextAttrs(ctx context.Context) Attrs {
+	attrs := Attrs{}
+	if ctx == nil {
+		return attrs
+	}
+
+	extractorsMu.RLock()
+	registered := extractors
+	extractorsMu.RUnlock()
+
+	for _, e := range registered {
+		for k, v := range e.extract(ctx) {
+			attrs[k] = v
+		}
+	}
+	return attrs
+}
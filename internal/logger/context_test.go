@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type tenantKey struct{}
+
+func TestValidCorrelationID(t *testing.T) {
+	for _, id := range []string{NewCorrelationID(), "3f2b8c1e-9d4a-4f6b-8e2a-1c5d7e9f0a3b", "req_1.retry"} {
+		if !ValidCorrelationID(id) {
+			t.Fatalf("%q should be valid", id)
+		}
+	}
+	for _, id := range []string{"", "a b", "id\nforged log line", "ü", strings.Repeat("a", MaxCorrelationIDLength+1)} {
+		if ValidCorrelationID(id) {
+			t.Fatalf("%q should be invalid", id)
+		}
+	}
+}
+
+func TestContextAttrs(t *testing.T) {
+	ctx := context.WithValue(context.Background(), CorrelationIdCtxKey, "id-1")
+	if attrs := ContextAttrs(ctx); len(attrs) != 1 || attrs["correlation_id"] != "id-1" {
+		t.Fatalf("want the correlation ID got %v", attrs)
+	}
+
+	RegisterContextExtractor("tenant", func(ctx context.Context) map[string]any {
+		if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+			return map[string]any{"tenant_id": tenant}
+		}
+		return nil
+	})
+	defer UnregisterContextExtractor("tenant")
+
+	ctx = context.WithValue(ctx, tenantKey{}, "acme")
+	if attrs := ContextAttrs(ctx); len(attrs) != 2 || attrs["tenant_id"] != "acme" || attrs["correlation_id"] != "id-1" {
+		t.Fatalf("want the tenant and correlation IDs got %v", attrs)
+	}
+
+	// Registering under the same name replaces the extractor, the later extractors override the attributes
+	RegisterContextExtractor("tenant", func(ctx context.Context) map[string]any {
+		return map[string]any{"tenant_id": "other", "correlation_id": "overridden"}
+	})
+	if attrs := ContextAttrs(ctx); len(attrs) != 2 || attrs["tenant_id"] != "other" || attrs["correlation_id"] != "overridden" {
+		t.Fatalf("want the attributes of the replaced extractor got %v", attrs)
+	}
+
+	UnregisterContextExtractor("tenant")
+	if attrs := ContextAttrs(ctx); len(attrs) != 1 {
+		t.Fatalf("unregistered extractor should not add attributes, got %v", attrs)
+	}
+	if attrs := ContextAttrs(nil); len(attrs) != 0 {
+		t.Fatalf("nil context should have no attributes, got %v", attrs)
+	}
+}
@@ -0,0 +1,179 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// Component is a part of grule-plus whose logs can have their own level.
+type Component string
+
+// Define the components.
+const (
+	ComponentEngine    Component = "engine"
+	ComponentCache     Component = "cache"
+	ComponentPartition Component = "partition"
+)
+
+// Components are the components whose level can be overridden.
+var Components = []Component{ComponentEngine, ComponentCache, ComponentPartition}
+
+// String returns the name of the level: debug, info, warn or error.
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+}
+
+// ParseLevel returns the level of the name: debug, info, warn or error, in any case.
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return LevelInfo, fmt.Errorf("unknown log level %q", name)
+	}
+}
+
+// LevelController holds the level of the logs and the levels overridden per component.
+// Levels are stored in slog.LevelVar, so they can be read and changed concurrently.
+type LevelController struct {
+	level     slog.LevelVar
+	mu        sync.RWMutex                 // protect overrides
+	overrides map[Component]*slog.LevelVar // levels of the components overriding the global level
+}
+
+// Levels is the level controller of the package logger.
+var Levels = &LevelController{}
+
+// SetLevel sets the level of the components without an override.
+func (c *LevelController) SetLevel(level Level) {
+	c.level.Set(getSlogLevel(level))
+}
+
+// Level returns the level of the components without an override.
+func (c *LevelController) Level() Level {
+	return levelOf(c.level.Level())
+}
+
+// SetComponentLevel overrides the level of the component.
+func (c *LevelController) SetComponentLevel(component Component, level Level) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.overrides == nil {
+		c.overrides = make(map[Component]*slog.LevelVar)
+	}
+	v, ok := c.overrides[component]
+	if !ok {
+		v = new(slog.LevelVar)
+		c.overrides[component] = v
+	}
+	v.Set(getSlogLevel(level))
+}
+
+// ResetComponentLevel removes the override of the level of the component.
+func (c *LevelController) ResetComponentLevel(component Component) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.overrides, component)
+}
+
+// ComponentLevel returns the level of the component, its override or the global level.
+func (c *LevelController) ComponentLevel(component Component) Level {
+	c.mu.RLock()
+	v, ok := c.overrides[component]
+	c.mu.RUnlock()
+	if ok {
+		return levelOf(v.Level())
+	}
+	return c.Level()
+}
+
+// Enabled reports whether the logs of the component at the level are written.
+func (c *LevelController) Enabled(component Component, level Level) bool {
+	return level >= c.ComponentLevel(component)
+}
+
+// Overrides returns the levels overridden per component.
+func (c *LevelController) Overrides() map[Component]Level {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	overrides := make(map[Component]Level, len(c.overrides))
+	for component, v := range c.overrides {
+		overrides[component] = levelOf(v.Level())
+	}
+	return overrides
+}
+
+// levelOf converts a slog.Level to the custom Level type.
+func levelOf(level slog.Level) Level {
+	switch {
+	case level < slog.LevelInfo:
+		return LevelDebug
+	case level < slog.LevelWarn:
+		return LevelInfo
+	case level < slog.LevelError:
+		return LevelWarn
+	default:
+		return LevelError
+	}
+}
+
+// leveledLogger writes the logs of its component enabled by the level controller.
+type leveledLogger struct {
+	log       ILogger
+	component Component
+	levels    *LevelController
+}
+
+// Debugf logs a message at Debug level.
+func (l leveledLogger) Debugf(format string, args ...any) {
+	if l.levels.Enabled(l.component, LevelDebug) {
+		l.log.Debugf(format, args...)
+	}
+}
+
+// Infof logs a message at Info level.
+func (l leveledLogger) Infof(format string, args ...any) {
+	if l.levels.Enabled(l.component, LevelInfo) {
+		l.log.Infof(format, args...)
+	}
+}
+
+// Warnf logs a message at Warn level.
+func (l leveledLogger) Warnf(format string, args ...any) {
+	if l.levels.Enabled(l.component, LevelWarn) {
+		l.log.Warnf(format, args...)
+	}
+}
+
+// Errorf logs a message at Error level.
+func (l leveledLogger) Errorf(format string, args ...any) {
+	if l.levels.Enabled(l.component, LevelError) {
+		l.log.Errorf(format, args...)
+	}
+}
+
+// WithAttrs returns a new logger with the given attributes added.
+func (l leveledLogger) WithAttrs(fields Attrs) ILogger {
+	return leveledLogger{log: l.log.WithAttrs(fields), component: l.component, levels: l.levels}
+}
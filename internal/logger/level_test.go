@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	for _, level := range []Level{LevelDebug, LevelInfo, LevelWarn, LevelError} {
+		got, err := ParseLevel(strings.ToUpper(level.String()))
+		if err != nil || got != level {
+			t.Fatalf("ParseLevel(%s) want %v got %v, %v", level, level, got, err)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Fatalf("ParseLevel should fail on unknown levels")
+	}
+}
+
+func TestLevelController(t *testing.T) {
+	var c LevelController
+	c.SetLevel(LevelWarn)
+	c.SetComponentLevel(ComponentEngine, LevelDebug)
+
+	if !c.Enabled(ComponentEngine, LevelDebug) || c.Enabled(ComponentCache, LevelInfo) || !c.Enabled(ComponentCache, LevelWarn) {
+		t.Fatalf("engine should log at debug level and cache at warn level")
+	}
+	if overrides := c.Overrides(); len(overrides) != 1 || overrides[ComponentEngine] != LevelDebug {
+		t.Fatalf("want the engine override got %v", overrides)
+	}
+
+	c.ResetComponentLevel(ComponentEngine)
+	if c.Enabled(ComponentEngine, LevelInfo) || c.ComponentLevel(ComponentEngine) != LevelWarn {
+		t.Fatalf("reset component should have the global level")
+	}
+
+	// Levels are changed while logs are written
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				c.SetComponentLevel(ComponentCache, Level(j%4))
+				c.SetLevel(Level(i))
+				c.Enabled(ComponentCache, LevelInfo)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestForComponent(t *testing.T) {
+	var out bytes.Buffer
+	previous, level := backend.Load(), Levels.Level()
+	backend.Store(&instanceLogger{newSlogLogger(Config{Level: LevelDebug}, &out)})
+	defer func() {
+		backend.Store(previous)
+		Levels.SetLevel(level)
+		Levels.ResetComponentLevel(ComponentPartition)
+	}()
+
+	Levels.SetLevel(LevelError)
+	Levels.SetComponentLevel(ComponentPartition, LevelDebug)
+	ForComponent(ComponentPartition).WithAttrs(Attrs{"rule": "r1"}).Debugf("partition debug")
+	ForComponent(ComponentEngine).Infof("engine info")
+	Infof("global info")
+	Errorf("global error")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "partition debug") || !strings.Contains(lines[0], "rule=r1") ||
+		!strings.Contains(lines[1], "global error") {
+		t.Fatalf("want the partition debug and global error logs got %q", out.String())
+	}
+}
@@ -3,7 +3,9 @@ package logger
 import (
 	"context"
 	"errors"
+	"os"
 	"sync"
+	"sync/atomic"
 )
 
 // Level represents the logging level.
@@ -49,13 +51,18 @@ type ILogger interface {
 	WithAttrs(fields Attrs) ILogger
 }
 
-// logger is the global slog.Logger instance.
+// backend is the logger instance selected by NewLogger, swapped atomically.
 // use a sync.Once to ensure thread-safe initialization.
 var (
-	log  ILogger
-	once sync.Once
+	backend atomic.Pointer[instanceLogger]
+	once    sync.Once
 )
 
+// instanceLogger holds the logger instance, writing the logs of every level.
+type instanceLogger struct {
+	ILogger
+}
+
 // Config holds the configuration for the logger.
 type Config struct {
 	Level      Level
@@ -74,50 +81,63 @@ func init() {
 
 // NewLogger initializes the logger with the given configuration and instance type.
 // It supports different logger instances like slog and zerolog.
+// The level of the configuration is set on Levels, which filters the logs afterwards.
 // Returns an error if the instance type is invalid.
 func NewLogger(config Config, instance Instance) error {
+	all := Config{Level: LevelDebug, JSONFormat: config.JSONFormat}
+	var l ILogger
 	switch instance {
 	case SlogInstance:
-		log = newSlogLogger(config)
-		return nil
+		l = newSlogLogger(all, os.Stdout)
+	case ZerologInstance:
+		l = newZerologLogger(all, os.Stdout)
 	default:
 		return errInvalidLoggerInstance
 	}
+	Levels.SetLevel(config.Level)
+	backend.Store(&instanceLogger{l})
+	return nil
+}
+
+// ForComponent returns a logger writing the logs of the component enabled by Levels.
+func ForComponent(component Component) ILogger {
+	return leveledLogger{log: backend.Load().ILogger, component: component, levels: Levels}
+}
+
+// current returns the logger of the logs without a component
+func current() ILogger {
+	return ForComponent("")
 }
 
 // Debugf logs a message at Debug level with the given format and arguments.
 func Debugf(format string, args ...any) {
-	log.Debugf(format, args...)
+	current().Debugf(format, args...)
 }
 
 // Infof logs a message at Info level with the given format and arguments.
 func Infof(format string, args ...any) {
-	log.Infof(format, args...)
+	current().Infof(format, args...)
 }
 
 // Warnf logs a message at Warn level with the given format and arguments.
 func Warnf(format string, args ...any) {
-	log.Warnf(format, args...)
+	current().Warnf(format, args...)
 }
 
 // Errorf logs a message at Error level with the given format and arguments.
 func Errorf(format string, args ...any) {
-	log.Errorf(format, args...)
+	current().Errorf(format, args...)
 }
 
 // WithAttrs returns a new logger with the given attributes added.
 func WithAttrs(attrs Attrs) ILogger {
-	return log.WithAttrs(attrs)
+	return current().WithAttrs(attrs)
 }
 
 // WithContext returns a logger that includes context-specific attributes.
 func WithContext(ctx context.Context) ILogger {
 	if ctx != nil {
-		attrs := Attrs{}
-		if correlationId, ok := ctx.Value(CorrelationIdCtxKey).(string); ok {
-			attrs[CorrelationIdCtxKey.String()] = correlationId
-		}
-		return log.WithAttrs(attrs)
+		return current().WithAttrs(ContextAttrs(ctx))
 	}
-	return log
+	return current()
 }
@@ -0,0 +1,153 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// Printer writes formatted messages at each level, the logging methods of ILogger.
+type Printer interface {
+	Debugf(format string, args ...any)
+	Infof(format string, args ...any)
+	Warnf(format string, args ...any)
+	Errorf(format string, args ...any)
+}
+
+// Sampling limits the logs written for each message and rule in every interval.
+type Sampling struct {
+	Interval time.Duration // length of the intervals, 0 means no limit
+	Limits   map[Level]int // logs written per message, rule and interval at each level, no limit for missing levels
+}
+
+// sampleKey identifies the logs counted together: the same message format of the same rule
+type sampleKey struct {
+	level  Level
+	format string
+	rule   string
+}
+
+// sampleCount counts the logs of a key in the current interval
+type sampleCount struct {
+	printer    Printer // last printer of the key, which writes the summary of the suppressed logs
+	written    int
+	suppressed int
+}
+
+// Sampler limits the logs written for each message format and rule in every interval.
+// At the end of an interval, the number of suppressed logs of each key is written in a summary
+// at the level of the logs. It is safe for concurrent use.
+type Sampler struct {
+	cfg    Sampling
+	mu     sync.Mutex
+	counts map[sampleKey]*sampleCount // counts of the current interval, nil until a limited log is written
+}
+
+// NewSampler returns a sampler with the given limits, or nil when the interval is not positive.
+func NewSampler(cfg Sampling) *Sampler {
+	if cfg.Interval <= 0 {
+		return nil
+	}
+	return &Sampler{cfg: cfg}
+}
+
+// Printer returns a Printer writing to p the logs of the rule allowed by the sampler.
+// A nil sampler returns p.
+func (s *Sampler) Printer(p Printer, rule string) Printer {
+	if s == nil {
+		return p
+	}
+	return sampledPrinter{printer: p, sampler: s, rule: rule}
+}
+
+// allow counts a log and reports whether it is under the limit of its key.
+// The first limited log of an interval schedules the end of the interval.
+func (s *Sampler) allow(p Printer, key sampleKey) bool {
+	limit := s.cfg.Limits[key.level]
+	if limit <= 0 {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.counts == nil {
+		s.counts = make(map[sampleKey]*sampleCount)
+		time.AfterFunc(s.cfg.Interval, s.flush)
+	}
+	c, ok := s.counts[key]
+	if !ok {
+		c = &sampleCount{}
+		s.counts[key] = c
+	}
+	c.printer = p
+	if c.written < limit {
+		c.written++
+		return true
+	}
+	c.suppressed++
+	return false
+}
+
+// flush ends the interval and writes the summaries of the suppressed logs
+func (s *Sampler) flush() {
+	s.mu.Lock()
+	counts := s.counts
+	s.counts = nil
+	s.mu.Unlock()
+
+	for key, c := range counts {
+		if c.suppressed == 0 {
+			continue
+		}
+		printf(c.printer, key.level, "[Sampler] %d logs of rule %v suppressed in the last %v : %q",
+			c.suppressed, key.rule, s.cfg.Interval, key.format)
+	}
+}
+
+// printf writes the message at the level
+func printf(p Printer, level Level, format string, args ...any) {
+	switch level {
+	case LevelDebug:
+		p.Debugf(format, args...)
+	case LevelInfo:
+		p.Infof(format, args...)
+	case LevelWarn:
+		p.Warnf(format, args...)
+	default:
+		p.Errorf(format, args...)
+	}
+}
+
+// sampledPrinter writes the logs allowed by the sampler
+type sampledPrinter struct {
+	printer Printer
+	sampler *Sampler
+	rule    string
+}
+
+// write writes the log if the sampler allows it
+func (p sampledPrinter) write(level Level, format string, args ...any) {
+	if p.sampler.allow(p.printer, sampleKey{level: level, format: format, rule: p.rule}) {
+		printf(p.printer, level, format, args...)
+	}
+}
+
+// Debugf logs a message at Debug level.
+func (p sampledPrinter) Debugf(format string, args ...any) {
+	p.write(LevelDebug, format, args...)
+}
+
+// Infof logs a message at Info level.
+func (p sampledPrinter) Infof(format string, args ...any) {
+	p.write(LevelInfo, format, args...)
+}
+
+// Warnf logs a message at Warn level.
+func (p sampledPrinter) Warnf(format string, args ...any) {
+	p.write(LevelWarn, format, args...)
+}
+
+// Errorf logs a message at Error level.
+func (p sampledPrinter) Errorf(format string, args ...any) {
+	p.write(LevelError, format, args...)
+}
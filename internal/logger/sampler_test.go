@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingPrinter records the level and message of the logs
+type recordingPrinter struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (p *recordingPrinter) Debugf(format string, args ...any) { p.add("DEBUG", format, args...) }
+func (p *recordingPrinter) Infof(format string, args ...any)  { p.add("INFO", format, args...) }
+func (p *recordingPrinter) Warnf(format string, args ...any)  { p.add("WARN", format, args...) }
+func (p *recordingPrinter) Errorf(format string, args ...any) { p.add("ERROR", format, args...) }
+
+func (p *recordingPrinter) add(level, format string, args ...any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.messages = append(p.messages, level+" "+fmt.Sprintf(format, args...))
+}
+
+func (p *recordingPrinter) lines() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.messages...)
+}
+
+func TestSampler(t *testing.T) {
+	p := &recordingPrinter{}
+	s := NewSampler(Sampling{Interval: 50 * time.Millisecond, Limits: map[Level]int{LevelError: 2}})
+
+	for i := 0; i < 10; i++ {
+		s.Printer(p, "r1").Errorf("execute fact %v has error", i)
+		s.Printer(p, "r2").Errorf("execute fact %v has error", i)
+		s.Printer(p, "r1").Errorf("knowledge library empty")
+		s.Printer(p, "r1").Infof("not limited %d", i)
+	}
+
+	// Each message of each rule is written twice, the info logs have no limit
+	lines := p.lines()
+	if len(lines) != 2+2+2+10 {
+		t.Fatalf("want 16 logs got %d: %v", len(lines), lines)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	summaries := p.lines()[len(lines):]
+	if len(summaries) != 3 {
+		t.Fatalf("want one summary per limited message and rule got %v", summaries)
+	}
+	for _, summary := range summaries {
+		if !strings.HasPrefix(summary, "ERROR [Sampler] 8 logs of rule r") {
+			t.Fatalf("summary should count the suppressed logs at their level: %s", summary)
+		}
+	}
+
+	// A new interval starts with new counts
+	s.Printer(p, "r1").Errorf("knowledge library empty")
+	if got := p.lines(); got[len(got)-1] != "ERROR knowledge library empty" {
+		t.Fatalf("limit should be reset after the interval, got %v", got)
+	}
+}
+
+func TestSamplerDisabled(t *testing.T) {
+	p := &recordingPrinter{}
+	s := NewSampler(Sampling{Limits: map[Level]int{LevelError: 1}})
+	if s != nil {
+		t.Fatalf("sampler without interval should be nil")
+	}
+	if s.Printer(p, "r1") != Printer(p) {
+		t.Fatalf("nil sampler should return the printer")
+	}
+}
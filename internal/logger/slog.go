@@ -2,8 +2,8 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"log/slog"
-	"os"
 )
 
 // slogLogger is an implementation of ILogger using the slog package.
@@ -28,16 +28,16 @@ func getSlogLevel(level Level) slog.Level {
 }
 
 // New creates a new slog.Logger instance based on the provided configuration.
-func newSlogLogger(cfg Config) *slogLogger {
+func newSlogLogger(cfg Config, w io.Writer) *slogLogger {
 	var handler slog.Handler
 
 	if cfg.JSONFormat {
-		handler = slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		handler = slog.NewJSONHandler(w, &slog.HandlerOptions{
 			Level:     getSlogLevel(cfg.Level),
 			AddSource: true,
 		})
 	} else {
-		handler = slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		handler = slog.NewTextHandler(w, &slog.HandlerOptions{
 			Level:     getSlogLevel(cfg.Level),
 			AddSource: true,
 		})
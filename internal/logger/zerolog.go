@@ -0,0 +1,131 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// zerologLogger is an implementation of ILogger using the zerolog package.
+// It writes the same fields as slogLogger: time, level, source, msg and the attributes.
+type zerologLogger struct {
+	log  zerolog.Logger
+	json bool
+}
+
+// getZerologLevel converts the custom Level type to zerolog.Level.
+func getZerologLevel(level Level) zerolog.Level {
+	switch level {
+	case LevelDebug:
+		return zerolog.DebugLevel
+	case LevelInfo:
+		return zerolog.InfoLevel
+	case LevelWarn:
+		return zerolog.WarnLevel
+	case LevelError:
+		return zerolog.ErrorLevel
+	default:
+		return zerolog.InfoLevel
+	}
+}
+
+// newZerologLogger creates a new zerolog.Logger instance based on the provided configuration.
+func newZerologLogger(cfg Config, w io.Writer) *zerologLogger {
+	if !cfg.JSONFormat {
+		w = newConsoleWriter(w)
+	}
+	return &zerologLogger{
+		log:  zerolog.New(w).Level(getZerologLevel(cfg.Level)),
+		json: cfg.JSONFormat,
+	}
+}
+
+// newConsoleWriter returns a zerolog.ConsoleWriter printing key=value pairs in the order of the
+// slog text handler.
+func newConsoleWriter(w io.Writer) zerolog.ConsoleWriter {
+	part := func(key string) zerolog.Formatter {
+		return func(v any) string { return key + "=" + consoleValue(v) }
+	}
+	return zerolog.ConsoleWriter{
+		Out:             w,
+		NoColor:         true,
+		PartsOrder:      []string{slog.TimeKey, slog.LevelKey, slog.SourceKey, slog.MessageKey},
+		FieldsExclude:   []string{slog.SourceKey, slog.MessageKey},
+		FormatTimestamp: part(slog.TimeKey),
+		FormatLevel:     part(slog.LevelKey),
+		FormatPartValueByName: func(v any, key string) string {
+			return key + "=" + consoleValue(v)
+		},
+		FormatFieldValue: consoleValue,
+	}
+}
+
+// consoleValue formats a console value, quoting the strings the slog text handler would quote.
+func consoleValue(v any) string {
+	s := fmt.Sprint(v)
+	if s == "" || strings.ContainsAny(s, " =\"") || !strconv.CanBackquote(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// write logs the message at the given level, with the source of the caller of the ILogger method.
+func (l *zerologLogger) write(level Level, format string, args ...any) {
+	if getZerologLevel(level) < l.log.GetLevel() {
+		return
+	}
+
+	now := time.Now()
+	e := l.log.Log()
+	if l.json {
+		e.Str(slog.TimeKey, now.Format(time.RFC3339Nano))
+	} else {
+		e.Str(slog.TimeKey, now.Format("2006-01-02T15:04:05.000Z07:00"))
+	}
+	e.Str(slog.LevelKey, getSlogLevel(level).String())
+
+	if pc, file, line, ok := runtime.Caller(2); ok {
+		if l.json {
+			source := zerolog.Dict()
+			if fn := runtime.FuncForPC(pc); fn != nil {
+				source.Str("function", fn.Name())
+			}
+			e.Dict(slog.SourceKey, source.Str("file", file).Int("line", line))
+		} else {
+			e.Str(slog.SourceKey, file+":"+strconv.Itoa(line))
+		}
+	}
+	e.Str(slog.MessageKey, fmt.Sprintf(format, args...)).Send()
+}
+
+// Debugf logs a message at Debug level.
+func (l *zerologLogger) Debugf(format string, args ...any) {
+	l.write(LevelDebug, format, args...)
+}
+
+// Infof logs a message at Info level.
+func (l *zerologLogger) Infof(format string, args ...any) {
+	l.write(LevelInfo, format, args...)
+}
+
+// Warnf logs a message at Warn level.
+func (l *zerologLogger) Warnf(format string, args ...any) {
+	l.write(LevelWarn, format, args...)
+}
+
+// Errorf logs a message at Error level.
+func (l *zerologLogger) Errorf(format string, args ...any) {
+	l.write(LevelError, format, args...)
+}
+
+// WithAttrs returns a new logger with the given attributes added.
+func (l *zerologLogger) WithAttrs(fields Attrs) ILogger {
+	newLogger := l.log.With().Fields(map[string]any(fields)).Logger()
+	return &zerologLogger{log: newLogger, json: l.json}
+}
@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// logAll logs one message per level, the debug message only when the level allows it
+func logAll(l ILogger) {
+	l.Debugf("debug %d", 1)
+	l.Infof("info %d", 2)
+	l.WithAttrs(Attrs{"rule": "r1", "count": 3}).Warnf("warn")
+	l.Errorf("error")
+}
+
+func TestZerologJSONFields(t *testing.T) {
+	var slogOut, zerologOut bytes.Buffer
+	cfg := Config{Level: LevelInfo, JSONFormat: true}
+	logAll(newSlogLogger(cfg, &slogOut))
+	logAll(newZerologLogger(cfg, &zerologOut))
+
+	want := decodeLines(t, slogOut.String())
+	got := decodeLines(t, zerologOut.String())
+	if len(got) != 3 || len(got) != len(want) {
+		t.Fatalf("want %d lines above the info level got %d: %s", len(want), len(got), zerologOut.String())
+	}
+	for i := range want {
+		if !slices.Equal(keys(got[i]), keys(want[i])) {
+			t.Fatalf("line %d: want fields %v got %v", i, keys(want[i]), keys(got[i]))
+		}
+		for _, key := range []string{"level", "msg", "rule", "count"} {
+			if got[i][key] != want[i][key] {
+				t.Fatalf("line %d: want %s %v got %v", i, key, want[i][key], got[i][key])
+			}
+		}
+		source, _ := got[i]["source"].(map[string]any)
+		wantSource, _ := want[i]["source"].(map[string]any)
+		if !slices.Equal(keys(source), keys(wantSource)) {
+			t.Fatalf("line %d: want source fields %v got %v", i, keys(wantSource), keys(source))
+		}
+	}
+	if !strings.HasSuffix(got[0]["source"].(map[string]any)["file"].(string), "zerolog_test.go") {
+		t.Fatalf("source should be the caller of the logger: %v", got[0]["source"])
+	}
+}
+
+func TestZerologConsoleFields(t *testing.T) {
+	var slogOut, zerologOut bytes.Buffer
+	cfg := Config{Level: LevelDebug}
+	logAll(newSlogLogger(cfg, &slogOut))
+	logAll(newZerologLogger(cfg, &zerologOut))
+
+	want := strings.Split(strings.TrimSpace(slogOut.String()), "\n")
+	got := strings.Split(strings.TrimSpace(zerologOut.String()), "\n")
+	if len(got) != 4 || len(got) != len(want) {
+		t.Fatalf("want %d lines got %d: %s", len(want), len(got), zerologOut.String())
+	}
+
+	key := regexp.MustCompile(`(?:^| )(\w+)=`)
+	for i := range want {
+		wantKeys, gotKeys := key.FindAllStringSubmatch(want[i], -1), key.FindAllStringSubmatch(got[i], -1)
+		if len(gotKeys) != len(wantKeys) {
+			t.Fatalf("line %d: want %q got %q", i, want[i], got[i])
+		}
+		// the attributes of a map have no order, the other fields are in the slog order
+		for j := 0; j < 4; j++ {
+			if gotKeys[j][1] != wantKeys[j][1] {
+				t.Fatalf("line %d: want %q got %q", i, want[i], got[i])
+			}
+		}
+	}
+	if !strings.Contains(got[0], `level=DEBUG`) || !strings.Contains(got[0], `msg="debug 1"`) ||
+		!strings.Contains(got[2], `rule=r1`) || !strings.Contains(got[2], `count=3`) {
+		t.Fatalf("console output should have the level, message and attributes: %s", zerologOut.String())
+	}
+}
+
+func TestZerologWithAttrs(t *testing.T) {
+	var out bytes.Buffer
+	l := newZerologLogger(Config{Level: LevelDebug, JSONFormat: true}, &out)
+	child := l.WithAttrs(Attrs{"tenant": "a"}).WithAttrs(Attrs{"rule": "r1"})
+	child.Infof("child")
+	l.Infof("parent")
+
+	lines := decodeLines(t, out.String())
+	if lines[0]["tenant"] != "a" || lines[0]["rule"] != "r1" {
+		t.Fatalf("child logger should have the attributes of its parents: %v", lines[0])
+	}
+	if _, ok := lines[1]["tenant"]; ok {
+		t.Fatalf("WithAttrs should not change the parent logger: %v", lines[1])
+	}
+}
+
+func TestNewLoggerZerolog(t *testing.T) {
+	defer func() { _ = NewLogger(Config{Level: LevelDebug}, SlogInstance) }()
+
+	if err := NewLogger(Config{Level: LevelInfo}, ZerologInstance); err != nil {
+		t.Fatalf("NewLogger error: %v", err)
+	}
+	if _, ok := backend.Load().ILogger.(*zerologLogger); !ok {
+		t.Fatalf("want zerolog logger got %T", backend.Load().ILogger)
+	}
+	if err := NewLogger(Config{}, Instance(-1)); err != errInvalidLoggerInstance {
+		t.Fatalf("want errInvalidLoggerInstance got %v", err)
+	}
+}
+
+func decodeLines(t *testing.T, out string) []map[string]any {
+	t.Helper()
+	lines := make([]map[string]any, 0)
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		var fields map[string]any
+		if err := json.Unmarshal([]byte(line), &fields); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		lines = append(lines, fields)
+	}
+	return lines
+}
+
+func keys(m map[string]any) []string {
+	k := make([]string, 0, len(m))
+	for key := range m {
+		k = append(k, key)
+	}
+	slices.Sort(k)
+	return k
+}
@@ -10,6 +10,6 @@ import (
 // RecoverWithContext recovers from a panic and logs the stack trace with the provided context.
 func RecoverWithContext(ctx context.Context, name string) {
 	if r := recover(); r != nil {
-		logger.WithContext(ctx).Errorf("%v panic : %v", name, string(debug.Stack()))
+		logger.WithContext(ctx).Errorf("%v panic : %v\n%v", name, r, string(debug.Stack()))
 	}
 }
@@ -0,0 +1,105 @@
+package ruletest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// junitTestSuites is the root element of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the results as a JUnit XML report.
+func WriteJUnit(w io.Writer, results []SuiteResult) error {
+	report := junitTestSuites{}
+	var total float64
+
+	for _, result := range results {
+		suite := junitTestSuite{
+			Name: result.Name,
+			Time: seconds(result.Duration.Seconds()),
+		}
+		if result.Err != nil {
+			// a suite which cannot run is reported as a single errored case
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      result.RuleFile,
+				ClassName: result.Name,
+				Time:      seconds(0),
+				Error:     &junitMessage{Message: result.Err.Error(), Body: result.Err.Error()},
+			})
+			suite.Errors++
+		}
+		for _, c := range result.Cases {
+			tc := junitTestCase{
+				Name:      c.Name,
+				ClassName: result.Name,
+				Time:      seconds(c.Duration.Seconds()),
+			}
+			if c.Err != nil {
+				tc.Error = &junitMessage{Message: c.Err.Error(), Body: c.Err.Error()}
+				suite.Errors++
+			} else if len(c.Failures) > 0 {
+				tc.Failure = &junitMessage{
+					Message: fmt.Sprintf("%d expectation(s) failed", len(c.Failures)),
+					Body:    strings.Join(c.Failures, "\n"),
+				}
+				suite.Failures++
+			}
+			suite.Cases = append(suite.Cases, tc)
+		}
+		suite.Tests = len(suite.Cases)
+
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Errors += suite.Errors
+		total += result.Duration.Seconds()
+		report.Suites = append(report.Suites, suite)
+	}
+	report.Time = seconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// seconds formats a duration in seconds as JUnit does.
+func seconds(s float64) string {
+	return fmt.Sprintf("%.3f", s)
+}
@@ -0,0 +1,197 @@
+package ruletest
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hungpdn/grule-plus/engine"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// SuiteResult is the result of running a spec.
+type SuiteResult struct {
+	Name     string
+	RuleFile string
+	Cases    []CaseResult
+	Coverage []engine.RuleCoverage // coverage of the rule file, when the engine is in coverage mode
+	Err      error                 // error preventing the cases from running, such as a compilation error
+	Duration time.Duration
+}
+
+// Failed returns the number of failed cases.
+func (r SuiteResult) Failed() int {
+	failed := 0
+	for _, c := range r.Cases {
+		if !c.Passed() {
+			failed++
+		}
+	}
+	return failed
+}
+
+// Passed returns true if the suite ran and all its cases passed.
+func (r SuiteResult) Passed() bool {
+	return r.Err == nil && r.Failed() == 0
+}
+
+// CaseResult is the result of running a case.
+type CaseResult struct {
+	Name     string
+	Fired    []string // rule entries fired, in order
+	Failures []string // unmet expectations
+	Err      error    // execution error
+	Duration time.Duration
+}
+
+// Passed returns true if the case ran and met all its expectations.
+func (r CaseResult) Passed() bool {
+	return r.Err == nil && len(r.Failures) == 0
+}
+
+// Run runs each spec on a new engine in coverage mode configured with its fact name.
+func Run(ctx context.Context, specs []*Spec) []SuiteResult {
+	results := make([]SuiteResult, 0, len(specs))
+	for _, spec := range specs {
+		grule := engine.NewSingleEngine(engine.Config{FactName: spec.GetFactName(), Coverage: true})
+		results = append(results, RunSpec(ctx, grule, spec))
+		grule.Close()
+	}
+	return results
+}
+
+// RunSpec adds the rule file of the spec to the engine and runs its cases.
+// The coverage of the rule file is reported when the engine is in coverage mode.
+// The engine must be configured with the fact name of the spec.
+func RunSpec(ctx context.Context, eng engine.IGruleEngine, spec *Spec) SuiteResult {
+	start := time.Now()
+	result := SuiteResult{Name: spec.GetName(), RuleFile: spec.RuleFilePath()}
+
+	statement, err := os.ReadFile(result.RuleFile)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if err := eng.AddRule(result.RuleFile, string(statement), 0); err != nil {
+		result.Err = err
+		return result
+	}
+	defer eng.RemoveRule(result.RuleFile)
+
+	for _, c := range spec.Cases {
+		result.Cases = append(result.Cases, runCase(ctx, eng, result.RuleFile, c))
+	}
+	for _, rc := range eng.Coverage().Rules {
+		if rc.Rule == result.RuleFile {
+			result.Coverage = append(result.Coverage, rc)
+		}
+	}
+	result.Duration = time.Since(start)
+
+	return result
+}
+
+// runCase executes the rule against the fact of the case and checks its expectations.
+func runCase(ctx context.Context, eng engine.IGruleEngine, rule string, c Case) CaseResult {
+	start := time.Now()
+	result := CaseResult{Name: c.Name}
+
+	fact := engine.NewJSONFact(c.Fact)
+	if fact.Values == nil {
+		fact.Values = make(map[string]any)
+	}
+	recorder := &firedRecorder{}
+	if err := eng.Execute(engine.WithRuleListener(ctx, recorder), rule, fact); err != nil {
+		result.Err = err
+		result.Duration = time.Since(start)
+		return result
+	}
+	result.Fired = recorder.fired
+
+	for _, field := range slices.Sorted(maps.Keys(c.Expect)) {
+		want := c.Expect[field]
+		got, ok := lookup(fact.Result, field)
+		if !ok {
+			result.Failures = append(result.Failures, fmt.Sprintf("field %s: want %v, got nothing", field, want))
+			continue
+		}
+		if !reflect.DeepEqual(normalize(want), normalize(got)) {
+			result.Failures = append(result.Failures, fmt.Sprintf("field %s: want %v, got %v", field, want, got))
+		}
+	}
+	for _, name := range c.Fired {
+		if !slices.Contains(recorder.fired, name) {
+			result.Failures = append(result.Failures, fmt.Sprintf("rule %s: want fired, fired %v", name, recorder.fired))
+		}
+	}
+	for _, name := range c.NotFired {
+		if slices.Contains(recorder.fired, name) {
+			result.Failures = append(result.Failures, fmt.Sprintf("rule %s: want not fired, fired %v", name, recorder.fired))
+		}
+	}
+	result.Duration = time.Since(start)
+
+	return result
+}
+
+// firedRecorder records the names of the rule entries executed.
+type firedRecorder struct {
+	fired []string
+}
+
+func (r *firedRecorder) RuleEvaluated(rule string, entry *ast.RuleEntry, matched bool) {}
+
+func (r *firedRecorder) RuleExecuted(rule string, entry *ast.RuleEntry) {
+	r.fired = append(r.fired, entry.RuleName)
+}
+
+// lookup returns the value of a dotted field path in the fact.
+func lookup(fact map[string]any, field string) (any, bool) {
+	var value any = fact
+	for _, key := range strings.Split(field, ".") {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// normalize converts numbers to float64, recursively, so that values decoded
+// from YAML and values set by the rules compare equal.
+func normalize(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[k] = normalize(e)
+		}
+		return m
+	case []any:
+		s := make([]any, len(v))
+		for i, e := range v {
+			s[i] = normalize(e)
+		}
+		return s
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	default:
+		return value
+	}
+}
@@ -0,0 +1,111 @@
+package ruletest
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadSpec(t *testing.T) {
+	spec, err := LoadSpec("testdata/discount_test.yaml")
+	if err != nil {
+		t.Fatalf("LoadSpec error: %v", err)
+	}
+	if spec.GetName() != "discount rules" || len(spec.Cases) != 2 {
+		t.Fatalf("unexpected spec %+v", spec)
+	}
+	if spec.RuleFilePath() != filepath.Join("testdata", "discount.grl") {
+		t.Fatalf("rule file should be resolved against the spec, got %s", spec.RuleFilePath())
+	}
+	if spec.GetFactName() != "Fact" {
+		t.Fatalf("default fact name want Fact got %s", spec.GetFactName())
+	}
+
+	spec, err = LoadSpec("testdata/discount_test.json")
+	if err != nil {
+		t.Fatalf("LoadSpec json error: %v", err)
+	}
+	if spec.GetName() != "discount_test.json" {
+		t.Fatalf("default name should be the file name, got %s", spec.GetName())
+	}
+}
+
+func TestLoadSpecInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "invalid.yaml")
+	if err := os.WriteFile(path, []byte("cases: []\n"), 0o600); err != nil {
+		t.Fatalf("write spec: %v", err)
+	}
+	if _, err := LoadSpec(path); err == nil {
+		t.Fatalf("LoadSpec should reject a spec without rule file")
+	}
+}
+
+func TestRun(t *testing.T) {
+	passing, err := LoadSpec("testdata/discount_test.yaml")
+	if err != nil {
+		t.Fatalf("LoadSpec error: %v", err)
+	}
+	failing, err := LoadSpec("testdata/discount_test.json")
+	if err != nil {
+		t.Fatalf("LoadSpec error: %v", err)
+	}
+
+	results := Run(context.Background(), []*Spec{passing, failing})
+	if len(results) != 2 {
+		t.Fatalf("want 2 results got %d", len(results))
+	}
+
+	if !results[0].Passed() {
+		t.Fatalf("yaml spec should pass: %+v", results[0])
+	}
+	if fired := results[0].Cases[0].Fired; len(fired) != 1 || fired[0] != "DiscountRule" {
+		t.Fatalf("first case should fire DiscountRule, fired %v", fired)
+	}
+
+	if results[1].Passed() || results[1].Failed() != 1 {
+		t.Fatalf("json spec should fail: %+v", results[1])
+	}
+	if failures := results[1].Cases[0].Failures; len(failures) != 2 {
+		t.Fatalf("want a field and a fired failure, got %v", failures)
+	}
+}
+
+func TestRunCompileError(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "broken.grl"), []byte("rule Broken {"), 0o600); err != nil {
+		t.Fatalf("write rule: %v", err)
+	}
+	spec := &Spec{RuleFile: "broken.grl", Cases: []Case{{Name: "case"}}, path: filepath.Join(dir, "spec.yaml")}
+
+	results := Run(context.Background(), []*Spec{spec})
+	if results[0].Err == nil || results[0].Passed() {
+		t.Fatalf("compile error should fail the suite: %+v", results[0])
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	passing, _ := LoadSpec("testdata/discount_test.yaml")
+	failing, _ := LoadSpec("testdata/discount_test.json")
+	results := Run(context.Background(), []*Spec{passing, failing})
+
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, results); err != nil {
+		t.Fatalf("WriteJUnit error: %v", err)
+	}
+
+	var report junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("report is not valid XML: %v", err)
+	}
+	if report.Tests != 3 || report.Failures != 1 || report.Errors != 0 || len(report.Suites) != 2 {
+		t.Fatalf("unexpected report totals %+v", report)
+	}
+	failure := report.Suites[1].Cases[0].Failure
+	if failure == nil || !strings.Contains(failure.Body, "field Discount") {
+		t.Fatalf("failure should describe the unmet expectation: %+v", failure)
+	}
+}
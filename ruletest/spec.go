@@ -0,0 +1,97 @@
+// ruletest runs declarative test cases against GRL rules.
+package ruletest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is a suite of test cases for a rule file.
+//
+//	name: discount rules
+//	rule_file: discount.grl
+//	fact_name: Fact
+//	cases:
+//	  - name: large orders get a discount
+//	    fact: {Amount: 150}
+//	    expect: {Discount: 10}
+//	    fired: [DiscountRule]
+type Spec struct {
+	Name     string `yaml:"name" json:"name"`
+	RuleFile string `yaml:"rule_file" json:"rule_file"` // path of the GRL file, relative to the spec file
+	FactName string `yaml:"fact_name" json:"fact_name"` // name of the fact in the rules, default is "Fact"
+	Cases    []Case `yaml:"cases" json:"cases"`
+
+	path string // path of the spec file
+}
+
+// Case is a single test case of a spec.
+type Case struct {
+	Name     string         `yaml:"name" json:"name"`
+	Fact     map[string]any `yaml:"fact" json:"fact"`           // input fact
+	Expect   map[string]any `yaml:"expect" json:"expect"`       // expected fact fields after execution, nested fields use dots
+	Fired    []string       `yaml:"fired" json:"fired"`         // rule entries expected to fire
+	NotFired []string       `yaml:"not_fired" json:"not_fired"` // rule entries expected not to fire
+}
+
+// LoadSpec reads a YAML or JSON spec file.
+func LoadSpec(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON is a subset of YAML, so both formats are decoded the same way
+	spec := &Spec{path: path}
+	if err := yaml.Unmarshal(data, spec); err != nil {
+		return nil, fmt.Errorf("decode spec %s: %w", path, err)
+	}
+	if err := spec.validate(); err != nil {
+		return nil, fmt.Errorf("invalid spec %s: %w", path, err)
+	}
+	return spec, nil
+}
+
+// GetName returns the name of the spec or its file name if not set.
+func (s *Spec) GetName() string {
+	if s.Name == "" {
+		return filepath.Base(s.path)
+	}
+	return s.Name
+}
+
+// GetFactName returns the configured fact name or a default value if not set.
+func (s *Spec) GetFactName() string {
+	if s.FactName == "" {
+		return "Fact"
+	}
+	return s.FactName
+}
+
+// RuleFilePath returns the path of the rule file, resolved against the spec file directory.
+func (s *Spec) RuleFilePath() string {
+	if filepath.IsAbs(s.RuleFile) || s.path == "" {
+		return s.RuleFile
+	}
+	return filepath.Join(filepath.Dir(s.path), s.RuleFile)
+}
+
+// validate checks that the spec can be run.
+func (s *Spec) validate() error {
+	if s.RuleFile == "" {
+		return errors.New("rule_file is required")
+	}
+	if len(s.Cases) == 0 {
+		return errors.New("at least one case is required")
+	}
+	for i, c := range s.Cases {
+		if c.Name == "" {
+			return fmt.Errorf("case %d: name is required", i)
+		}
+	}
+	return nil
+}
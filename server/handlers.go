@@ -0,0 +1,355 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hungpdn/grule-plus/engine"
+	"github.com/hungpdn/grule-plus/internal/logger"
+)
+
+// RuleRequest is the body of the add and build rule endpoints.
+type RuleRequest struct {
+	Name      string `json:"name"`
+	Statement string `json:"statement"`
+	Duration  int64  `json:"duration"` // cache duration in nanoseconds, 0 means the engine TTL
+}
+
+// RuleResponse describes a rule known by the engine.
+type RuleResponse struct {
+	Name   string `json:"name"`
+	Exists bool   `json:"exists"`
+}
+
+// ListRulesResponse is the body returned by the list rules endpoint.
+type ListRulesResponse struct {
+	Rules []string `json:"rules"`
+	Len   int      `json:"len"`
+}
+
+// ExecuteResponse is the body returned by the execute endpoint.
+type ExecuteResponse struct {
+	Rule string         `json:"rule"`
+	Fact map[string]any `json:"fact"`
+}
+
+// RuleEntry describes a rule entry matching a fact.
+type RuleEntry struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Salience    int    `json:"salience"`
+}
+
+// MatchingResponse is the body returned by the matching endpoint.
+type MatchingResponse struct {
+	Rule    string      `json:"rule"`
+	Matches []RuleEntry `json:"matches"`
+}
+
+// LogLevelRequest is the body of the set log level endpoint.
+type LogLevelRequest struct {
+	Level     string `json:"level"`               // debug, info, warn or error, empty resets the component level
+	Component string `json:"component,omitempty"` // engine, cache or partition, empty for the global level
+}
+
+// LogLevelResponse is the body returned by the log level endpoints.
+type LogLevelResponse struct {
+	Level      string            `json:"level"`
+	Components map[string]string `json:"components"`
+}
+
+// RateLimitRequest is the body of the set rate limit endpoint.
+type RateLimitRequest struct {
+	Rule  string  `json:"rule"`  // name of the rule, or prefix of the names ending with "*"
+	Rate  float64 `json:"rate"`  // executions per second
+	Burst int     `json:"burst"` // executions allowed at once, 0 means the rate rounded up
+}
+
+// RateLimitResponse is a rate limit with its statistics.
+type RateLimitResponse struct {
+	Rule    string  `json:"rule"`
+	Rate    float64 `json:"rate"`
+	Burst   int     `json:"burst"`
+	Tokens  float64 `json:"tokens"`
+	Allowed int64   `json:"allowed"`
+	Limited int64   `json:"limited"`
+}
+
+// ListRateLimitsResponse is the body returned by the rate limit endpoints.
+type ListRateLimitsResponse struct {
+	RateLimits []RateLimitResponse `json:"rate_limits"`
+}
+
+// ErrorResponse is the body returned on failure.
+type ErrorResponse struct {
+	Error         string `json:"error"`
+	CorrelationId string `json:"correlation_id,omitempty"`
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleDebug(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.engine.Debug())
+}
+
+func (s *Server) handleCoverage(w http.ResponseWriter, r *http.Request) {
+	report := s.engine.Coverage()
+	if r.URL.Query().Get("format") != "html" {
+		writeJSON(w, http.StatusOK, report)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := report.WriteHTML(w); err != nil {
+		logger.WithContext(r.Context()).Errorf("[Server][handleCoverage] write html has error : %v", err)
+	}
+}
+
+func (s *Server) handleGetLogLevel(w http.ResponseWriter, r *http.Request) {
+	level, components := engine.LogLevels()
+	writeJSON(w, http.StatusOK, LogLevelResponse{Level: level, Components: components})
+}
+
+func (s *Server) handleSetLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req LogLevelRequest
+	if err := s.decode(r, &req); err != nil {
+		writeRequestError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var err error
+	if req.Component == "" {
+		err = engine.SetLogLevel(req.Level)
+	} else {
+		err = engine.SetComponentLogLevel(req.Component, req.Level)
+	}
+	if err != nil {
+		writeRequestError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	logger.WithContext(r.Context()).Infof("[Server][handleSetLogLevel] log level of %q set to %q", req.Component, req.Level)
+	s.handleGetLogLevel(w, r)
+}
+
+func (s *Server) handleListRateLimits(w http.ResponseWriter, r *http.Request) {
+	limiter, ok := s.rateLimiter(w, r)
+	if !ok {
+		return
+	}
+	stats := limiter.RateLimits()
+	limits := make([]RateLimitResponse, 0, len(stats))
+	for _, l := range stats {
+		limits = append(limits, RateLimitResponse{
+			Rule:    l.Rule,
+			Rate:    l.Rate,
+			Burst:   l.Burst,
+			Tokens:  l.Tokens,
+			Allowed: l.Allowed,
+			Limited: l.Limited,
+		})
+	}
+	writeJSON(w, http.StatusOK, ListRateLimitsResponse{RateLimits: limits})
+}
+
+func (s *Server) handleSetRateLimit(w http.ResponseWriter, r *http.Request) {
+	limiter, ok := s.rateLimiter(w, r)
+	if !ok {
+		return
+	}
+	var req RateLimitRequest
+	if err := s.decode(r, &req); err != nil {
+		writeRequestError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := limiter.SetRateLimit(engine.RateLimit{Rule: req.Rule, Rate: req.Rate, Burst: req.Burst}); err != nil {
+		writeRequestError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.handleListRateLimits(w, r)
+}
+
+func (s *Server) handleRemoveRateLimit(w http.ResponseWriter, r *http.Request) {
+	limiter, ok := s.rateLimiter(w, r)
+	if !ok {
+		return
+	}
+	rule := r.PathValue("rule")
+	if !limiter.RemoveRateLimit(rule) {
+		writeRequestError(w, r, http.StatusNotFound, fmt.Sprintf("rate limit of %s not found", rule))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// rateLimiter returns the engine as a RateLimiter, writing the error response when it is not one.
+func (s *Server) rateLimiter(w http.ResponseWriter, r *http.Request) (engine.RateLimiter, bool) {
+	limiter, ok := s.engine.(engine.RateLimiter)
+	if !ok {
+		writeRequestError(w, r, http.StatusNotImplemented, "the engine has no rate limits")
+	}
+	return limiter, ok
+}
+
+func (s *Server) handleListRules(w http.ResponseWriter, r *http.Request) {
+	rules := s.engine.ListRules()
+	writeJSON(w, http.StatusOK, ListRulesResponse{Rules: rules, Len: len(rules)})
+}
+
+func (s *Server) handleGetRule(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !s.engine.ContainsRule(name) {
+		writeRequestError(w, r, http.StatusNotFound, fmt.Sprintf("rule %s not found", name))
+		return
+	}
+	writeJSON(w, http.StatusOK, RuleResponse{Name: name, Exists: true})
+}
+
+func (s *Server) handleAddRule(w http.ResponseWriter, r *http.Request) {
+	var req RuleRequest
+	if !s.decodeRuleRequest(w, r, &req) {
+		return
+	}
+	if err := s.engine.AddRule(req.Name, req.Statement, req.Duration); err != nil {
+		logger.WithContext(r.Context()).Errorf("[Server][handleAddRule] add rule %v has error : %v", req.Name, err)
+		writeRequestError(w, r, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, RuleResponse{Name: req.Name, Exists: true})
+}
+
+func (s *Server) handleBuildRule(w http.ResponseWriter, r *http.Request) {
+	var req RuleRequest
+	if !s.decodeRuleRequest(w, r, &req) {
+		return
+	}
+	if err := s.engine.BuildRule(req.Name, req.Statement, req.Duration); err != nil {
+		logger.WithContext(r.Context()).Errorf("[Server][handleBuildRule] build rule %v has error : %v", req.Name, err)
+		writeRequestError(w, r, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, RuleResponse{Name: req.Name, Exists: true})
+}
+
+func (s *Server) handleRemoveRule(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !s.engine.ContainsRule(name) {
+		writeRequestError(w, r, http.StatusNotFound, fmt.Sprintf("rule %s not found", name))
+		return
+	}
+	s.engine.RemoveRule(name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleExecute(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	fact, ok := s.decodeFact(w, r, name)
+	if !ok {
+		return
+	}
+	if err := s.engine.Execute(r.Context(), name, fact); err != nil {
+		writeRequestError(w, r, executeStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, ExecuteResponse{Rule: name, Fact: fact.Result})
+}
+
+func (s *Server) handleFetchMatching(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	fact, ok := s.decodeFact(w, r, name)
+	if !ok {
+		return
+	}
+	ruleEntries, err := s.engine.FetchMatching(r.Context(), name, fact)
+	if err != nil {
+		writeRequestError(w, r, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	matches := make([]RuleEntry, 0, len(ruleEntries))
+	for _, ruleEntry := range ruleEntries {
+		matches = append(matches, RuleEntry{
+			Name:        ruleEntry.RuleName,
+			Description: ruleEntry.RuleDescription,
+			Salience:    ruleEntry.Salience,
+		})
+	}
+	writeJSON(w, http.StatusOK, MatchingResponse{Rule: name, Matches: matches})
+}
+
+// decodeRuleRequest decodes and validates a rule request, writing the error response on failure.
+func (s *Server) decodeRuleRequest(w http.ResponseWriter, r *http.Request, req *RuleRequest) bool {
+	if err := s.decode(r, req); err != nil {
+		writeRequestError(w, r, http.StatusBadRequest, err.Error())
+		return false
+	}
+	if req.Name == "" || req.Statement == "" {
+		writeRequestError(w, r, http.StatusBadRequest, "name and statement are required")
+		return false
+	}
+	return true
+}
+
+// decodeFact decodes the JSON fact of an execution request for an existing rule,
+// writing the error response on failure.
+func (s *Server) decodeFact(w http.ResponseWriter, r *http.Request, name string) (*engine.JSONFact, bool) {
+	if !s.engine.ContainsRule(name) {
+		writeRequestError(w, r, http.StatusNotFound, fmt.Sprintf("rule %s not found", name))
+		return nil, false
+	}
+	values := make(map[string]any)
+	if err := s.decode(r, &values); err != nil {
+		writeRequestError(w, r, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	return engine.NewJSONFact(values), true
+}
+
+// executeStatus returns the status code of an execution error: 429 for a rule over its rate limit,
+// 503 for a quarantined rule, which may be executed again later, and 422 otherwise.
+func executeStatus(err error) int {
+	switch {
+	case errors.Is(err, engine.ErrRateLimited):
+		return http.StatusTooManyRequests
+	case errors.Is(err, engine.ErrRuleQuarantined):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusUnprocessableEntity
+	}
+}
+
+// decode decodes the JSON request body into v.
+func (s *Server) decode(r *http.Request, v any) error {
+	if r.Body == nil {
+		return errors.New("request body is empty")
+	}
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, s.cfg.GetMaxBodySize()))
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+// writeJSON writes v as a JSON response with the given status code.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Errorf("[Server][writeJSON] encode response has error : %v", err)
+	}
+}
+
+// writeError writes an error response with the given status code.
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, ErrorResponse{Error: message})
+}
+
+// writeRequestError writes an error response carrying the correlation ID of the request.
+func writeRequestError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	writeJSON(w, status, ErrorResponse{
+		Error:         message,
+		CorrelationId: logger.GetCorrelationIdFromCtx(r.Context()),
+	})
+}
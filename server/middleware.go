@@ -0,0 +1,40 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/hungpdn/grule-plus/internal/logger"
+)
+
+// CorrelationIdHeader is the HTTP header carrying the correlation ID.
+const CorrelationIdHeader = "X-Correlation-ID"
+
+// withCorrelationID reuses the correlation ID of the request header when it is valid or generates
+// a new one, stores it in the request context and echoes it in the response header.
+func withCorrelationID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if correlationId := r.Header.Get(CorrelationIdHeader); logger.ValidCorrelationID(correlationId) {
+			ctx = context.WithValue(ctx, logger.CorrelationIdCtxKey, correlationId)
+		}
+		ctx = logger.SetCorrelationIdToCtx(ctx)
+
+		w.Header().Set(CorrelationIdHeader, logger.GetCorrelationIdFromCtx(ctx))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// withRecover converts a panic in a handler into an internal server error.
+func withRecover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				logger.WithContext(r.Context()).Errorf("[Server][withRecover] %s %s panic : %v %s", r.Method, r.URL.Path, rec, string(debug.Stack()))
+				writeError(w, http.StatusInternalServerError, "internal server error")
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,132 @@
+// server exposes an IGruleEngine over HTTP.
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/hungpdn/grule-plus/engine"
+	"github.com/hungpdn/grule-plus/internal/logger"
+)
+
+// Config holds the configuration for the HTTP server.
+type Config struct {
+	Addr              string // address to listen on, default is ":8080"
+	ReadTimeout       int    // read timeout in seconds, 0 means no timeout
+	ReadHeaderTimeout int    // timeout in seconds to read the request headers, default is 10
+	WriteTimeout      int    // write timeout in seconds, 0 means no timeout
+	ShutdownTimeout   int    // graceful shutdown timeout in seconds, default is 10
+	MaxBodySize       int64  // maximum request body size in bytes, default is 1MB
+}
+
+// GetAddr returns the configured address or a default value if not set.
+func (c Config) GetAddr() string {
+	if c.Addr == "" {
+		return ":8080"
+	}
+	return c.Addr
+}
+
+// GetReadHeaderTimeout returns the configured read header timeout or a default value if not set.
+func (c Config) GetReadHeaderTimeout() time.Duration {
+	if c.ReadHeaderTimeout <= 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(c.ReadHeaderTimeout) * time.Second
+}
+
+// GetShutdownTimeout returns the configured shutdown timeout or a default value if not set.
+func (c Config) GetShutdownTimeout() time.Duration {
+	if c.ShutdownTimeout <= 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(c.ShutdownTimeout) * time.Second
+}
+
+// GetMaxBodySize returns the configured maximum body size or a default value if not set.
+func (c Config) GetMaxBodySize() int64 {
+	if c.MaxBodySize <= 0 {
+		return 1 << 20
+	}
+	return c.MaxBodySize
+}
+
+// Server serves the rule admin and execution endpoints for an engine.
+type Server struct {
+	cfg    Config
+	engine engine.IGruleEngine
+	mux    *http.ServeMux
+}
+
+// New creates a new Server for the given engine.
+func New(eng engine.IGruleEngine, cfg Config) *Server {
+	s := &Server{
+		cfg:    cfg,
+		engine: eng,
+		mux:    http.NewServeMux(),
+	}
+	s.routes()
+	return s
+}
+
+// routes registers the endpoints of the server.
+func (s *Server) routes() {
+	s.mux.HandleFunc("GET /healthz", s.handleHealth)
+	s.mux.HandleFunc("GET /debug", s.handleDebug)
+	s.mux.HandleFunc("GET /coverage", s.handleCoverage)
+	s.mux.HandleFunc("GET /log-level", s.handleGetLogLevel)
+	s.mux.HandleFunc("PUT /log-level", s.handleSetLogLevel)
+	s.mux.HandleFunc("GET /rate-limits", s.handleListRateLimits)
+	s.mux.HandleFunc("PUT /rate-limits", s.handleSetRateLimit)
+	s.mux.HandleFunc("DELETE /rate-limits/{rule...}", s.handleRemoveRateLimit)
+	s.mux.HandleFunc("GET /rules", s.handleListRules)
+	s.mux.HandleFunc("POST /rules", s.handleAddRule)
+	s.mux.HandleFunc("POST /rules/build", s.handleBuildRule)
+	s.mux.HandleFunc("GET /rules/{name}", s.handleGetRule)
+	s.mux.HandleFunc("DELETE /rules/{name}", s.handleRemoveRule)
+	s.mux.HandleFunc("POST /rules/{name}/execute", s.handleExecute)
+	s.mux.HandleFunc("POST /rules/{name}/matching", s.handleFetchMatching)
+}
+
+// Handler returns the http.Handler of the server with its middlewares.
+func (s *Server) Handler() http.Handler {
+	return withCorrelationID(withRecover(s.mux))
+}
+
+// ListenAndServe serves HTTP requests until the context is done,
+// then shuts the server down gracefully.
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	httpServer := &http.Server{
+		Addr:              s.cfg.GetAddr(),
+		Handler:           s.Handler(),
+		ReadTimeout:       time.Duration(s.cfg.ReadTimeout) * time.Second,
+		ReadHeaderTimeout: s.cfg.GetReadHeaderTimeout(),
+		WriteTimeout:      time.Duration(s.cfg.WriteTimeout) * time.Second,
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		logger.Infof("[Server][ListenAndServe] listening on %s", httpServer.Addr)
+		errChan <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errChan:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	logger.Infof("[Server][ListenAndServe] shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.cfg.GetShutdownTimeout())
+	defer cancel()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,356 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hungpdn/grule-plus/engine"
+	"github.com/hungpdn/grule-plus/internal/logger"
+)
+
+const discountStatement = `rule DiscountRule "Apply discount" salience 10 {
+	when
+		Fact.Amount > 100
+	then
+		Fact.Discount = 10;
+		Retract("DiscountRule");
+}`
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	grule := engine.NewPartitionEngine(engine.Config{Type: engine.LRU, Size: 100, Partition: 1}, nil)
+	ts := httptest.NewServer(New(grule, Config{}).Handler())
+	t.Cleanup(func() {
+		ts.Close()
+		grule.Close()
+	})
+	return ts
+}
+
+func doJSON(t *testing.T, method, url string, body any, header http.Header) *http.Response {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatalf("encode body: %v", err)
+		}
+	}
+	req, err := http.NewRequest(method, url, &buf)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+func decodeBody(t *testing.T, resp *http.Response, v any) {
+	t.Helper()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+}
+
+func addDiscountRule(t *testing.T, ts *httptest.Server) {
+	t.Helper()
+	resp := doJSON(t, http.MethodPost, ts.URL+"/rules", RuleRequest{Name: "DiscountRule", Statement: discountStatement}, nil)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("add rule status want %d got %d", http.StatusCreated, resp.StatusCode)
+	}
+}
+
+func TestAddListAndRemoveRule(t *testing.T) {
+	ts := newTestServer(t)
+	addDiscountRule(t, ts)
+
+	resp := doJSON(t, http.MethodGet, ts.URL+"/rules", nil, nil)
+	var list ListRulesResponse
+	decodeBody(t, resp, &list)
+	if list.Len != 1 || list.Rules[0] != "DiscountRule" {
+		t.Fatalf("unexpected rules %+v", list)
+	}
+
+	resp = doJSON(t, http.MethodGet, ts.URL+"/rules/DiscountRule", nil, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("get rule status want %d got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	resp = doJSON(t, http.MethodDelete, ts.URL+"/rules/DiscountRule", nil, nil)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("remove rule status want %d got %d", http.StatusNoContent, resp.StatusCode)
+	}
+
+	resp = doJSON(t, http.MethodDelete, ts.URL+"/rules/DiscountRule", nil, nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("remove missing rule status want %d got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestBuildRule(t *testing.T) {
+	ts := newTestServer(t)
+
+	resp := doJSON(t, http.MethodPost, ts.URL+"/rules/build", RuleRequest{Name: "DiscountRule", Statement: discountStatement}, nil)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("build rule status want %d got %d", http.StatusCreated, resp.StatusCode)
+	}
+}
+
+func TestAddRuleInvalid(t *testing.T) {
+	ts := newTestServer(t)
+
+	resp := doJSON(t, http.MethodPost, ts.URL+"/rules", RuleRequest{Name: "Broken"}, nil)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("missing statement status want %d got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+
+	resp = doJSON(t, http.MethodPost, ts.URL+"/rules", RuleRequest{Name: "Broken", Statement: "rule Broken {"}, nil)
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("invalid statement status want %d got %d", http.StatusUnprocessableEntity, resp.StatusCode)
+	}
+	var errResp ErrorResponse
+	decodeBody(t, resp, &errResp)
+	if errResp.Error == "" || errResp.CorrelationId == "" {
+		t.Fatalf("unexpected error response %+v", errResp)
+	}
+}
+
+func TestExecute(t *testing.T) {
+	ts := newTestServer(t)
+	addDiscountRule(t, ts)
+
+	resp := doJSON(t, http.MethodPost, ts.URL+"/rules/DiscountRule/execute", map[string]any{"Amount": 150, "Discount": 0}, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("execute status want %d got %d", http.StatusOK, resp.StatusCode)
+	}
+	var result ExecuteResponse
+	decodeBody(t, resp, &result)
+	if result.Fact["Discount"] != float64(10) {
+		t.Fatalf("Discount want 10 got %v", result.Fact["Discount"])
+	}
+
+	resp = doJSON(t, http.MethodPost, ts.URL+"/rules/Missing/execute", map[string]any{"Amount": 150}, nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("execute missing rule status want %d got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestExecuteStatus(t *testing.T) {
+	if got := executeStatus(fmt.Errorf("%w: r1", engine.ErrRuleQuarantined)); got != http.StatusServiceUnavailable {
+		t.Fatalf("quarantined rule status want %d got %d", http.StatusServiceUnavailable, got)
+	}
+	if got := executeStatus(engine.ErrMaxCycleExceeded); got != http.StatusUnprocessableEntity {
+		t.Fatalf("execution error status want %d got %d", http.StatusUnprocessableEntity, got)
+	}
+}
+
+func TestFetchMatching(t *testing.T) {
+	ts := newTestServer(t)
+	addDiscountRule(t, ts)
+
+	resp := doJSON(t, http.MethodPost, ts.URL+"/rules/DiscountRule/matching", map[string]any{"Amount": 150}, nil)
+	var result MatchingResponse
+	decodeBody(t, resp, &result)
+	if len(result.Matches) != 1 || result.Matches[0].Name != "DiscountRule" || result.Matches[0].Salience != 10 {
+		t.Fatalf("unexpected matches %+v", result.Matches)
+	}
+
+	resp = doJSON(t, http.MethodPost, ts.URL+"/rules/DiscountRule/matching", map[string]any{"Amount": 50}, nil)
+	decodeBody(t, resp, &result)
+	if len(result.Matches) != 0 {
+		t.Fatalf("expected no matches got %+v", result.Matches)
+	}
+}
+
+func TestDebug(t *testing.T) {
+	ts := newTestServer(t)
+	addDiscountRule(t, ts)
+
+	resp := doJSON(t, http.MethodGet, ts.URL+"/debug", nil, nil)
+	var debug map[string]any
+	decodeBody(t, resp, &debug)
+	if debug["engines"] == nil || debug["stats"] == nil {
+		t.Fatalf("debug missing keys: %v", debug)
+	}
+}
+
+func TestCorrelationID(t *testing.T) {
+	ts := newTestServer(t)
+
+	resp := doJSON(t, http.MethodGet, ts.URL+"/healthz", nil, http.Header{CorrelationIdHeader: {"abc"}})
+	if got := resp.Header.Get(CorrelationIdHeader); got != "abc" {
+		t.Fatalf("correlation id want abc got %q", got)
+	}
+
+	resp = doJSON(t, http.MethodGet, ts.URL+"/healthz", nil, nil)
+	if got := resp.Header.Get(CorrelationIdHeader); got == "" {
+		t.Fatalf("correlation id should be generated")
+	}
+
+	// Invalid correlation IDs are replaced by a generated one
+	for _, id := range []string{"abc def", "abc\"<script>", strings.Repeat("a", 65)} {
+		resp = doJSON(t, http.MethodGet, ts.URL+"/healthz", nil, http.Header{CorrelationIdHeader: {id}})
+		if got := resp.Header.Get(CorrelationIdHeader); got == id || !logger.ValidCorrelationID(got) {
+			t.Fatalf("correlation id %q should be replaced, got %q", id, got)
+		}
+	}
+}
+
+func TestListenAndServeShutdown(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	_ = listener.Close()
+
+	grule := engine.NewPartitionEngine(engine.Config{Partition: 1}, nil)
+	defer grule.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- New(grule, Config{Addr: addr, ReadHeaderTimeout: 1, ShutdownTimeout: 1}).ListenAndServe(ctx)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		resp, err := http.Get("http://" + addr + "/healthz")
+		if err == nil {
+			_ = resp.Body.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server did not start: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// A client that never finishes its headers is disconnected
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("GET /healthz HTTP/1.1\r\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	if _, err := io.ReadAll(conn); err != nil {
+		t.Fatalf("connection should be closed after the read header timeout: %v", err)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("ListenAndServe returned error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("server did not shut down")
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	grule := engine.NewPartitionEngine(engine.Config{Partition: 1, Coverage: true}, nil)
+	ts := httptest.NewServer(New(grule, Config{}).Handler())
+	defer ts.Close()
+	defer grule.Close()
+	addDiscountRule(t, ts)
+	doJSON(t, http.MethodPost, ts.URL+"/rules/DiscountRule/execute", map[string]any{"Amount": 150}, nil)
+
+	resp := doJSON(t, http.MethodGet, ts.URL+"/coverage", nil, nil)
+	var report engine.CoverageReport
+	decodeBody(t, resp, &report)
+	if len(report.Rules) != 1 || report.Rules[0].Entries[0].Fired != 1 {
+		t.Fatalf("unexpected coverage %+v", report)
+	}
+
+	resp = doJSON(t, http.MethodGet, ts.URL+"/coverage?format=html", nil, nil)
+	if got := resp.Header.Get("Content-Type"); got != "text/html; charset=utf-8" {
+		t.Fatalf("html coverage content type got %q", got)
+	}
+}
+
+func TestLogLevel(t *testing.T) {
+	ts := newTestServer(t)
+	level, _ := engine.LogLevels()
+	defer func() {
+		_ = engine.SetLogLevel(level)
+		_ = engine.SetComponentLogLevel(engine.LogCache, "")
+	}()
+
+	resp := doJSON(t, http.MethodPut, ts.URL+"/log-level", LogLevelRequest{Level: "warn"}, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("set log level status want %d got %d", http.StatusOK, resp.StatusCode)
+	}
+	resp = doJSON(t, http.MethodPut, ts.URL+"/log-level", LogLevelRequest{Level: "debug", Component: "cache"}, nil)
+	var levels LogLevelResponse
+	decodeBody(t, resp, &levels)
+	if levels.Level != "warn" || levels.Components["cache"] != "debug" {
+		t.Fatalf("want warn with cache at debug got %+v", levels)
+	}
+
+	resp = doJSON(t, http.MethodGet, ts.URL+"/log-level", nil, nil)
+	decodeBody(t, resp, &levels)
+	if levels.Level != "warn" || len(levels.Components) != 1 {
+		t.Fatalf("want warn with one component level got %+v", levels)
+	}
+
+	for _, req := range []LogLevelRequest{{Level: "verbose"}, {Level: "debug", Component: "server"}} {
+		resp = doJSON(t, http.MethodPut, ts.URL+"/log-level", req, nil)
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("invalid level %+v status want %d got %d", req, http.StatusBadRequest, resp.StatusCode)
+		}
+	}
+}
+
+func TestRateLimits(t *testing.T) {
+	ts := newTestServer(t)
+	addDiscountRule(t, ts)
+
+	resp := doJSON(t, http.MethodPut, ts.URL+"/rate-limits", RateLimitRequest{Rule: "Discount*", Rate: 0.01}, nil)
+	var limits ListRateLimitsResponse
+	decodeBody(t, resp, &limits)
+	if resp.StatusCode != http.StatusOK || len(limits.RateLimits) != 1 || limits.RateLimits[0].Burst != 1 {
+		t.Fatalf("set rate limit status %d, limits %+v", resp.StatusCode, limits)
+	}
+
+	execute := ts.URL + "/rules/DiscountRule/execute"
+	if resp := doJSON(t, http.MethodPost, execute, map[string]any{"Amount": 150}, nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("execute status want %d got %d", http.StatusOK, resp.StatusCode)
+	}
+	if resp := doJSON(t, http.MethodPost, execute, map[string]any{"Amount": 150}, nil); resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("rate limited execute status want %d got %d", http.StatusTooManyRequests, resp.StatusCode)
+	}
+
+	resp = doJSON(t, http.MethodGet, ts.URL+"/rate-limits", nil, nil)
+	decodeBody(t, resp, &limits)
+	if l := limits.RateLimits[0]; l.Rule != "Discount*" || l.Allowed != 1 || l.Limited != 1 {
+		t.Fatalf("unexpected rate limit %+v", l)
+	}
+
+	if resp := doJSON(t, http.MethodPut, ts.URL+"/rate-limits", RateLimitRequest{Rule: "DiscountRule"}, nil); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("invalid rate limit status want %d got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+	if resp := doJSON(t, http.MethodDelete, ts.URL+"/rate-limits/Discount*", nil, nil); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("remove rate limit status want %d got %d", http.StatusNoContent, resp.StatusCode)
+	}
+	if resp := doJSON(t, http.MethodDelete, ts.URL+"/rate-limits/Discount*", nil, nil); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("remove missing rate limit status want %d got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}